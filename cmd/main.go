@@ -1,21 +1,63 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/cobra"
 	"github.com/ssitko/hex-domain/config"
+	"github.com/ssitko/hex-domain/internal/domain"
 	"github.com/ssitko/hex-domain/internal/handlers"
+	"github.com/ssitko/hex-domain/internal/infrastructure/alerts"
+	"github.com/ssitko/hex-domain/internal/infrastructure/blobs"
+	"github.com/ssitko/hex-domain/internal/infrastructure/images"
+	"github.com/ssitko/hex-domain/internal/infrastructure/payments"
 	"github.com/ssitko/hex-domain/internal/infrastructure/persistence"
+	"github.com/ssitko/hex-domain/internal/infrastructure/rates"
+	"github.com/ssitko/hex-domain/internal/infrastructure/webhooks"
 	"github.com/ssitko/hex-domain/internal/repositories"
 	"github.com/ssitko/hex-domain/internal/routers"
 	"github.com/ssitko/hex-domain/internal/services"
 	"github.com/ssitko/hex-domain/pkg/logger"
 )
 
+const (
+	// How often the scheduler looks for due scheduled changes.
+	schedulerInterval = 15 * time.Second
+	// How often covers without thumbnails are looked for.
+	thumbnailInterval = time.Minute
+	// How often queued jobs are looked for, new jobs of this replica start right away.
+	jobInterval = 5 * time.Second
+	// Jobs run at once per replica unless JOB_WORKERS says otherwise.
+	defaultJobWorkers = 2
+	// Album events kept for clients resuming an event stream, and queued per client before
+	// a client too slow to keep up is disconnected.
+	albumEventReplay = 1000
+	albumEventQueue  = 64
+	// How often idle event streams send a heartbeat.
+	eventHeartbeat = 15 * time.Second
+	// How often WebSocket clients are pinged, and messages queued per client before one too
+	// slow to keep up is disconnected.
+	socketKeepalive = 30 * time.Second
+	socketQueue     = 64
+	// How often due webhook retries are looked for, new deliveries are sent right away.
+	webhookInterval = 15 * time.Second
+	// How long a webhook may take to answer a delivery.
+	webhookTimeout = 10 * time.Second
+	// How long requests in flight may take to finish on shutdown.
+	shutdownTimeout = 30 * time.Second
+)
+
 var (
 	db            persistence.DB
 	envPath       string
@@ -40,23 +82,204 @@ func init() {
 }
 
 func main() {
-	r := gin.Default()
+	// Background workers stop on SIGINT or SIGTERM, running jobs are resumed after a restart
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	r := gin.New()
+	// Client IPs key review flags, forwarded headers only count when sent by a trusted proxy
+	if err := r.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES provided %s", err)
+	}
+	r.Use(gin.Logger(), handlers.Problems(serviceLogger))
+	r.NoRoute(handlers.RouteNotFound)
 
 	// Add logger middleware
 	r.Use(func(c *gin.Context) {
 		serviceLogger.Info(fmt.Sprintf("Request method: %s, time: %s, path: %s", c.Request.Method, time.Now().UTC().Format(time.RFC3339), c.Request.URL))
 		c.Next()
 	})
+	if config.GetConfigValue(config.EDITOR_TOKEN) == "" {
+		serviceLogger.Warn("EDITOR_TOKEN not set, albums can not be published")
+	}
+	r.Use(handlers.IdentifyEditor(config.GetConfigValue(config.EDITOR_TOKEN)))
+
+	// Check requests against the API description, responses too in development
+	spec := handlers.AlbumAPISpec()
+	r.Use(handlers.ValidateRequests(spec, config.GetConfigValue(config.APP_ENV) == "development"))
 
 	// Initialize layers
 	repo := repositories.NewGormAlbumRepository(db)
-	service := services.NewAlbumService(repo)
-	handler := handlers.NewAlbumHandler(service)
+	albumEvents := services.NewAlbumEventBroker(albumEventReplay, albumEventQueue)
+	webhookService := services.NewWebhookService(repositories.NewGormWebhookRepository(db), webhooks.NewHTTPSender(webhookTimeout), serviceLogger)
+	go services.NewWebhookWorker(webhookService, serviceLogger, webhookInterval).Run(ctx)
+	service := services.NewAlbumService(repo, services.WithEvents(albumEvents), services.WithEvents(webhookService))
+	handlerOpts := []handlers.AlbumHandlerOption{handlers.WithEvents(albumEvents, eventHeartbeat)}
+	if provider := exchangeRateProvider(); provider != nil {
+		handlerOpts = append(handlerOpts, handlers.WithExchangeRates(provider))
+	}
+	promotionRepo := repositories.NewGormPromotionRepository(db)
+	pricingService := services.NewPricingService(promotionRepo)
+	handlerOpts = append(handlerOpts, handlers.WithPricing(pricingService))
+	blobs := blobStore()
+	coverService := services.NewCoverService(repo, blobs, images.NewResizer())
+	handlerOpts = append(handlerOpts, handlers.WithCovers(coverService))
+	go services.NewThumbnailWorker(coverService, repo, serviceLogger, thumbnailInterval).Run(ctx)
+	handler := handlers.NewAlbumHandler(service, handlerOpts...)
+	socketHandler := handlers.NewSocketHandler(albumEvents, socketKeepalive, socketQueue)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	promotionHandler := handlers.NewPromotionHandler(services.NewPromotionService(promotionRepo))
+
+	artistRepo := repositories.NewGormArtistRepository(db)
+	artistService := services.NewArtistService(artistRepo, repo)
+	artistHandler := handlers.NewArtistHandler(artistService)
+
+	genreHandler := handlers.NewGenreHandler(services.NewGenreService(repositories.NewGormGenreRepository(db)))
+	tagHandler := handlers.NewTagHandler(services.NewTagService(repositories.NewGormTagRepository(db)))
+
+	inventoryService := services.NewInventoryService(repositories.NewGormInventoryRepository(db), alerts.NewLogStockAlerter(serviceLogger))
+	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+
+	orderRepo := repositories.NewGormOrderRepository(db)
+	orderHandler := handlers.NewOrderHandler(services.NewOrderService(orderRepo, repo, pricingService))
+	paymentService := services.NewPaymentService(repositories.NewGormPaymentRepository(db), orderRepo, paymentGateway())
+	paymentHandler := handlers.NewPaymentHandler(paymentService)
+
+	reviewHandler := handlers.NewReviewHandler(services.NewReviewService(repositories.NewGormReviewRepository(db), repo))
+
+	scheduleService := services.NewScheduleService(repositories.NewGormScheduleRepository(db), service)
+	scheduleHandler := handlers.NewScheduleHandler(scheduleService)
+	scheduler := services.NewScheduler(scheduleService, repositories.NewGormLeaseRepository(db), serviceLogger, schedulerInterval)
+	go scheduler.Run(ctx)
+
+	jobService := services.NewJobService(repositories.NewGormJobRepository(db), blobs, map[domain.JobKind]domain.JobExecutor{
+		domain.JobImport: services.NewImportJob(service, blobs, handlers.AlbumFiles{}),
+		domain.JobExport: services.NewExportJob(service, blobs, handlers.AlbumFiles{}),
+	})
+	jobHandler := handlers.NewJobHandler(jobService)
+	jobRunner := services.NewJobRunner(jobService, serviceLogger, jobWorkers(), jobInterval)
+	jobsStopped := make(chan struct{})
+	go func() {
+		jobRunner.Run(ctx)
+		close(jobsStopped)
+	}()
 
 	// Router
 	routers.RegisterAlbumHandlers(r, handler)
+	routers.RegisterArtistHandlers(r, artistHandler)
+	routers.RegisterGenreHandlers(r, genreHandler)
+	routers.RegisterTagHandlers(r, tagHandler)
+	routers.RegisterInventoryHandlers(r, inventoryHandler)
+	routers.RegisterOrderHandlers(r, orderHandler)
+	routers.RegisterPaymentHandlers(r, paymentHandler)
+	routers.RegisterPromotionHandlers(r, promotionHandler)
+	routers.RegisterScheduleHandlers(r, scheduleHandler)
+	routers.RegisterReviewHandlers(r, reviewHandler)
+	routers.RegisterJobHandlers(r, jobHandler)
+	routers.RegisterSocketHandlers(r, socketHandler)
+	routers.RegisterWebhookHandlers(r, webhookHandler)
+
+	docsHandler, err := handlers.NewDocsHandler(spec)
+	if err != nil {
+		log.Fatalf("failed to build OpenAPI document %s", err)
+	}
+	routers.RegisterDocsHandlers(r, docsHandler)
+
+	server := &http.Server{Addr: fmt.Sprintf(":%s", config.GetConfigValue(config.PORT)), Handler: r}
+	// Event streams and sockets never finish on their own, clients reconnect to another replica
+	server.RegisterOnShutdown(albumEvents.Close)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("failed to serve %s", err)
+		}
+	}()
 
-	r.Run(fmt.Sprintf(":%s", config.GetConfigValue(config.PORT)))
+	<-ctx.Done()
+	serviceLogger.Info("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		serviceLogger.Warn(fmt.Sprintf("shutdown: %s", err))
+	}
+	<-jobsStopped
+}
+
+// Number of job workers, JOB_WORKERS when set to a positive number.
+func jobWorkers() int {
+	value := config.GetConfigValue(config.JOB_WORKERS)
+	if value == "" {
+		return defaultJobWorkers
+	}
+	workers, err := strconv.Atoi(value)
+	if err != nil || workers < 1 {
+		log.Fatalf("invalid JOB_WORKERS provided %q", value)
+	}
+	return workers
+}
+
+// Proxies whose X-Forwarded-For header is trusted, the comma separated TRUSTED_PROXIES. None by default.
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(config.GetConfigValue(config.TRUSTED_PROXIES), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// Pick exchange rate adapter based on config, remote API takes precedence over a local file.
+func exchangeRateProvider() domain.ExchangeRateProvider {
+	if url := config.GetConfigValue(config.RATES_URL); url != "" {
+		return rates.NewHTTPProvider(url, time.Hour)
+	}
+	if path := config.GetConfigValue(config.RATES_FILE); path != "" {
+		provider, err := rates.NewFileProvider(path)
+		if err != nil {
+			log.Fatalf("invalid rates file provided %s", err)
+		}
+		return provider
+	}
+	return nil
+}
+
+// Store blobs in S3 compatible storage when configured, below a local directory otherwise.
+func blobStore() domain.BlobStore {
+	if endpoint := config.GetConfigValue(config.S3_ENDPOINT); endpoint != "" {
+		region := config.GetConfigValue(config.S3_REGION)
+		if region == "" {
+			region = "us-east-1"
+		}
+		store, err := blobs.NewS3Store(endpoint, config.GetConfigValue(config.S3_BUCKET), region,
+			config.GetConfigValue(config.S3_ACCESS_KEY), config.GetConfigValue(config.S3_SECRET_KEY))
+		if err != nil {
+			log.Fatalf("invalid blob storage config provided %s", err)
+		}
+		return store
+	}
+	dir := config.GetConfigValue(config.BLOB_DIR)
+	if dir == "" {
+		dir = "data/blobs"
+	}
+	store, err := blobs.NewLocalStore(dir)
+	if err != nil {
+		log.Fatalf("invalid blob directory provided %s", err)
+	}
+	return store
+}
+
+// Use the Stripe-like HTTP gateway when configured, the deterministic fake gateway otherwise.
+// Either way payment webhooks mark orders paid, so they must be signed with a secret.
+func paymentGateway() domain.PaymentGateway {
+	secret := config.GetConfigValue(config.PAYMENT_WEBHOOK_SECRET)
+	if secret == "" {
+		log.Fatal("PAYMENT_WEBHOOK_SECRET is required to verify payment webhooks")
+	}
+	if url := config.GetConfigValue(config.PAYMENT_API_URL); url != "" {
+		return payments.NewHTTPGateway(url, config.GetConfigValue(config.PAYMENT_API_KEY), secret)
+	}
+	serviceLogger.Warn("PAYMENT_API_URL not set, using the fake payment gateway")
+	return payments.NewFakeGateway(secret)
 }
 
 func cmd() {
@@ -13,6 +13,29 @@ const (
 	DB_PORT     = "DB_PORT"
 	DB_NAME     = "DB_NAME"
 	PORT        = "PORT"
+
+	// Optional keys
+	APP_ENV = "APP_ENV"
+
+	RATES_FILE = "RATES_FILE"
+	RATES_URL  = "RATES_URL"
+
+	PAYMENT_API_URL        = "PAYMENT_API_URL"
+	PAYMENT_API_KEY        = "PAYMENT_API_KEY"
+	PAYMENT_WEBHOOK_SECRET = "PAYMENT_WEBHOOK_SECRET"
+
+	EDITOR_TOKEN = "EDITOR_TOKEN"
+
+	TRUSTED_PROXIES = "TRUSTED_PROXIES"
+
+	JOB_WORKERS = "JOB_WORKERS"
+
+	BLOB_DIR      = "BLOB_DIR"
+	S3_ENDPOINT   = "S3_ENDPOINT"
+	S3_BUCKET     = "S3_BUCKET"
+	S3_REGION     = "S3_REGION"
+	S3_ACCESS_KEY = "S3_ACCESS_KEY"
+	S3_SECRET_KEY = "S3_SECRET_KEY"
 )
 
 var REQUIRED_KEYS = []string{
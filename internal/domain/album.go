@@ -1,19 +1,141 @@
 package domain
 
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrInvalidFilter = errors.New("invalid filter")
+
 // Domain Layer
 // Represents the core business logic and entities.
 type Album struct {
-	ID     uint    `json:"id" gorm:"primaryKey"`
-	Title  string  `json:"title"`
-	Artist string  `json:"artist"`
-	Price  float64 `json:"price"`
+	ID       uint    `json:"id" xml:"id" gorm:"primaryKey"`
+	Title    string  `json:"title" xml:"title"`
+	ArtistID uint    `json:"artist_id" xml:"artist_id" gorm:"index"`
+	Artist   *Artist `json:"artist,omitempty" xml:"artist,omitempty" gorm:"foreignKey:ArtistID"`
+	Price    float64 `json:"price" xml:"price"`
+	Tracks   []Track `json:"tracks,omitempty" xml:"tracks>track,omitempty" gorm:"foreignKey:AlbumID;constraint:OnDelete:CASCADE"`
+	Genres   []Genre `json:"genres,omitempty" xml:"genres>genre,omitempty" gorm:"many2many:album_genres;constraint:OnDelete:CASCADE"`
+	Tags     []Tag   `json:"tags,omitempty" xml:"tags>tag,omitempty" gorm:"many2many:album_tags;constraint:OnDelete:CASCADE"`
+
+	// Release metadata, see Validate
+	ReleaseDate   Date        `json:"release_date" xml:"release_date" gorm:"type:date;index"`
+	Label         string      `json:"label,omitempty" xml:"label,omitempty" gorm:"size:255;uniqueIndex:idx_albums_label_barcode"`
+	CatalogNumber string      `json:"catalog_number,omitempty" xml:"catalog_number,omitempty" gorm:"size:64"`
+	Format        AlbumFormat `json:"format,omitempty" xml:"format,omitempty" gorm:"size:16"`
+	Barcode       string      `json:"barcode,omitempty" xml:"barcode,omitempty" gorm:"size:13;serializer:nullempty;uniqueIndex:idx_albums_label_barcode"`
+
+	// Editorial workflow, changed only through transitions
+	Status      AlbumStatus `json:"status" xml:"status" gorm:"size:16;index"`
+	PublishedAt *time.Time  `json:"published_at,omitempty" xml:"published_at,omitempty"`
+
+	// Uploaded artwork, see CoverService
+	Cover CoverImage `json:"-" xml:"-" gorm:"embedded;embeddedPrefix:cover_"`
+
+	// Review aggregates maintained with every review change, see UpdateRating
+	AverageRating float64 `json:"average_rating" xml:"average_rating"`
+	ReviewCount   int     `json:"review_count" xml:"review_count"`
+	RatingSum     int     `json:"-" xml:"-"`
+
+	// Computed from Tracks, see RefreshTracklist
+	TotalDuration int `json:"total_duration" xml:"total_duration" gorm:"-"`
+	TrackCount    int `json:"track_count" xml:"track_count" gorm:"-"`
+
+	// Recorded in the price history when an update changes Price, not stored on the album
+	PriceChangeReason string `json:"price_change_reason,omitempty" xml:"price_change_reason,omitempty" gorm:"-"`
+}
+
+// KeepManagedFields copies the fields a plain update must not change from the stored album:
+// the workflow status, the review aggregates and the cover.
+func (a *Album) KeepManagedFields(stored Album) {
+	a.Status, a.PublishedAt = stored.Status, stored.PublishedAt
+	a.AverageRating, a.ReviewCount, a.RatingSum = stored.AverageRating, stored.ReviewCount, stored.RatingSum
+	a.Cover = stored.Cover
+}
+
+// How a filter with several values matches an album.
+type MatchMode string
+
+const (
+	MatchAny MatchMode = "any"
+	MatchAll MatchMode = "all"
+)
+
+// Criteria for listing albums, zero value matches every album.
+type AlbumFilter struct {
+	Genres     []string
+	GenreMatch MatchMode
+	Tags       []string
+	TagMatch   MatchMode
+
+	ReleasedAfter Date
+
+	// Nil matches regardless of stock
+	InStock *bool
+
+	// Empty matches every status
+	Status AlbumStatus
+}
+
+// Matches tells whether the album meets the filter the way the album repository selects
+// albums, for albums at hand. InStock is not checked, stock levels are not part of the album.
+func (f AlbumFilter) Matches(album Album) bool {
+	genres := make([]string, len(album.Genres))
+	for i, genre := range album.Genres {
+		genres[i] = genre.Name
+	}
+	tags := make([]string, len(album.Tags))
+	for i, tag := range album.Tags {
+		tags[i] = tag.Name
+	}
+	switch {
+	case !matchNames(f.Genres, genres, f.GenreMatch), !matchNames(f.Tags, tags, f.TagMatch):
+		return false
+	case !f.ReleasedAfter.IsZero() && !album.ReleaseDate.After(f.ReleasedAfter.Time):
+		return false
+	}
+	return f.Status == "" || f.Status == album.Status
+}
+
+// matchNames tells whether names contains any or all of wanted, ignoring case.
+func matchNames(wanted, names []string, mode MatchMode) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	for _, name := range wanted {
+		found := slices.ContainsFunc(names, func(other string) bool { return strings.EqualFold(name, other) })
+		if found && mode != MatchAll {
+			return true
+		}
+		if !found && mode == MatchAll {
+			return false
+		}
+	}
+	return mode == MatchAll
 }
 
 // Album service interface definition.
 type AlbumService interface {
+	AddAlbumGenre(id int, genre string) (Album, error)
+	AddAlbumTag(id int, tag string) (Album, error)
+	ApproveAlbum(id int) (Album, error)
+	ArchiveAlbum(id int) (Album, error)
 	CreateAlbum(album Album) (Album, error)
 	DeleteAlbum(id int) error
+	ExportAlbums(filter AlbumFilter, afterID uint, batch func(albums []Album) error) error
 	GetAlbumByID(id int) (Album, error)
-	GetAllAlbums() ([]Album, error)
+	GetAlbumPriceHistory(id int, query PriceHistoryQuery) (PriceHistory, error)
+	GetAlbumsByBarcode(code string) ([]Album, error)
+	GetAllAlbums(filter AlbumFilter) ([]Album, error)
+	GetAlbumTracks(id int) ([]Track, error)
+	ImportAlbums(rows []ImportRow, dryRun bool) (ImportReport, error)
+	PublishAlbum(id int) (Album, error)
+	RemoveAlbumGenre(id int, genre string) (Album, error)
+	RemoveAlbumTag(id int, tag string) (Album, error)
+	ReplaceAlbumTracks(id int, tracks []Track) ([]Track, error)
+	SubmitAlbum(id int) (Album, error)
 	UpdateAlbum(album Album) (Album, error)
 }
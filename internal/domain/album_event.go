@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// What happened to the album of an event.
+type AlbumEventType string
+
+const (
+	AlbumCreated AlbumEventType = "created"
+	AlbumUpdated AlbumEventType = "updated"
+	AlbumDeleted AlbumEventType = "deleted"
+)
+
+// Change made to an album by the album service. Album is the album after the change, or as it
+// was before it was deleted. IDs are assigned by the publisher and increase with every event.
+type AlbumEvent struct {
+	ID    string
+	Type  AlbumEventType
+	Album Album
+	At    time.Time
+}
+
+// Public tells whether everyone may learn about the event, the album is or was published.
+// Drafts stay between editors, see also Withdrawn.
+func (e AlbumEvent) Public() bool {
+	return e.Album.IsPublic() || e.Album.PublishedAt != nil
+}
+
+// Withdrawn tells whether the album was published but is no longer public, for example
+// archived. Anyone but editors only learns that such an album changed, not how.
+func (e AlbumEvent) Withdrawn() bool {
+	return e.Public() && !e.Album.IsPublic()
+}
+
+// Albums a subscriber follows, events match any listed album or artist. Empty lists match
+// every album.
+type AlbumEventFilter struct {
+	AlbumIDs  []uint
+	ArtistIDs []uint
+}
+
+func (f AlbumEventFilter) Matches(event AlbumEvent) bool {
+	if len(f.AlbumIDs) == 0 && len(f.ArtistIDs) == 0 {
+		return true
+	}
+	return slices.Contains(f.AlbumIDs, event.Album.ID) || slices.Contains(f.ArtistIDs, event.Album.ArtistID)
+}
+
+// Events of a subscriber, see AlbumEventSource.Subscribe.
+type AlbumSubscription struct {
+	// Buffered events published after the last event the subscriber saw, oldest first
+	Replay []AlbumEvent
+	// Events published after the last event the subscriber saw are no longer buffered, it
+	// should reload the albums it shows
+	Gap bool
+	// Events published since subscribing, closed when the subscriber fell too far behind or
+	// Close was called
+	Events <-chan AlbumEvent
+	Close  func()
+}
+
+// AlbumEventPublisher is told about every album change.
+type AlbumEventPublisher interface {
+	Publish(eventType AlbumEventType, album Album)
+}
+
+// AlbumEventSource lets clients follow album changes as they happen.
+type AlbumEventSource interface {
+	// Subscribe delivers the events matching filter. Given the ID of the last event seen it
+	// first replays the events published after it.
+	Subscribe(filter AlbumEventFilter, lastEventID string) AlbumSubscription
+}
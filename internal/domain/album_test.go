@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlbumFilter(t *testing.T) {
+	album := Album{
+		Genres:      []Genre{{Name: "Rock"}, {Name: "Jazz"}},
+		Tags:        []Tag{{Name: "live"}},
+		ReleaseDate: NewDate(1969, time.September, 26),
+		Status:      AlbumPublished,
+	}
+
+	t.Run("Matches :: the zero filter matches every album", func(t *testing.T) {
+		assert.True(t, AlbumFilter{}.Matches(album))
+		assert.True(t, AlbumFilter{}.Matches(Album{}))
+	})
+
+	t.Run("Matches :: genres and tags match any or all names, ignoring case", func(t *testing.T) {
+		assert.True(t, AlbumFilter{Genres: []string{"rock", "Blues"}}.Matches(album))
+		assert.False(t, AlbumFilter{Genres: []string{"rock", "Blues"}, GenreMatch: MatchAll}.Matches(album))
+		assert.True(t, AlbumFilter{Genres: []string{"rock", "jazz"}, GenreMatch: MatchAll}.Matches(album))
+		assert.False(t, AlbumFilter{Tags: []string{"studio"}}.Matches(album))
+	})
+
+	t.Run("Matches :: release date and status", func(t *testing.T) {
+		assert.True(t, AlbumFilter{ReleasedAfter: NewDate(1969, time.September, 25)}.Matches(album))
+		assert.False(t, AlbumFilter{ReleasedAfter: NewDate(1969, time.September, 26)}.Matches(album))
+		assert.False(t, AlbumFilter{ReleasedAfter: NewDate(1960, time.January, 1)}.Matches(Album{}))
+		assert.False(t, AlbumFilter{Status: AlbumDraft}.Matches(album))
+	})
+}
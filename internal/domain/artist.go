@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrArtistExists    = errors.New("artist already exists")
+	ErrArtistHasAlbums = errors.New("artist still has albums")
+)
+
+// Artist entity, albums reference it by ID.
+type Artist struct {
+	ID   uint   `json:"id" xml:"id" gorm:"primaryKey"`
+	Name string `json:"name" xml:"name" binding:"required" gorm:"size:255"`
+	// Canonical form of Name, used to detect duplicates such as "The Beatles" and "beatles".
+	NormalizedName string `json:"-" xml:"-" gorm:"size:255;uniqueIndex"`
+}
+
+// NormalizeArtistName folds case, whitespace and a leading article, so spelling
+// variants of the same artist map to one key.
+func NormalizeArtistName(name string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(name), " "))
+	return strings.TrimPrefix(normalized, "the ")
+}
+
+// Artist service interface definition.
+type ArtistService interface {
+	CreateArtist(artist Artist) (Artist, error)
+	DeleteArtist(id int) error
+	GetAlbumsByArtist(id int) ([]Album, error)
+	GetAllArtists() ([]Artist, error)
+	GetArtistByID(id int) (Artist, error)
+	UpdateArtist(artist Artist) (Artist, error)
+}
@@ -0,0 +1,212 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	// Decoders of the accepted cover types, for reading their dimensions
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrInvalidCover  = errors.New("invalid cover image")
+	ErrCoverTooLarge = errors.New("cover image too large")
+	ErrNoCover       = errors.New("album has no cover")
+	ErrBlobNotFound  = fmt.Errorf("blob %w", ErrNotFound)
+)
+
+// Largest accepted cover upload.
+const MaxCoverSize = 10 << 20
+
+const (
+	// Failed thumbnail generations of an image before it is given up.
+	MaxThumbnailAttempts = 5
+	// Wait before generating thumbnails again after a failure, doubled with every further failure.
+	ThumbnailBackoff = time.Minute
+)
+
+// Largest accepted cover dimensions. A small file can declare a huge image, decoding it takes
+// four bytes of memory per pixel.
+const (
+	MaxCoverWidth  = 8000
+	MaxCoverHeight = 8000
+	MaxCoverPixels = 24_000_000
+)
+
+// Accepted cover content types, detected from the file content, and their file extensions.
+var coverTypes = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+// Album cover stored in the blob store under Key.
+type CoverImage struct {
+	Key         string `gorm:"size:255"`
+	ContentType string `gorm:"size:32"`
+	Size        int64
+	// SHA-256 of the content, doubles as the HTTP entity tag
+	Checksum  string `gorm:"size:64"`
+	UpdatedAt *time.Time
+	// Checksum of the image the thumbnails were generated from
+	ThumbnailChecksum string `gorm:"size:64;not null;default:''"`
+	// Failed thumbnail generations of the image, and when to try again
+	ThumbnailAttempts int `gorm:"not null;default:0"`
+	ThumbnailRetryAt  *time.Time
+}
+
+// NewCoverImage checks the uploaded content and names its blob after the content checksum,
+// so uploading the same image again maps to the same key.
+func NewCoverImage(albumID uint, content []byte, now time.Time) (CoverImage, error) {
+	if len(content) == 0 {
+		return CoverImage{}, fmt.Errorf("%w: empty file", ErrInvalidCover)
+	}
+	if len(content) > MaxCoverSize {
+		return CoverImage{}, fmt.Errorf("%w: larger than %d bytes", ErrCoverTooLarge, MaxCoverSize)
+	}
+	contentType := http.DetectContentType(content)
+	extension, ok := coverTypes[contentType]
+	if !ok {
+		return CoverImage{}, fmt.Errorf("%w: %s is not a JPEG, PNG or WebP image", ErrInvalidCover, contentType)
+	}
+	if err := CheckCoverDimensions(content); err != nil {
+		return CoverImage{}, err
+	}
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+	return CoverImage{
+		Key:         fmt.Sprintf("covers/%d/%s.%s", albumID, checksum, extension),
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		Checksum:    checksum,
+		UpdatedAt:   &now,
+	}, nil
+}
+
+// CheckCoverDimensions reads the dimensions from the image header, without decoding the image,
+// and rejects images beyond MaxCoverWidth, MaxCoverHeight or MaxCoverPixels.
+func CheckCoverDimensions(content []byte) error {
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidCover, err)
+	}
+	if config.Width > MaxCoverWidth || config.Height > MaxCoverHeight || config.Width*config.Height > MaxCoverPixels {
+		return fmt.Errorf("%w: %dx%d pixels exceed %dx%d or %d pixels",
+			ErrCoverTooLarge, config.Width, config.Height, MaxCoverWidth, MaxCoverHeight, MaxCoverPixels)
+	}
+	return nil
+}
+
+func (c CoverImage) Exists() bool {
+	return c.Key != ""
+}
+
+// Thumbnail widths and formats generated for every cover, each fits a square of that size.
+var (
+	CoverSizes     = []int{64, 256, 1024}
+	ThumbnailTypes = []string{"image/jpeg", "image/png"}
+)
+
+var thumbnailExtensions = map[string]string{"image/jpeg": "jpg", "image/png": "png"}
+
+// Resized cover image.
+type Thumbnail struct {
+	Size        int
+	ContentType string
+	Content     []byte
+}
+
+// ThumbnailsReady reports whether thumbnails of the current image exist.
+func (c CoverImage) ThumbnailsReady() bool {
+	return c.Exists() && c.ThumbnailChecksum == c.Checksum
+}
+
+// ThumbnailsDue reports whether thumbnails of the current image are missing and may be generated
+// now, images that failed MaxThumbnailAttempts times are given up.
+func (c CoverImage) ThumbnailsDue(now time.Time) bool {
+	if !c.Exists() || c.ThumbnailsReady() || c.ThumbnailAttempts >= MaxThumbnailAttempts {
+		return false
+	}
+	return c.ThumbnailRetryAt == nil || !c.ThumbnailRetryAt.After(now)
+}
+
+// RecordThumbnailFailure counts a failed generation, the next one waits for a backoff.
+func (c *CoverImage) RecordThumbnailFailure(now time.Time) {
+	c.ThumbnailAttempts++
+	if c.ThumbnailAttempts >= MaxThumbnailAttempts {
+		c.ThumbnailRetryAt = nil
+		return
+	}
+	next := now.Add(ThumbnailBackoff << (c.ThumbnailAttempts - 1))
+	c.ThumbnailRetryAt = &next
+}
+
+// DefaultThumbnailType keeps PNG covers lossless, other covers get JPEG thumbnails.
+func (c CoverImage) DefaultThumbnailType() string {
+	if c.ContentType == "image/png" {
+		return "image/png"
+	}
+	return "image/jpeg"
+}
+
+// ThumbnailKey names a thumbnail after the image it was generated from, so regenerating
+// thumbnails of the same image overwrites them with identical content.
+func (c CoverImage) ThumbnailKey(size int, contentType string) string {
+	return fmt.Sprintf("%s/%d.%s", strings.TrimSuffix(c.Key, path.Ext(c.Key)), size, thumbnailExtensions[contentType])
+}
+
+// ThumbnailKeys lists every thumbnail key of the image.
+func (c CoverImage) ThumbnailKeys() []string {
+	var keys []string
+	for _, size := range CoverSizes {
+		for _, contentType := range ThumbnailTypes {
+			keys = append(keys, c.ThumbnailKey(size, contentType))
+		}
+	}
+	return keys
+}
+
+// ValidateThumbnail checks that size and contentType name a generated thumbnail.
+func ValidateThumbnail(size int, contentType string) error {
+	if !slices.Contains(CoverSizes, size) {
+		return fmt.Errorf("%w: size must be one of %v", ErrInvalidCover, CoverSizes)
+	}
+	if !slices.Contains(ThumbnailTypes, contentType) {
+		return fmt.Errorf("%w: format must be jpeg or png", ErrInvalidCover)
+	}
+	return nil
+}
+
+// Image processing interface definition (port).
+type ImageResizer interface {
+	// Thumbnails scales source down to fit each size, in each of the content types
+	Thumbnails(source []byte, sizes []int, contentTypes []string) ([]Thumbnail, error)
+}
+
+// Blob storage interface definition (port). Missing keys are reported through ErrBlobNotFound.
+type BlobStore interface {
+	Put(key string, content []byte, contentType string) error
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// Cover service interface definition.
+type CoverService interface {
+	UploadCover(albumID int, content []byte) (Album, error)
+	// OpenCover reads the stored cover of album, or one of its thumbnails when size is not
+	// zero. The caller closes the reader.
+	OpenCover(album Album, size int, contentType string) (io.ReadCloser, error)
+}
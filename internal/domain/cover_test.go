@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pngHeader is the start of a PNG declaring the dimensions, without any image data.
+func pngHeader(width, height uint32) []byte {
+	chunk := binary.BigEndian.AppendUint32([]byte("IHDR"), width)
+	chunk = binary.BigEndian.AppendUint32(chunk, height)
+	chunk = append(chunk, 8, 6, 0, 0, 0)
+	header := binary.BigEndian.AppendUint32([]byte("\x89PNG\r\n\x1a\n"), uint32(len(chunk)-4))
+	header = append(header, chunk...)
+	return binary.BigEndian.AppendUint32(header, crc32.ChecksumIEEE(chunk))
+}
+
+func TestCoverImage(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	var encoded bytes.Buffer
+	png.Encode(&encoded, image.NewRGBA(image.Rect(0, 0, 2, 2)))
+
+	t.Run("NewCoverImage :: detects the type from content", func(t *testing.T) {
+		cover, err := NewCoverImage(7, encoded.Bytes(), now)
+		assert.Nil(t, err)
+		assert.Equal(t, "image/png", cover.ContentType)
+		assert.Equal(t, int64(encoded.Len()), cover.Size)
+		assert.Equal(t, "covers/7/"+cover.Checksum+".png", cover.Key)
+		assert.True(t, cover.Exists())
+
+		again, _ := NewCoverImage(7, encoded.Bytes(), now.Add(time.Hour))
+		assert.Equal(t, cover.Key, again.Key)
+	})
+
+	t.Run("NewCoverImage :: rejects other content and oversized files", func(t *testing.T) {
+		_, err := NewCoverImage(7, []byte("<svg xmlns='http://www.w3.org/2000/svg'/>"), now)
+		assert.ErrorIs(t, err, ErrInvalidCover)
+		_, err = NewCoverImage(7, nil, now)
+		assert.ErrorIs(t, err, ErrInvalidCover)
+		_, err = NewCoverImage(7, append(encoded.Bytes(), make([]byte, MaxCoverSize)...), now)
+		assert.ErrorIs(t, err, ErrCoverTooLarge)
+	})
+
+	t.Run("NewCoverImage :: rejects images beyond the dimension limits", func(t *testing.T) {
+		_, err := NewCoverImage(7, pngHeader(30000, 30000), now)
+		assert.ErrorIs(t, err, ErrCoverTooLarge)
+		_, err = NewCoverImage(7, pngHeader(MaxCoverWidth+1, 10), now)
+		assert.ErrorIs(t, err, ErrCoverTooLarge)
+		_, err = NewCoverImage(7, pngHeader(10, MaxCoverHeight+1), now)
+		assert.ErrorIs(t, err, ErrCoverTooLarge)
+		_, err = NewCoverImage(7, pngHeader(MaxCoverWidth, MaxCoverHeight), now)
+		assert.ErrorIs(t, err, ErrCoverTooLarge)
+
+		assert.Nil(t, CheckCoverDimensions(pngHeader(3000, 3000)))
+		assert.ErrorIs(t, CheckCoverDimensions([]byte("\x89PNG\r\n\x1a\n")), ErrInvalidCover)
+	})
+
+	t.Run("ThumbnailKey :: derives from the image checksum", func(t *testing.T) {
+		cover := CoverImage{Key: "covers/7/abc.webp", ContentType: "image/webp", Checksum: "abc"}
+		assert.Equal(t, "covers/7/abc/256.jpg", cover.ThumbnailKey(256, "image/jpeg"))
+		assert.Equal(t, "covers/7/abc/64.png", cover.ThumbnailKey(64, "image/png"))
+		assert.Len(t, cover.ThumbnailKeys(), 6)
+		assert.Equal(t, "image/jpeg", cover.DefaultThumbnailType())
+		assert.False(t, cover.ThumbnailsReady())
+
+		cover.ThumbnailChecksum = "abc"
+		assert.True(t, cover.ThumbnailsReady())
+		cover.Checksum = "def"
+		assert.False(t, cover.ThumbnailsReady())
+	})
+
+	t.Run("RecordThumbnailFailure :: backs off and gives up", func(t *testing.T) {
+		cover := CoverImage{Key: "covers/7/abc.png", Checksum: "abc"}
+		assert.True(t, cover.ThumbnailsDue(now))
+
+		cover.RecordThumbnailFailure(now)
+		assert.Equal(t, now.Add(ThumbnailBackoff), *cover.ThumbnailRetryAt)
+		assert.False(t, cover.ThumbnailsDue(now))
+		assert.True(t, cover.ThumbnailsDue(now.Add(ThumbnailBackoff)))
+
+		cover.RecordThumbnailFailure(now)
+		assert.Equal(t, now.Add(2*ThumbnailBackoff), *cover.ThumbnailRetryAt)
+
+		for cover.ThumbnailAttempts < MaxThumbnailAttempts {
+			cover.RecordThumbnailFailure(now)
+		}
+		assert.Nil(t, cover.ThumbnailRetryAt)
+		assert.False(t, cover.ThumbnailsDue(now.Add(24*time.Hour)))
+
+		cover.ThumbnailAttempts, cover.ThumbnailChecksum = 0, "abc"
+		assert.False(t, cover.ThumbnailsDue(now))
+	})
+
+	t.Run("ValidateThumbnail :: generated sizes and formats only", func(t *testing.T) {
+		assert.Nil(t, ValidateThumbnail(1024, "image/png"))
+		assert.ErrorIs(t, ValidateThumbnail(512, "image/png"), ErrInvalidCover)
+		assert.ErrorIs(t, ValidateThumbnail(64, "image/webp"), ErrInvalidCover)
+	})
+}
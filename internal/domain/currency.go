@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+// Currency in which album prices are stored.
+const BaseCurrency = "USD"
+
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// Exchange rate between two currencies, valid as of Date.
+type ExchangeRate struct {
+	From string
+	To   string
+	Rate float64
+	Date time.Time
+}
+
+// Convert returns amount expressed in the target currency, rounded to cents.
+func (r ExchangeRate) Convert(amount float64) float64 {
+	return math.Round(amount*r.Rate*100) / 100
+}
+
+// Exchange rate provider interface definition (port).
+type ExchangeRateProvider interface {
+	Rate(from, to string) (ExchangeRate, error)
+}
+
+// Rates table quoted against a single base currency.
+type RateTable struct {
+	Base  string
+	Date  time.Time
+	Rates map[string]float64
+}
+
+// Rate derives the cross rate between two currencies of the table.
+func (t RateTable) Rate(from, to string) (ExchangeRate, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	fromRate, err := t.lookup(from)
+	if err != nil {
+		return ExchangeRate{}, err
+	}
+	toRate, err := t.lookup(to)
+	if err != nil {
+		return ExchangeRate{}, err
+	}
+	return ExchangeRate{From: from, To: to, Rate: toRate / fromRate, Date: t.Date}, nil
+}
+
+func (t RateTable) lookup(currency string) (float64, error) {
+	if currency == strings.ToUpper(t.Base) {
+		return 1, nil
+	}
+	rate, ok := t.Rates[currency]
+	if !ok || rate <= 0 {
+		return 0, ErrUnsupportedCurrency
+	}
+	return rate, nil
+}
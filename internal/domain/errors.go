@@ -0,0 +1,10 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrNotFound is returned by repositories when the requested record does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrDuplicate is returned by repositories when a write violates a unique index.
+	ErrDuplicate = errors.New("already exists")
+)
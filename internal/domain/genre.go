@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"strings"
+)
+
+// Genre entity, albums belong to any number of genres.
+type Genre struct {
+	ID   uint   `json:"id" xml:"id" gorm:"primaryKey"`
+	Name string `json:"name" xml:"name" binding:"required" gorm:"size:100;uniqueIndex"`
+}
+
+// NormalizeGenreName trims and collapses whitespace, keeping the original casing for display.
+func NormalizeGenreName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// Genre service interface definition.
+type GenreService interface {
+	CreateGenre(genre Genre) (Genre, error)
+	DeleteGenre(id int) error
+	GetAllGenres() ([]Genre, error)
+	GetGenreByID(id int) (Genre, error)
+	UpdateGenre(genre Genre) (Genre, error)
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Returned with the report when an import is not applied because rows were rejected.
+var ErrImportRejected = errors.New("import rejected, no album was saved")
+
+// What an import does with a row.
+type ImportAction string
+
+const (
+	ImportCreated  ImportAction = "created"
+	ImportUpdated  ImportAction = "updated"
+	ImportRejected ImportAction = "rejected"
+)
+
+// Album read from a row of a bulk import. Fields lists the JSON names of the album fields the
+// row sets, an updated album keeps the stored value of any other field. Err tells why the
+// row could not be read.
+type ImportRow struct {
+	Row    int
+	Album  Album
+	Fields []string
+	Err    error
+}
+
+// Outcome of an import row, AlbumID is that of the matched album or, once saved, the created one.
+type ImportRowResult struct {
+	Row     int          `json:"row"`
+	Action  ImportAction `json:"action"`
+	AlbumID uint         `json:"album_id,omitempty"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// Per row report of an import, of what would be done on a dry run.
+type ImportReport struct {
+	DryRun   bool              `json:"dry_run"`
+	Created  int               `json:"created"`
+	Updated  int               `json:"updated"`
+	Rejected int               `json:"rejected"`
+	Rows     []ImportRowResult `json:"rows"`
+}
+
+// Add records the outcome of a row.
+func (r *ImportReport) Add(result ImportRowResult) {
+	switch result.Action {
+	case ImportCreated:
+		r.Created++
+	case ImportUpdated:
+		r.Updated++
+	case ImportRejected:
+		r.Rejected++
+	}
+	r.Rows = append(r.Rows, result)
+}
+
+// ImportKey is the natural key imported albums are matched by, the label and barcode. Labels
+// match regardless of case, like barcode uniqueness is checked.
+func (a Album) ImportKey() string {
+	return strings.ToLower(strings.TrimSpace(a.Label)) + "\x00" + NormalizeBarcode(a.Barcode)
+}
+
+// MergeImported copies the fields set by an import row onto a stored album.
+func (a *Album) MergeImported(row ImportRow) {
+	imported := row.Album
+	for _, field := range row.Fields {
+		switch field {
+		case "title":
+			a.Title = imported.Title
+		case "artist":
+			a.Artist, a.ArtistID = imported.Artist, 0
+		case "price":
+			a.Price = imported.Price
+		case "release_date":
+			a.ReleaseDate = imported.ReleaseDate
+		case "label":
+			a.Label = imported.Label
+		case "catalog_number":
+			a.CatalogNumber = imported.CatalogNumber
+		case "format":
+			a.Format = imported.Format
+		case "barcode":
+			a.Barcode = imported.Barcode
+		}
+	}
+}
+
+// ValidateImport applies Validate and the rules of albums saved by an import: albums are
+// matched by barcode and need a title, an artist and a price that is not negative.
+func (a *Album) ValidateImport() error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	switch {
+	case a.Barcode == "":
+		return fmt.Errorf("%w: barcode is required, imported albums are matched by label and barcode", ErrInvalidAlbum)
+	case strings.TrimSpace(a.Title) == "":
+		return fmt.Errorf("%w: title is required", ErrInvalidAlbum)
+	case a.ArtistID == 0 && (a.Artist == nil || strings.TrimSpace(a.Artist.Name) == ""):
+		return fmt.Errorf("%w: artist is required", ErrInvalidAlbum)
+	case a.Price < 0:
+		return fmt.Errorf("%w: price must not be negative", ErrInvalidAlbum)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidQuantity       = errors.New("quantity must be positive")
+	ErrInsufficientStock     = errors.New("insufficient stock")
+	ErrReservationNotActive  = errors.New("reservation is no longer active")
+	ErrStockBelowReservation = errors.New("stock on hand cannot be lower than the reserved quantity")
+)
+
+// Stock of one album in one warehouse.
+type StockLevel struct {
+	ID                uint      `json:"-" gorm:"primaryKey"`
+	AlbumID           uint      `json:"album_id" gorm:"uniqueIndex:idx_stock_album_warehouse"`
+	Warehouse         string    `json:"warehouse" gorm:"size:64;uniqueIndex:idx_stock_album_warehouse"`
+	OnHand            int       `json:"on_hand"`
+	Reserved          int       `json:"reserved"`
+	LowStockThreshold int       `json:"low_stock_threshold"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// Available is the quantity that can still be reserved.
+func (l StockLevel) Available() int {
+	return l.OnHand - l.Reserved
+}
+
+// LowStock reports whether availability dropped to or below the threshold.
+func (l StockLevel) LowStock() bool {
+	return l.LowStockThreshold > 0 && l.Available() <= l.LowStockThreshold
+}
+
+// FellToLowStock reports whether the level became low since it was before, so an alert is
+// raised once when availability crosses the threshold rather than on every change below it.
+func (l StockLevel) FellToLowStock(before StockLevel) bool {
+	return l.LowStock() && !before.LowStock()
+}
+
+// SetOnHand replaces the physical count, which may never drop below what is already reserved.
+func (l *StockLevel) SetOnHand(quantity int) error {
+	if quantity < 0 {
+		return ErrInvalidQuantity
+	}
+	if quantity < l.Reserved {
+		return ErrStockBelowReservation
+	}
+	l.OnHand = quantity
+	return nil
+}
+
+func (l *StockLevel) reserve(quantity int) error {
+	if quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+	if quantity > l.Available() {
+		return ErrInsufficientStock
+	}
+	l.Reserved += quantity
+	return nil
+}
+
+// Lifecycle of a reservation: active until it is either released or committed.
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationReleased  ReservationStatus = "released"
+	ReservationCommitted ReservationStatus = "committed"
+)
+
+// Quantity of an album held in a warehouse until it is committed (shipped) or released.
+type Reservation struct {
+	ID        uint              `json:"id" gorm:"primaryKey"`
+	AlbumID   uint              `json:"album_id" gorm:"index"`
+	Warehouse string            `json:"warehouse" gorm:"size:64"`
+	Quantity  int               `json:"quantity"`
+	Status    ReservationStatus `json:"status" gorm:"size:16"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// Reserve holds quantity on the level and returns the matching active reservation.
+func (l *StockLevel) Reserve(quantity int) (Reservation, error) {
+	if err := l.reserve(quantity); err != nil {
+		return Reservation{}, err
+	}
+	return Reservation{AlbumID: l.AlbumID, Warehouse: l.Warehouse, Quantity: quantity, Status: ReservationActive}, nil
+}
+
+// Release gives the reserved quantity back to the level.
+func (r *Reservation) Release(level *StockLevel) error {
+	if r.Status != ReservationActive {
+		return ErrReservationNotActive
+	}
+	level.Reserved -= r.Quantity
+	r.Status = ReservationReleased
+	return nil
+}
+
+// Commit removes the reserved quantity from stock, e.g. once it has been shipped.
+func (r *Reservation) Commit(level *StockLevel) error {
+	if r.Status != ReservationActive {
+		return ErrReservationNotActive
+	}
+	level.Reserved -= r.Quantity
+	level.OnHand -= r.Quantity
+	r.Status = ReservationCommitted
+	return nil
+}
+
+// Album stock across all warehouses.
+type StockSummary struct {
+	AlbumID   uint         `json:"album_id"`
+	OnHand    int          `json:"on_hand"`
+	Reserved  int          `json:"reserved"`
+	Available int          `json:"available"`
+	Levels    []StockLevel `json:"warehouses"`
+}
+
+func SummarizeStock(albumID uint, levels []StockLevel) StockSummary {
+	summary := StockSummary{AlbumID: albumID, Levels: levels}
+	if summary.Levels == nil {
+		summary.Levels = []StockLevel{}
+	}
+	for _, level := range levels {
+		summary.OnHand += level.OnHand
+		summary.Reserved += level.Reserved
+		summary.Available += level.Available()
+	}
+	return summary
+}
+
+// Low stock alert port, notified when a stock level falls to its threshold.
+type StockAlerter interface {
+	LowStock(level StockLevel)
+}
+
+// Inventory service interface definition (port).
+type InventoryService interface {
+	CommitReservation(albumID int, reservationID int) (Reservation, error)
+	GetStock(albumID int) (StockSummary, error)
+	ReleaseReservation(albumID int, reservationID int) (Reservation, error)
+	Reserve(albumID int, warehouse string, quantity int) (Reservation, error)
+	SetStock(level StockLevel) (StockLevel, error)
+}
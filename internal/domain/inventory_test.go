@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInventory(t *testing.T) {
+	t.Run("Reserve :: holds available stock", func(t *testing.T) {
+		level := StockLevel{AlbumID: 1, Warehouse: "waw", OnHand: 5, LowStockThreshold: 2}
+		reservation, err := level.Reserve(3)
+		assert.Nil(t, err)
+		assert.Equal(t, ReservationActive, reservation.Status)
+		assert.Equal(t, 2, level.Available())
+		assert.True(t, level.LowStock())
+	})
+
+	t.Run("Reserve :: never oversells", func(t *testing.T) {
+		level := StockLevel{OnHand: 2, Reserved: 1}
+		_, err := level.Reserve(2)
+		assert.ErrorIs(t, err, ErrInsufficientStock)
+		assert.Equal(t, 1, level.Reserved)
+
+		_, err = level.Reserve(0)
+		assert.ErrorIs(t, err, ErrInvalidQuantity)
+	})
+
+	t.Run("Commit :: removes stock once", func(t *testing.T) {
+		level := StockLevel{OnHand: 5}
+		reservation, _ := level.Reserve(2)
+		assert.Nil(t, reservation.Commit(&level))
+		assert.Equal(t, 3, level.OnHand)
+		assert.Equal(t, 0, level.Reserved)
+		assert.ErrorIs(t, reservation.Commit(&level), ErrReservationNotActive)
+		assert.ErrorIs(t, reservation.Release(&level), ErrReservationNotActive)
+	})
+
+	t.Run("Release :: returns stock", func(t *testing.T) {
+		level := StockLevel{OnHand: 5}
+		reservation, _ := level.Reserve(2)
+		assert.Nil(t, reservation.Release(&level))
+		assert.Equal(t, 5, level.Available())
+		assert.Equal(t, ReservationReleased, reservation.Status)
+	})
+
+	t.Run("SetOnHand :: cannot drop below reserved", func(t *testing.T) {
+		level := StockLevel{OnHand: 5, Reserved: 3}
+		assert.ErrorIs(t, level.SetOnHand(2), ErrStockBelowReservation)
+		assert.Nil(t, level.SetOnHand(3))
+	})
+
+	t.Run("FellToLowStock :: only when crossing the threshold", func(t *testing.T) {
+		before := StockLevel{OnHand: 5, LowStockThreshold: 2}
+		after := before
+		after.Reserved = 3
+		assert.True(t, after.FellToLowStock(before))
+
+		further := after
+		further.Reserved = 4
+		assert.False(t, further.FellToLowStock(after))
+		assert.False(t, before.FellToLowStock(after))
+	})
+}
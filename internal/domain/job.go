@@ -0,0 +1,212 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var (
+	ErrInvalidJob      = errors.New("invalid job")
+	ErrJobFinished     = errors.New("job already finished")
+	ErrJobNotClaimable = errors.New("job is not waiting for a worker")
+	ErrJobLeaseLost    = errors.New("job is no longer held by this worker")
+	ErrNoJobResult     = errors.New("job has no result to download")
+)
+
+// Attempts after which a job that keeps being interrupted, for instance because it crashes
+// the process, is failed instead of being picked up again.
+const MaxJobAttempts = 3
+
+// Work done by a job, each kind has its JobExecutor.
+type JobKind string
+
+const (
+	JobImport JobKind = "import"
+	JobExport JobKind = "export"
+)
+
+// Job lifecycle: queued -> running -> succeeded | failed | cancelled. A running job goes back to
+// queued when its worker shuts down and is picked up again once its lease expired.
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+// Parameters of an import job, the uploaded file is kept under the job's InputKey.
+type ImportParams struct {
+	ContentType string            `json:"content_type"`
+	Mapping     map[string]string `json:"mapping"`
+	DryRun      bool              `json:"dry_run"`
+}
+
+// Parameters of an export job.
+type ExportParams struct {
+	Format string      `json:"format"`
+	Filter AlbumFilter `json:"filter"`
+}
+
+// Long-running work done in the background by a pool of workers. Params and the stored input
+// tell the executor what to do, Processed and Total report progress. The worker holding the job
+// renews its lease until the job finishes, an expired lease hands the job to another worker.
+type Job struct {
+	ID     uint      `json:"id" gorm:"primaryKey"`
+	Kind   JobKind   `json:"kind" gorm:"size:16"`
+	Status JobStatus `json:"status" gorm:"size:16;index"`
+	// JSON encoded ImportParams or ExportParams
+	Params   string `json:"-" gorm:"type:text"`
+	InputKey string `json:"-" gorm:"size:255"`
+
+	Processed int  `json:"processed"`
+	Total     *int `json:"total,omitempty"`
+	// Last album ID written by an export, it resumes after it
+	Cursor uint `json:"-"`
+	// Result blobs written so far, read in order as one file
+	Parts      int           `json:"-"`
+	ResultType string        `json:"-" gorm:"size:64"`
+	Report     *ImportReport `json:"report,omitempty" gorm:"serializer:json;type:text"`
+	Error      string        `json:"error,omitempty" gorm:"size:255"`
+
+	Attempts        int       `json:"attempts"`
+	CancelRequested bool      `json:"cancel_requested,omitempty"`
+	Holder          string    `json:"-" gorm:"size:255"`
+	LeaseExpiresAt  time.Time `json:"-"`
+
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Finished tells whether the job reached a final status.
+func (j Job) Finished() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed || j.Status == JobCancelled
+}
+
+// Claimable tells whether a worker may take the job, it is queued or its worker stopped
+// renewing the lease.
+func (j Job) Claimable(now time.Time) bool {
+	return j.Status == JobQueued || j.Status == JobRunning && j.LeaseExpiresAt.Before(now)
+}
+
+// Claim hands the job to holder until now+ttl. A job interrupted MaxJobAttempts times is failed
+// instead, the caller only runs jobs claimed as running.
+func (j *Job) Claim(holder string, now time.Time, ttl time.Duration) error {
+	if !j.Claimable(now) {
+		return ErrJobNotClaimable
+	}
+	if j.Attempts >= MaxJobAttempts {
+		j.Status, j.FinishedAt, j.Holder = JobFailed, &now, ""
+		j.Error = fmt.Sprintf("interrupted %d times, giving up", j.Attempts)
+		return nil
+	}
+	j.Status, j.Holder, j.LeaseExpiresAt = JobRunning, holder, now.Add(ttl)
+	j.Attempts++
+	if j.StartedAt == nil {
+		j.StartedAt = &now
+	}
+	return nil
+}
+
+// Renew extends the lease of holder.
+func (j *Job) Renew(holder string, now time.Time, ttl time.Duration) error {
+	if j.Status != JobRunning || j.Holder != holder {
+		return ErrJobLeaseLost
+	}
+	j.LeaseExpiresAt = now.Add(ttl)
+	return nil
+}
+
+// Requeue gives the job back when its worker shuts down, the next worker resumes it. An orderly
+// shutdown does not count as an attempt.
+func (j *Job) Requeue(holder string) error {
+	if j.Status != JobRunning || j.Holder != holder {
+		return ErrJobLeaseLost
+	}
+	j.Status, j.Holder, j.LeaseExpiresAt = JobQueued, "", time.Time{}
+	j.Attempts--
+	return nil
+}
+
+// Finish records the outcome of the run, err is nil on success. A job that failed after
+// cancellation was requested counts as cancelled.
+func (j *Job) Finish(holder string, err error, now time.Time) error {
+	if j.Status != JobRunning || j.Holder != holder {
+		return ErrJobLeaseLost
+	}
+	j.Status, j.FinishedAt, j.Holder, j.LeaseExpiresAt = JobSucceeded, &now, "", time.Time{}
+	switch {
+	case err == nil:
+	case j.CancelRequested:
+		j.Status = JobCancelled
+	default:
+		j.Status, j.Error = JobFailed, truncate(err.Error(), 255)
+	}
+	return nil
+}
+
+// Cancel stops a queued job right away, a running one stops once its worker notices.
+func (j *Job) Cancel(now time.Time) error {
+	switch {
+	case j.Finished():
+		return ErrJobFinished
+	case j.Status == JobQueued:
+		j.Status, j.FinishedAt, j.CancelRequested = JobCancelled, &now, true
+	default:
+		j.CancelRequested = true
+	}
+	return nil
+}
+
+// HasResult tells whether a result file can be downloaded, imports report inline instead.
+func (j Job) HasResult() bool {
+	return j.Status == JobSucceeded && j.Parts > 0
+}
+
+// ResultKey names the blob of a result part, parts are numbered from 1.
+func (j Job) ResultKey(part int) string {
+	return fmt.Sprintf("jobs/%d/part-%05d", j.ID, part)
+}
+
+// JobProgress saves the changes update makes to the running job and renews its lease. The
+// returned job tells whether cancellation was requested. It fails with ErrJobLeaseLost once the
+// job was handed to another worker.
+type JobProgress func(update func(job *Job)) (Job, error)
+
+// JobExecutor does the work of one kind of job. Execute must stop when ctx is done and may be
+// called again for a job that was interrupted, it resumes from the progress saved so far.
+type JobExecutor interface {
+	Execute(ctx context.Context, job Job, progress JobProgress) error
+}
+
+// Files of import and export jobs, in the formats the album endpoints accept and return (port).
+type AlbumFiles interface {
+	// ReadImport reads the rows of an import file in the media type of params.
+	ReadImport(params ImportParams, input io.Reader) ([]ImportRow, error)
+	// NewExportWriter returns a writer of format to w and the media type it writes. The writer
+	// starts with the header of the format, if it has one, when header is true.
+	NewExportWriter(format string, w io.Writer, header bool) (AlbumWriter, string)
+}
+
+// AlbumWriter writes the rows of an export file, Flush reports write errors.
+type AlbumWriter interface {
+	Write(albums []Album) error
+	Flush() error
+}
+
+// Job service interface definition.
+type JobService interface {
+	// StartJob queues a job, params are passed to its executor and input is stored for it
+	StartJob(kind JobKind, params interface{}, input []byte) (Job, error)
+	GetJob(id int) (Job, error)
+	CancelJob(id int) (Job, error)
+	// OpenJobResult reads the result of a succeeded job. The caller closes the reader.
+	OpenJobResult(job Job) (io.ReadCloser, error)
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobs(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Claim :: hands queued jobs and expired leases to a worker", func(t *testing.T) {
+		job := Job{Status: JobQueued}
+		assert.NoError(t, job.Claim("a", now, time.Minute))
+		assert.Equal(t, JobRunning, job.Status)
+		assert.Equal(t, 1, job.Attempts)
+		assert.Equal(t, now, *job.StartedAt)
+		assert.ErrorIs(t, job.Claim("b", now.Add(30*time.Second), time.Minute), ErrJobNotClaimable)
+
+		assert.NoError(t, job.Claim("b", now.Add(2*time.Minute), time.Minute))
+		assert.Equal(t, "b", job.Holder)
+		assert.Equal(t, 2, job.Attempts)
+		assert.Equal(t, now, *job.StartedAt)
+		assert.ErrorIs(t, job.Renew("a", now, time.Minute), ErrJobLeaseLost)
+	})
+
+	t.Run("Claim :: fails jobs interrupted too often", func(t *testing.T) {
+		job := Job{Status: JobQueued, Attempts: MaxJobAttempts}
+		assert.NoError(t, job.Claim("a", now, time.Minute))
+		assert.Equal(t, JobFailed, job.Status)
+		assert.Equal(t, "interrupted 3 times, giving up", job.Error)
+	})
+
+	t.Run("Requeue :: gives a running job back", func(t *testing.T) {
+		job := Job{Status: JobQueued}
+		assert.NoError(t, job.Claim("a", now, time.Minute))
+		assert.ErrorIs(t, job.Requeue("b"), ErrJobLeaseLost)
+		assert.NoError(t, job.Requeue("a"))
+		assert.Equal(t, JobQueued, job.Status)
+		assert.Equal(t, 0, job.Attempts)
+		assert.True(t, job.Claimable(now))
+	})
+
+	t.Run("Finish :: records success, failure and cancellation", func(t *testing.T) {
+		job := Job{Status: JobRunning, Holder: "a"}
+		assert.NoError(t, job.Finish("a", nil, now))
+		assert.Equal(t, JobSucceeded, job.Status)
+		assert.ErrorIs(t, job.Finish("a", nil, now), ErrJobLeaseLost)
+
+		job = Job{Status: JobRunning, Holder: "a"}
+		assert.NoError(t, job.Finish("a", errors.New("database is down"), now))
+		assert.Equal(t, JobFailed, job.Status)
+		assert.Equal(t, "database is down", job.Error)
+
+		job = Job{Status: JobRunning, Holder: "a", CancelRequested: true}
+		assert.NoError(t, job.Finish("a", errors.New("context canceled"), now))
+		assert.Equal(t, JobCancelled, job.Status)
+		assert.Empty(t, job.Error)
+	})
+
+	t.Run("Cancel :: stops queued jobs and flags running ones", func(t *testing.T) {
+		job := Job{Status: JobQueued}
+		assert.NoError(t, job.Cancel(now))
+		assert.Equal(t, JobCancelled, job.Status)
+		assert.ErrorIs(t, job.Cancel(now), ErrJobFinished)
+
+		job = Job{Status: JobRunning}
+		assert.NoError(t, job.Cancel(now))
+		assert.Equal(t, JobRunning, job.Status)
+		assert.True(t, job.CancelRequested)
+	})
+}
@@ -0,0 +1,10 @@
+package domain
+
+import "time"
+
+// Named lock held by one process until ExpiresAt, used to elect a single replica for background work.
+type Lease struct {
+	Name      string `gorm:"primaryKey;size:64"`
+	Holder    string `gorm:"size:255"`
+	ExpiresAt time.Time
+}
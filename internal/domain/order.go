@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"time"
+)
+
+var (
+	ErrInvalidOrder           = errors.New("invalid order")
+	ErrInvalidOrderTransition = errors.New("invalid order status transition")
+)
+
+// Order lifecycle: created -> paid -> shipped, created or paid orders may be cancelled.
+type OrderStatus string
+
+const (
+	OrderCreated   OrderStatus = "created"
+	OrderPaid      OrderStatus = "paid"
+	OrderShipped   OrderStatus = "shipped"
+	OrderCancelled OrderStatus = "cancelled"
+)
+
+// Order aggregate, albums are referenced by ID with their title and price captured at order time.
+type Order struct {
+	ID            uint        `json:"id" gorm:"primaryKey"`
+	CustomerEmail string      `json:"customer_email" gorm:"size:255;index"`
+	Status        OrderStatus `json:"status" gorm:"size:16;index"`
+	Currency      string      `json:"currency" gorm:"size:3"`
+	Total         float64     `json:"total"`
+	Items         []OrderItem `json:"items" gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+	PaidAt        *time.Time  `json:"paid_at,omitempty"`
+	ShippedAt     *time.Time  `json:"shipped_at,omitempty"`
+	CancelledAt   *time.Time  `json:"cancelled_at,omitempty"`
+}
+
+// Order line with a snapshot of the album at order time.
+type OrderItem struct {
+	ID        uint    `json:"-" gorm:"primaryKey"`
+	OrderID   uint    `json:"-" gorm:"index"`
+	AlbumID   uint    `json:"album_id" gorm:"index"`
+	Title     string  `json:"title" gorm:"size:255"`
+	UnitPrice float64 `json:"unit_price"`
+	Quantity  int     `json:"quantity"`
+	// Stock reservation held for physical items, nil for digital ones
+	ReservationID *uint `json:"reservation_id,omitempty"`
+	RequiresStock bool  `json:"-" gorm:"-"`
+}
+
+// RequiresStock reports whether the album is a physical copy that must be reserved in inventory.
+func (a Album) RequiresStock() bool {
+	return a.Format != FormatDigital
+}
+
+// NewOrder builds a created order, taking title and price snapshots from albums (keyed by ID).
+// Items are charged the effective unit price in prices, albums without one their list price.
+func NewOrder(customerEmail string, items []OrderItem, albums map[uint]Album, prices map[uint]float64) (Order, error) {
+	if _, err := mail.ParseAddress(customerEmail); err != nil {
+		return Order{}, fmt.Errorf("%w: invalid customer email", ErrInvalidOrder)
+	}
+	if len(items) == 0 {
+		return Order{}, fmt.Errorf("%w: order has no items", ErrInvalidOrder)
+	}
+	order := Order{CustomerEmail: customerEmail, Status: OrderCreated, Currency: BaseCurrency}
+	for _, item := range items {
+		album, ok := albums[item.AlbumID]
+		if !ok {
+			return Order{}, fmt.Errorf("%w: album %d does not exist", ErrInvalidOrder, item.AlbumID)
+		}
+		if !album.IsPublic() {
+			return Order{}, fmt.Errorf("%w: album %d is not published", ErrInvalidOrder, item.AlbumID)
+		}
+		if item.Quantity <= 0 {
+			return Order{}, fmt.Errorf("%w: quantity of album %d must be positive", ErrInvalidOrder, item.AlbumID)
+		}
+		unitPrice, ok := prices[album.ID]
+		if !ok {
+			unitPrice = album.Price
+		}
+		order.Items = append(order.Items, OrderItem{
+			AlbumID:   album.ID,
+			Title:     album.Title,
+			UnitPrice: unitPrice,
+			Quantity:  item.Quantity,
+
+			RequiresStock: album.RequiresStock(),
+		})
+	}
+	order.RecalculateTotal()
+	return order, nil
+}
+
+// RecalculateTotal sums item prices, rounded to cents.
+func (o *Order) RecalculateTotal() {
+	total := 0.0
+	for _, item := range o.Items {
+		total += item.UnitPrice * float64(item.Quantity)
+	}
+	o.Total = math.Round(total*100) / 100
+}
+
+func (o *Order) MarkPaid(at time.Time) error {
+	if o.Status != OrderCreated {
+		return o.transitionError(OrderPaid)
+	}
+	o.Status, o.PaidAt = OrderPaid, &at
+	return nil
+}
+
+func (o *Order) MarkShipped(at time.Time) error {
+	if o.Status != OrderPaid {
+		return o.transitionError(OrderShipped)
+	}
+	o.Status, o.ShippedAt = OrderShipped, &at
+	return nil
+}
+
+func (o *Order) Cancel(at time.Time) error {
+	if o.Status != OrderCreated && o.Status != OrderPaid {
+		return o.transitionError(OrderCancelled)
+	}
+	o.Status, o.CancelledAt = OrderCancelled, &at
+	return nil
+}
+
+func (o *Order) transitionError(to OrderStatus) error {
+	return fmt.Errorf("%w: %s order cannot become %s", ErrInvalidOrderTransition, o.Status, to)
+}
+
+// Order service interface definition.
+type OrderService interface {
+	CancelOrder(id int) (Order, error)
+	GetAllOrders() ([]Order, error)
+	GetOrderByID(id int) (Order, error)
+	PlaceOrder(customerEmail string, items []OrderItem) (Order, error)
+	ShipOrder(id int) (Order, error)
+}
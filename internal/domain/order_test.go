@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrder(t *testing.T) {
+	albums := map[uint]Album{
+		1: {ID: 1, Title: "Abbey Road", Price: 9.99, Format: FormatVinyl, Status: AlbumPublished},
+		2: {ID: 2, Title: "Let It Be", Price: 4.5, Format: FormatDigital, Status: AlbumPublished},
+		3: {ID: 3, Title: "Get Back", Price: 12, Format: FormatVinyl, Status: AlbumDraft},
+	}
+	now := time.Now()
+
+	t.Run("NewOrder :: captures price snapshots", func(t *testing.T) {
+		order, err := NewOrder("fan@example.com", []OrderItem{{AlbumID: 1, Quantity: 2}, {AlbumID: 2, Quantity: 1}}, albums, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, OrderCreated, order.Status)
+		assert.Equal(t, 24.48, order.Total)
+		assert.Equal(t, "Abbey Road", order.Items[0].Title)
+		assert.Equal(t, 9.99, order.Items[0].UnitPrice)
+		assert.True(t, order.Items[0].RequiresStock)
+		assert.False(t, order.Items[1].RequiresStock)
+	})
+
+	t.Run("NewOrder :: charges effective prices", func(t *testing.T) {
+		order, err := NewOrder("fan@example.com", []OrderItem{{AlbumID: 1, Quantity: 2}, {AlbumID: 2, Quantity: 1}}, albums, map[uint]float64{1: 7.99})
+		assert.Nil(t, err)
+		assert.Equal(t, 7.99, order.Items[0].UnitPrice)
+		assert.Equal(t, 4.5, order.Items[1].UnitPrice)
+		assert.Equal(t, 20.48, order.Total)
+	})
+
+	t.Run("NewOrder :: rejects unknown albums and bad quantities", func(t *testing.T) {
+		_, err := NewOrder("fan@example.com", []OrderItem{{AlbumID: 4, Quantity: 1}}, albums, nil)
+		assert.ErrorIs(t, err, ErrInvalidOrder)
+		_, err = NewOrder("fan@example.com", []OrderItem{{AlbumID: 3, Quantity: 1}}, albums, nil)
+		assert.ErrorIs(t, err, ErrInvalidOrder)
+		_, err = NewOrder("fan@example.com", []OrderItem{{AlbumID: 1, Quantity: 0}}, albums, nil)
+		assert.ErrorIs(t, err, ErrInvalidOrder)
+		_, err = NewOrder("not an email", []OrderItem{{AlbumID: 1, Quantity: 1}}, albums, nil)
+		assert.ErrorIs(t, err, ErrInvalidOrder)
+	})
+
+	t.Run("Lifecycle :: created -> paid -> shipped", func(t *testing.T) {
+		order := Order{Status: OrderCreated}
+		assert.ErrorIs(t, order.MarkShipped(now), ErrInvalidOrderTransition)
+		assert.Nil(t, order.MarkPaid(now))
+		assert.Nil(t, order.MarkShipped(now))
+		assert.Equal(t, OrderShipped, order.Status)
+		assert.ErrorIs(t, order.Cancel(now), ErrInvalidOrderTransition)
+	})
+
+	t.Run("Lifecycle :: cancelled orders are final", func(t *testing.T) {
+		order := Order{Status: OrderCreated}
+		assert.Nil(t, order.Cancel(now))
+		assert.ErrorIs(t, order.MarkPaid(now), ErrInvalidOrderTransition)
+	})
+}
@@ -0,0 +1,173 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrPaymentDeclined         = errors.New("payment declined")
+	ErrPaymentGateway          = errors.New("payment gateway failed")
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	ErrInvalidWebhookEvent     = errors.New("invalid webhook event")
+	ErrInvalidPaymentState     = errors.New("invalid payment state")
+)
+
+// Payment lifecycle: authorized -> captured -> refunded, an authorization may fail instead.
+// A payment is capturing or refunding while the gateway is asked to, which claims it for that call.
+type PaymentStatus string
+
+const (
+	PaymentAuthorized PaymentStatus = "authorized"
+	PaymentCapturing  PaymentStatus = "capturing"
+	PaymentCaptured   PaymentStatus = "captured"
+	PaymentRefunding  PaymentStatus = "refunding"
+	PaymentRefunded   PaymentStatus = "refunded"
+	PaymentFailed     PaymentStatus = "failed"
+)
+
+// Payment of an order through a payment gateway.
+type Payment struct {
+	ID            uint          `json:"id" gorm:"primaryKey"`
+	OrderID       uint          `json:"order_id" gorm:"index"`
+	Gateway       string        `json:"gateway" gorm:"size:32"`
+	GatewayRef    string        `json:"gateway_ref" gorm:"size:255;index"`
+	Amount        float64       `json:"amount"`
+	Refunded      float64       `json:"refunded"`
+	Refunding     float64       `json:"refunding,omitempty"`
+	Currency      string        `json:"currency" gorm:"size:3"`
+	Status        PaymentStatus `json:"status" gorm:"size:16"`
+	FailureReason string        `json:"failure_reason,omitempty" gorm:"size:255"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+// CanBecome reports whether the lifecycle allows moving the payment to status.
+func (p Payment) CanBecome(status PaymentStatus) bool {
+	switch status {
+	case PaymentCaptured, PaymentFailed:
+		return p.Status == PaymentAuthorized || p.Status == PaymentCapturing
+	case PaymentRefunded:
+		return p.Status == PaymentCaptured || p.Status == PaymentRefunding
+	}
+	return false
+}
+
+// Live reports whether the payment holds or collected the money of its order.
+func (p Payment) Live() bool {
+	switch p.Status {
+	case PaymentAuthorized, PaymentCapturing, PaymentCaptured, PaymentRefunding:
+		return true
+	}
+	return false
+}
+
+// Transition moves the payment to status, reason tells why it failed. A payment that becomes
+// refunded is refunded in full.
+func (p *Payment) Transition(status PaymentStatus, reason string) error {
+	if !p.CanBecome(status) {
+		return fmt.Errorf("%w: %s payment cannot become %s", ErrInvalidPaymentState, p.Status, status)
+	}
+	p.Status, p.FailureReason = status, reason
+	if status == PaymentRefunded {
+		p.Refunded, p.Refunding = p.Amount, 0
+	}
+	return nil
+}
+
+// ClaimCapture marks an authorized payment as capturing, Transition records the gateway outcome.
+func (p *Payment) ClaimCapture() error {
+	if p.Status != PaymentAuthorized {
+		return fmt.Errorf("%w: %s payment cannot be captured", ErrInvalidPaymentState, p.Status)
+	}
+	p.Status = PaymentCapturing
+	return nil
+}
+
+// RefundAmount returns the amount refunding takes back, the remaining balance for a zero amount.
+func (p Payment) RefundAmount(amount float64) (float64, error) {
+	remaining := roundCents(p.Amount - p.Refunded)
+	if amount == 0 {
+		amount = remaining
+	}
+	if p.Status != PaymentCaptured || amount <= 0 || amount > remaining {
+		return 0, fmt.Errorf("%w: cannot refund %.2f of a %s payment", ErrInvalidPaymentState, amount, p.Status)
+	}
+	return amount, nil
+}
+
+// ClaimRefund marks a captured payment as refunding amount, CompleteRefund records it once refunded.
+func (p *Payment) ClaimRefund(amount float64) error {
+	amount, err := p.RefundAmount(amount)
+	if err != nil {
+		return err
+	}
+	p.Status, p.Refunding = PaymentRefunding, amount
+	return nil
+}
+
+// CompleteRefund records the claimed refund, the payment becomes refunded once nothing is left.
+// A payment a webhook reported as refunded meanwhile stays as it is.
+func (p *Payment) CompleteRefund() {
+	if p.Status != PaymentRefunding {
+		return
+	}
+	p.Refunded = roundCents(p.Refunded + p.Refunding)
+	p.Status, p.Refunding = PaymentCaptured, 0
+	if p.Refunded >= p.Amount {
+		p.Status = PaymentRefunded
+	}
+}
+
+// Release gives up a capture or refund claim after the gateway call failed.
+func (p *Payment) Release() {
+	switch p.Status {
+	case PaymentCapturing:
+		p.Status = PaymentAuthorized
+	case PaymentRefunding:
+		p.Status, p.Refunding = PaymentCaptured, 0
+	}
+}
+
+// Authorization request sent to a gateway. Source is a card number or a gateway payment method token.
+type PaymentAuthorization struct {
+	Reference string
+	Amount    float64
+	Currency  string
+	Source    string
+}
+
+// Gateway response to an authorize, capture or refund call.
+type PaymentResult struct {
+	GatewayRef string
+	Status     PaymentStatus
+	Reason     string
+}
+
+// Verified gateway notification about a payment status change.
+type PaymentEvent struct {
+	ID         string
+	GatewayRef string
+	Status     PaymentStatus
+}
+
+// Payment gateway interface definition (port).
+// Declines are reported through ErrPaymentDeclined, transport failures through any other error.
+type PaymentGateway interface {
+	Name() string
+	Authorize(authorization PaymentAuthorization) (PaymentResult, error)
+	Capture(gatewayRef string, amount float64, currency string) (PaymentResult, error)
+	Refund(gatewayRef string, amount float64, currency string) (PaymentResult, error)
+	VerifyWebhook(payload []byte, signature string) (PaymentEvent, error)
+}
+
+// Payment service interface definition.
+type PaymentService interface {
+	AuthorizeOrder(orderID int, source string) (Payment, error)
+	CapturePayment(id int) (Payment, error)
+	GetPaymentByID(id int) (Payment, error)
+	GetPaymentsByOrder(orderID int) ([]Payment, error)
+	HandleWebhook(payload []byte, signature string) (Payment, error)
+	RefundPayment(id int, amount float64) (Payment, error)
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayment(t *testing.T) {
+	t.Run("Transition :: follows the payment lifecycle", func(t *testing.T) {
+		payment := Payment{Amount: 20, Status: PaymentAuthorized}
+		assert.Nil(t, payment.Transition(PaymentCaptured, ""))
+		assert.ErrorIs(t, payment.Transition(PaymentAuthorized, ""), ErrInvalidPaymentState)
+		assert.ErrorIs(t, payment.Transition(PaymentFailed, "card_declined"), ErrInvalidPaymentState)
+		assert.Nil(t, payment.Transition(PaymentRefunded, ""))
+		assert.Equal(t, 20.0, payment.Refunded)
+		assert.ErrorIs(t, payment.Transition(PaymentCaptured, ""), ErrInvalidPaymentState)
+	})
+
+	t.Run("CanBecome :: never leaves failed payments", func(t *testing.T) {
+		payment := Payment{Status: PaymentFailed}
+		for _, status := range []PaymentStatus{PaymentAuthorized, PaymentCaptured, PaymentRefunded, PaymentFailed} {
+			assert.False(t, payment.CanBecome(status))
+		}
+	})
+
+	t.Run("ClaimRefund :: refunds up to the captured amount", func(t *testing.T) {
+		payment := Payment{Amount: 20, Status: PaymentCaptured}
+		assert.Nil(t, payment.ClaimRefund(5.5))
+		assert.Equal(t, PaymentRefunding, payment.Status)
+		assert.ErrorIs(t, payment.ClaimRefund(1), ErrInvalidPaymentState)
+		payment.CompleteRefund()
+		assert.Equal(t, PaymentCaptured, payment.Status)
+		assert.Equal(t, 5.5, payment.Refunded)
+		assert.ErrorIs(t, payment.ClaimRefund(15), ErrInvalidPaymentState)
+
+		amount, err := payment.RefundAmount(0)
+		assert.Nil(t, err)
+		assert.Equal(t, 14.5, amount)
+		assert.Nil(t, payment.ClaimRefund(0))
+		payment.CompleteRefund()
+		assert.Equal(t, PaymentRefunded, payment.Status)
+		assert.Equal(t, 0.0, payment.Refunding)
+		assert.ErrorIs(t, payment.ClaimRefund(0), ErrInvalidPaymentState)
+	})
+
+	t.Run("ClaimCapture :: claims authorized payments once", func(t *testing.T) {
+		payment := Payment{Amount: 20, Status: PaymentAuthorized}
+		assert.Nil(t, payment.ClaimCapture())
+		assert.ErrorIs(t, payment.ClaimCapture(), ErrInvalidPaymentState)
+		assert.True(t, payment.Live())
+		payment.Release()
+		assert.Equal(t, PaymentAuthorized, payment.Status)
+
+		assert.Nil(t, payment.ClaimCapture())
+		assert.Nil(t, payment.Transition(PaymentCaptured, ""))
+		assert.Nil(t, payment.ClaimRefund(5))
+		payment.Release()
+		assert.Equal(t, PaymentCaptured, payment.Status)
+		assert.Equal(t, 0.0, payment.Refunding)
+	})
+}
@@ -0,0 +1,197 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var ErrInvalidPriceHistoryQuery = errors.New("invalid price history query")
+
+// Window used by the EU Omnibus Directive "lowest price in the last 30 days" rule.
+const OmnibusWindow = 30 * 24 * time.Hour
+
+// Reasons recorded when none is given with the price.
+const (
+	PriceReasonInitial = "initial price"
+	PriceReasonUpdate  = "manual update"
+	// Given by AlbumService.ImportAlbums
+	PriceReasonImport = "bulk import"
+)
+
+// Recorded change of an album price.
+type PriceChange struct {
+	ID            uint      `json:"-" gorm:"primaryKey"`
+	AlbumID       uint      `json:"-" gorm:"index:idx_price_changes_album_changed_at"`
+	Price         float64   `json:"price"`
+	PreviousPrice *float64  `json:"previous_price,omitempty"`
+	Reason        string    `json:"reason" gorm:"size:255"`
+	ChangedAt     time.Time `json:"changed_at" gorm:"index:idx_price_changes_album_changed_at"`
+}
+
+type PriceBucketSize string
+
+const (
+	BucketNone PriceBucketSize = ""
+	BucketDay  PriceBucketSize = "day"
+	BucketWeek PriceBucketSize = "week"
+)
+
+// Time range and bucketing of a price history request.
+type PriceHistoryQuery struct {
+	From   time.Time
+	To     time.Time
+	Bucket PriceBucketSize
+}
+
+// Price aggregates over [Start, End). Avg is weighted by how long each price was in effect.
+type PriceBucket struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Avg   float64   `json:"avg"`
+	Close float64   `json:"close"`
+}
+
+// Validate checks the range and bucket size of the query.
+func (q PriceHistoryQuery) Validate() error {
+	if !q.From.Before(q.To) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidPriceHistoryQuery)
+	}
+	switch q.Bucket {
+	case BucketNone, BucketDay, BucketWeek:
+		return nil
+	}
+	return fmt.Errorf("%w: unknown bucket %q", ErrInvalidPriceHistoryQuery, q.Bucket)
+}
+
+// Album price time series with aggregates over the requested range.
+type PriceHistory struct {
+	AlbumID uint          `json:"album_id"`
+	From    time.Time     `json:"from"`
+	To      time.Time     `json:"to"`
+	Changes []PriceChange `json:"changes"`
+	Buckets []PriceBucket `json:"buckets,omitempty"`
+	Min     *float64      `json:"min"`
+	Max     *float64      `json:"max"`
+	Avg     *float64      `json:"avg"`
+	// Lowest price in effect during the 30 days before To
+	Lowest30Days *float64 `json:"lowest_30_days"`
+}
+
+// Span of time during which a single price was in effect.
+type priceSegment struct {
+	start, end time.Time
+	price      float64
+}
+
+// BuildPriceHistory aggregates changes (ordered by ChangedAt, including the last change before
+// query.From, if any) over the query range.
+func BuildPriceHistory(albumID uint, changes []PriceChange, query PriceHistoryQuery) PriceHistory {
+	history := PriceHistory{AlbumID: albumID, From: query.From, To: query.To, Changes: []PriceChange{}}
+	for _, change := range changes {
+		if !change.ChangedAt.Before(query.From) && !change.ChangedAt.After(query.To) {
+			history.Changes = append(history.Changes, change)
+		}
+	}
+
+	if overall, ok := aggregate(segments(changes, query.From, query.To), query.From, query.To); ok {
+		history.Min, history.Max, history.Avg = &overall.Min, &overall.Max, &overall.Avg
+	}
+	if lowest, ok := LowestPrice(changes, query.To.Add(-OmnibusWindow), query.To); ok {
+		history.Lowest30Days = &lowest
+	}
+
+	if query.Bucket != BucketNone {
+		history.Buckets = []PriceBucket{}
+		for start := bucketStart(query.From, query.Bucket); start.Before(query.To); start = nextBucket(start, query.Bucket) {
+			end := nextBucket(start, query.Bucket)
+			from, to := maxTime(start, query.From), minTime(end, query.To)
+			if bucket, ok := aggregate(segments(changes, from, to), from, to); ok {
+				bucket.Start, bucket.End = start, end
+				history.Buckets = append(history.Buckets, bucket)
+			}
+		}
+	}
+	return history
+}
+
+// LowestPrice returns the lowest price in effect at any time within [from, to].
+func LowestPrice(changes []PriceChange, from, to time.Time) (float64, bool) {
+	bucket, ok := aggregate(segments(changes, from, to), from, to)
+	return bucket.Min, ok
+}
+
+// segments turns ordered changes into the price spans overlapping [from, to].
+func segments(changes []PriceChange, from, to time.Time) []priceSegment {
+	var result []priceSegment
+	for i, change := range changes {
+		end := to
+		if i+1 < len(changes) {
+			end = minTime(changes[i+1].ChangedAt, to)
+		}
+		start := maxTime(change.ChangedAt, from)
+		// Spans are half-open, a change exactly at to only counts for an instant query
+		if !end.After(start) && !(from.Equal(to) && start.Equal(to)) {
+			continue
+		}
+		result = append(result, priceSegment{start: start, end: end, price: change.Price})
+	}
+	return result
+}
+
+func aggregate(segments []priceSegment, from, to time.Time) (PriceBucket, bool) {
+	if len(segments) == 0 {
+		return PriceBucket{}, false
+	}
+	bucket := PriceBucket{Start: from, End: to, Min: math.Inf(1), Max: math.Inf(-1)}
+	var weighted, total float64
+	for _, segment := range segments {
+		bucket.Min = math.Min(bucket.Min, segment.price)
+		bucket.Max = math.Max(bucket.Max, segment.price)
+		duration := segment.end.Sub(segment.start).Seconds()
+		weighted += segment.price * duration
+		total += duration
+		bucket.Close = segment.price
+	}
+	if total > 0 {
+		bucket.Avg = roundCents(weighted / total)
+	} else {
+		bucket.Avg = bucket.Close
+	}
+	return bucket, true
+}
+
+// bucketStart truncates t to the start of its UTC day or ISO week (Monday).
+func bucketStart(t time.Time, size PriceBucketSize) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if size == BucketWeek {
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	}
+	return day
+}
+
+func nextBucket(start time.Time, size PriceBucketSize) time.Time {
+	if size == BucketWeek {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceHistory(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2025, 6, d, h, 0, 0, 0, time.UTC) }
+	changes := []PriceChange{
+		{Price: 20, Reason: PriceReasonInitial, ChangedAt: day(1, 0)},
+		{Price: 10, Reason: "summer sale", ChangedAt: day(3, 12)},
+		{Price: 16, Reason: PriceReasonUpdate, ChangedAt: day(4, 0)},
+	}
+
+	t.Run("BuildPriceHistory :: aggregates are weighted by time in effect", func(t *testing.T) {
+		history := BuildPriceHistory(1, changes, PriceHistoryQuery{From: day(2, 0), To: day(5, 0)})
+		// 20 for 36h, 10 for 12h, 16 for 24h
+		assert.Equal(t, 10.0, *history.Min)
+		assert.Equal(t, 20.0, *history.Max)
+		assert.Equal(t, 17.0, *history.Avg)
+		// The change before the range only sets the opening price
+		assert.Len(t, history.Changes, 2)
+		assert.Nil(t, history.Buckets)
+	})
+
+	t.Run("BuildPriceHistory :: daily buckets carry the price over", func(t *testing.T) {
+		history := BuildPriceHistory(1, changes, PriceHistoryQuery{From: day(2, 0), To: day(5, 0), Bucket: BucketDay})
+		assert.Len(t, history.Buckets, 3)
+		assert.Equal(t, PriceBucket{Start: day(2, 0), End: day(3, 0), Min: 20, Max: 20, Avg: 20, Close: 20}, history.Buckets[0])
+		assert.Equal(t, PriceBucket{Start: day(3, 0), End: day(4, 0), Min: 10, Max: 20, Avg: 15, Close: 10}, history.Buckets[1])
+		assert.Equal(t, PriceBucket{Start: day(4, 0), End: day(5, 0), Min: 16, Max: 16, Avg: 16, Close: 16}, history.Buckets[2])
+	})
+
+	t.Run("BuildPriceHistory :: weekly buckets start on monday", func(t *testing.T) {
+		// 2025-06-01 is a sunday
+		history := BuildPriceHistory(1, changes, PriceHistoryQuery{From: day(1, 0), To: day(5, 0), Bucket: BucketWeek})
+		assert.Len(t, history.Buckets, 2)
+		assert.Equal(t, time.Date(2025, 5, 26, 0, 0, 0, 0, time.UTC), history.Buckets[0].Start)
+		assert.Equal(t, day(2, 0), history.Buckets[1].Start)
+		assert.Equal(t, 16.0, history.Buckets[1].Close)
+	})
+
+	t.Run("BuildPriceHistory :: lowest price of the last 30 days", func(t *testing.T) {
+		history := BuildPriceHistory(1, changes, PriceHistoryQuery{From: day(20, 0), To: day(30, 0)})
+		assert.Empty(t, history.Changes)
+		assert.Equal(t, 16.0, *history.Min)
+		assert.Equal(t, 10.0, *history.Lowest30Days)
+
+		history = BuildPriceHistory(1, changes, PriceHistoryQuery{From: day(1, 0).AddDate(0, 1, 10), To: day(1, 0).AddDate(0, 1, 20)})
+		assert.Equal(t, 16.0, *history.Lowest30Days)
+	})
+
+	t.Run("BuildPriceHistory :: no price before the album existed", func(t *testing.T) {
+		history := BuildPriceHistory(1, changes, PriceHistoryQuery{From: day(1, 0).AddDate(0, -3, 0), To: day(1, 0).AddDate(0, -2, 0)})
+		assert.Nil(t, history.Min)
+		assert.Nil(t, history.Lowest30Days)
+	})
+
+	t.Run("PriceHistoryQuery :: rejects reversed range and unknown bucket", func(t *testing.T) {
+		assert.ErrorIs(t, PriceHistoryQuery{From: day(2, 0), To: day(1, 0)}.Validate(), ErrInvalidPriceHistoryQuery)
+		assert.ErrorIs(t, PriceHistoryQuery{From: day(1, 0), To: day(2, 0), Bucket: "month"}.Validate(), ErrInvalidPriceHistoryQuery)
+	})
+}
@@ -0,0 +1,272 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidPromotion = errors.New("invalid promotion")
+
+type PromotionKind string
+
+const (
+	PromotionPercent  PromotionKind = "percent"
+	PromotionFixed    PromotionKind = "fixed"
+	PromotionBuyXGetY PromotionKind = "buy_x_get_y"
+)
+
+// What a promotion applies to, TargetRef holds the artist ID, album ID or genre name.
+type PromotionTarget string
+
+const (
+	TargetAll    PromotionTarget = "all"
+	TargetGenre  PromotionTarget = "genre"
+	TargetArtist PromotionTarget = "artist"
+	TargetAlbum  PromotionTarget = "album"
+)
+
+// More specific targets take precedence over broader ones at equal priority.
+var targetSpecificity = map[PromotionTarget]int{TargetAll: 0, TargetGenre: 1, TargetArtist: 2, TargetAlbum: 3}
+
+// Time-boxed discount rule.
+// Percent and fixed promotions use Value, buy-X-get-Y promotions use BuyQuantity and FreeQuantity.
+type Promotion struct {
+	ID           uint            `json:"id" gorm:"primaryKey"`
+	Name         string          `json:"name" binding:"required" gorm:"size:255"`
+	Kind         PromotionKind   `json:"kind" binding:"required" gorm:"size:16"`
+	Value        float64         `json:"value"`
+	BuyQuantity  int             `json:"buy_quantity,omitempty"`
+	FreeQuantity int             `json:"free_quantity,omitempty"`
+	Target       PromotionTarget `json:"target" binding:"required" gorm:"size:16"`
+	TargetRef    string          `json:"target_ref,omitempty" gorm:"size:255"`
+	CouponCode   string          `json:"coupon_code,omitempty" gorm:"size:64;index"`
+	Priority     int             `json:"priority"`
+	Stackable    bool            `json:"stackable"`
+	StartsAt     time.Time       `json:"starts_at" gorm:"index"`
+	EndsAt       time.Time       `json:"ends_at" gorm:"index"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// Redacted returns the promotion without its coupon code.
+func (p Promotion) Redacted() Promotion {
+	p.CouponCode = ""
+	return p
+}
+
+// Validate normalizes the promotion and checks its rule is well formed.
+func (p *Promotion) Validate() error {
+	p.Name = strings.TrimSpace(p.Name)
+	p.CouponCode = strings.ToUpper(strings.TrimSpace(p.CouponCode))
+	p.TargetRef = strings.TrimSpace(p.TargetRef)
+
+	switch p.Kind {
+	case PromotionPercent:
+		if p.Value <= 0 || p.Value > 100 {
+			return fmt.Errorf("%w: percent value must be within (0, 100]", ErrInvalidPromotion)
+		}
+	case PromotionFixed:
+		if p.Value <= 0 {
+			return fmt.Errorf("%w: fixed value must be positive", ErrInvalidPromotion)
+		}
+	case PromotionBuyXGetY:
+		if p.BuyQuantity < 1 || p.FreeQuantity < 1 {
+			return fmt.Errorf("%w: buy and free quantities must be at least 1", ErrInvalidPromotion)
+		}
+	default:
+		return fmt.Errorf("%w: kind must be percent, fixed or buy_x_get_y", ErrInvalidPromotion)
+	}
+
+	switch p.Target {
+	case TargetAll:
+		p.TargetRef = ""
+	case TargetGenre:
+		if p.TargetRef == "" {
+			return fmt.Errorf("%w: genre target needs a genre name", ErrInvalidPromotion)
+		}
+	case TargetArtist, TargetAlbum:
+		id, err := strconv.ParseUint(p.TargetRef, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: %s target needs a numeric ID", ErrInvalidPromotion, p.Target)
+		}
+		// Stored in the form AppliesTo compares against, so "007" targets ID 7
+		p.TargetRef = strconv.FormatUint(id, 10)
+	default:
+		return fmt.Errorf("%w: target must be all, genre, artist or album", ErrInvalidPromotion)
+	}
+
+	if p.StartsAt.IsZero() || p.EndsAt.IsZero() || !p.EndsAt.After(p.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidPromotion)
+	}
+	return nil
+}
+
+// ActiveAt reports whether now falls within [StartsAt, EndsAt).
+func (p Promotion) ActiveAt(now time.Time) bool {
+	return !now.Before(p.StartsAt) && now.Before(p.EndsAt)
+}
+
+// AppliesTo reports whether the promotion targets album.
+func (p Promotion) AppliesTo(album Album) bool {
+	switch p.Target {
+	case TargetAll:
+		return true
+	case TargetAlbum:
+		return p.TargetRef == strconv.FormatUint(uint64(album.ID), 10)
+	case TargetArtist:
+		return p.TargetRef == strconv.FormatUint(uint64(album.ArtistID), 10)
+	case TargetGenre:
+		for _, genre := range album.Genres {
+			if strings.EqualFold(genre.Name, p.TargetRef) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Pricing parameters for a quote, Quantity defaults to 1.
+type PriceRequest struct {
+	Quantity   int
+	CouponCode string
+	At         time.Time
+}
+
+// Discount applied to a quote, with a human readable explanation of why.
+type AppliedDiscount struct {
+	PromotionID uint    `json:"promotion_id" xml:"promotion_id"`
+	Name        string  `json:"name" xml:"name"`
+	Amount      float64 `json:"amount" xml:"amount"`
+	Explanation string  `json:"explanation" xml:"explanation"`
+}
+
+// Price of an album for a quantity once promotions are applied. Prices are per unit.
+type PriceQuote struct {
+	AlbumID        uint              `json:"album_id"`
+	Quantity       int               `json:"quantity"`
+	ListPrice      float64           `json:"list_price"`
+	EffectivePrice float64           `json:"effective_price"`
+	Total          float64           `json:"total"`
+	Discounts      []AppliedDiscount `json:"discounts"`
+}
+
+// SortPromotions orders promotions by precedence: higher priority first, then the more
+// specific target (album, artist, genre, all), then the older promotion (lower ID).
+func SortPromotions(promotions []Promotion) {
+	sort.SliceStable(promotions, func(i, j int) bool {
+		a, b := promotions[i], promotions[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if targetSpecificity[a.Target] != targetSpecificity[b.Target] {
+			return targetSpecificity[a.Target] > targetSpecificity[b.Target]
+		}
+		return a.ID < b.ID
+	})
+}
+
+// PriceAlbum applies promotions to album in precedence order. Each discount is computed on the
+// price left by the previous ones. A non-stackable promotion only applies when no discount
+// applied before it and stops any further discounts. Coupon promotions need a matching coupon.
+func PriceAlbum(album Album, promotions []Promotion, request PriceRequest) PriceQuote {
+	if request.Quantity <= 0 {
+		request.Quantity = 1
+	}
+	quote := PriceQuote{
+		AlbumID:   album.ID,
+		Quantity:  request.Quantity,
+		ListPrice: album.Price,
+		Discounts: []AppliedDiscount{},
+	}
+	coupon := strings.ToUpper(strings.TrimSpace(request.CouponCode))
+	quantity := float64(request.Quantity)
+	total := album.Price * quantity
+
+	sorted := append([]Promotion(nil), promotions...)
+	SortPromotions(sorted)
+	for _, promotion := range sorted {
+		if !promotion.ActiveAt(request.At) || !promotion.AppliesTo(album) {
+			continue
+		}
+		if promotion.CouponCode != "" && promotion.CouponCode != coupon {
+			continue
+		}
+		if !promotion.Stackable && len(quote.Discounts) > 0 {
+			continue
+		}
+		amount, explanation := promotion.discount(total, request.Quantity)
+		amount = math.Min(roundCents(amount), total)
+		if amount <= 0 {
+			continue
+		}
+		total -= amount
+		quote.Discounts = append(quote.Discounts, AppliedDiscount{
+			PromotionID: promotion.ID,
+			Name:        promotion.Name,
+			Amount:      amount,
+			Explanation: explanation,
+		})
+		if !promotion.Stackable {
+			break
+		}
+	}
+
+	quote.Total = roundCents(total)
+	quote.EffectivePrice = roundCents(total / quantity)
+	return quote
+}
+
+// discount computes the promotion discount on a line total of quantity units.
+func (p Promotion) discount(total float64, quantity int) (float64, string) {
+	scope := p.scopeDescription()
+	if p.CouponCode != "" {
+		scope += " with coupon " + p.CouponCode
+	}
+	switch p.Kind {
+	case PromotionPercent:
+		return total * p.Value / 100, fmt.Sprintf("%s: %g%% off %s", p.Name, p.Value, scope)
+	case PromotionFixed:
+		return p.Value * float64(quantity), fmt.Sprintf("%s: %.2f off each unit %s", p.Name, p.Value, scope)
+	case PromotionBuyXGetY:
+		free := quantity / (p.BuyQuantity + p.FreeQuantity) * p.FreeQuantity
+		unit := total / float64(quantity)
+		return unit * float64(free), fmt.Sprintf("%s: buy %d get %d free %s, %d free unit(s)", p.Name, p.BuyQuantity, p.FreeQuantity, scope, free)
+	}
+	return 0, ""
+}
+
+func (p Promotion) scopeDescription() string {
+	switch p.Target {
+	case TargetGenre:
+		return "on " + p.TargetRef + " albums"
+	case TargetArtist:
+		return "on albums by artist " + p.TargetRef
+	case TargetAlbum:
+		return "on album " + p.TargetRef
+	default:
+		return "on all albums"
+	}
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// Pricing service interface definition, quotes album prices with promotions applied.
+type PricingService interface {
+	QuoteAlbums(albums []Album, request PriceRequest) ([]PriceQuote, error)
+}
+
+// Promotion service interface definition.
+type PromotionService interface {
+	CreatePromotion(promotion Promotion) (Promotion, error)
+	DeletePromotion(id int) error
+	GetAllPromotions() ([]Promotion, error)
+	GetPromotionByID(id int) (Promotion, error)
+	UpdatePromotion(promotion Promotion) (Promotion, error)
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromotions(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	window := func(p Promotion) Promotion {
+		p.StartsAt, p.EndsAt = now.Add(-time.Hour), now.Add(time.Hour)
+		return p
+	}
+	album := Album{ID: 7, ArtistID: 3, Price: 20, Genres: []Genre{{Name: "Jazz"}}}
+
+	t.Run("PriceAlbum :: no promotions keeps list price", func(t *testing.T) {
+		quote := PriceAlbum(album, nil, PriceRequest{At: now})
+		assert.Equal(t, 20.0, quote.EffectivePrice)
+		assert.Empty(t, quote.Discounts)
+	})
+
+	t.Run("PriceAlbum :: stackable discounts apply in precedence order", func(t *testing.T) {
+		promotions := []Promotion{
+			window(Promotion{ID: 1, Name: "Jazz week", Kind: PromotionPercent, Value: 10, Target: TargetGenre, TargetRef: "jazz", Stackable: true}),
+			window(Promotion{ID: 2, Name: "Artist deal", Kind: PromotionFixed, Value: 2, Target: TargetArtist, TargetRef: "3", Stackable: true}),
+		}
+		quote := PriceAlbum(album, promotions, PriceRequest{At: now})
+		// Artist target is more specific, so 20 - 2 = 18, then 10% off = 16.20
+		assert.Equal(t, 16.2, quote.EffectivePrice)
+		assert.Equal(t, []uint{2, 1}, []uint{quote.Discounts[0].PromotionID, quote.Discounts[1].PromotionID})
+		assert.Equal(t, 1.8, quote.Discounts[1].Amount)
+		assert.Contains(t, quote.Discounts[1].Explanation, "10% off on jazz albums")
+	})
+
+	t.Run("PriceAlbum :: exclusive promotion wins by priority", func(t *testing.T) {
+		promotions := []Promotion{
+			window(Promotion{ID: 1, Name: "Sitewide", Kind: PromotionPercent, Value: 50, Target: TargetAll, Stackable: true}),
+			window(Promotion{ID: 2, Name: "Flash", Kind: PromotionPercent, Value: 25, Target: TargetAlbum, TargetRef: "7", Priority: 10}),
+		}
+		quote := PriceAlbum(album, promotions, PriceRequest{At: now})
+		assert.Equal(t, 15.0, quote.EffectivePrice)
+		assert.Len(t, quote.Discounts, 1)
+	})
+
+	t.Run("PriceAlbum :: coupon and time window", func(t *testing.T) {
+		promotions := []Promotion{
+			window(Promotion{ID: 1, Name: "Coupon", Kind: PromotionFixed, Value: 5, Target: TargetAll, CouponCode: "SAVE5"}),
+			{ID: 2, Name: "Expired", Kind: PromotionPercent, Value: 50, Target: TargetAll, StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour)},
+		}
+		assert.Equal(t, 20.0, PriceAlbum(album, promotions, PriceRequest{At: now}).EffectivePrice)
+		assert.Equal(t, 15.0, PriceAlbum(album, promotions, PriceRequest{At: now, CouponCode: "save5"}).EffectivePrice)
+	})
+
+	t.Run("PriceAlbum :: buy two get one free", func(t *testing.T) {
+		promotions := []Promotion{window(Promotion{ID: 1, Name: "3 for 2", Kind: PromotionBuyXGetY, BuyQuantity: 2, FreeQuantity: 1, Target: TargetAll})}
+		quote := PriceAlbum(album, promotions, PriceRequest{At: now, Quantity: 4})
+		assert.Equal(t, 60.0, quote.Total)
+		assert.Equal(t, 15.0, quote.EffectivePrice)
+		assert.Contains(t, quote.Discounts[0].Explanation, "1 free unit(s)")
+
+		quote = PriceAlbum(album, promotions, PriceRequest{At: now, Quantity: 1})
+		assert.Empty(t, quote.Discounts)
+	})
+
+	t.Run("Validate :: rejects malformed rules", func(t *testing.T) {
+		for _, promotion := range []Promotion{
+			window(Promotion{Name: "a", Kind: PromotionPercent, Value: 150, Target: TargetAll}),
+			window(Promotion{Name: "b", Kind: PromotionFixed, Value: 1, Target: TargetArtist, TargetRef: "abc"}),
+			window(Promotion{Name: "c", Kind: PromotionBuyXGetY, Target: TargetAll}),
+			{Name: "d", Kind: PromotionFixed, Value: 1, Target: TargetAll, StartsAt: now, EndsAt: now},
+		} {
+			assert.ErrorIs(t, promotion.Validate(), ErrInvalidPromotion, promotion.Name)
+		}
+	})
+
+	t.Run("Validate :: stores target IDs in canonical form", func(t *testing.T) {
+		promotion := window(Promotion{Name: "a", Kind: PromotionFixed, Value: 1, Target: TargetAlbum, TargetRef: " 007 "})
+		assert.Nil(t, promotion.Validate())
+		assert.Equal(t, "7", promotion.TargetRef)
+		assert.True(t, promotion.AppliesTo(album))
+	})
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidAlbum     = errors.New("invalid album")
+	ErrDuplicateBarcode = errors.New("barcode already used by another album of this label")
+)
+
+// Physical or digital release format.
+type AlbumFormat string
+
+const (
+	FormatCD      AlbumFormat = "cd"
+	FormatVinyl   AlbumFormat = "vinyl"
+	FormatDigital AlbumFormat = "digital"
+)
+
+func (f AlbumFormat) Valid() bool {
+	switch f {
+	case "", FormatCD, FormatVinyl, FormatDigital:
+		return true
+	}
+	return false
+}
+
+// NormalizeBarcode removes the spaces and hyphens barcodes are often printed with.
+func NormalizeBarcode(code string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code))
+}
+
+// ValidateBarcode checks an EAN-8, UPC-A (12 digits) or EAN-13 barcode, including its check digit.
+func ValidateBarcode(code string) error {
+	switch len(code) {
+	case 8, 12, 13:
+	default:
+		return fmt.Errorf("%w: barcode must have 8, 12 or 13 digits", ErrInvalidAlbum)
+	}
+	sum := 0
+	for i := len(code) - 1; i >= 0; i-- {
+		digit := int(code[i] - '0')
+		if digit < 0 || digit > 9 {
+			return fmt.Errorf("%w: barcode must contain digits only", ErrInvalidAlbum)
+		}
+		if i == len(code)-1 {
+			continue
+		}
+		// Weights alternate 3, 1, ... starting next to the check digit
+		if (len(code)-1-i)%2 == 1 {
+			sum += digit * 3
+		} else {
+			sum += digit
+		}
+	}
+	if check := (10 - sum%10) % 10; check != int(code[len(code)-1]-'0') {
+		return fmt.Errorf("%w: barcode check digit mismatch", ErrInvalidAlbum)
+	}
+	return nil
+}
+
+// Validate normalizes the release metadata of an album and checks it.
+func (a *Album) Validate() error {
+	a.Barcode = NormalizeBarcode(a.Barcode)
+	a.Format = AlbumFormat(strings.ToLower(string(a.Format)))
+	a.Label = strings.TrimSpace(a.Label)
+	a.CatalogNumber = strings.TrimSpace(a.CatalogNumber)
+
+	if !a.Format.Valid() {
+		return fmt.Errorf("%w: format must be one of cd, vinyl or digital", ErrInvalidAlbum)
+	}
+	if a.Barcode != "" {
+		return ValidateBarcode(a.Barcode)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseMetadata(t *testing.T) {
+	t.Run("ValidateBarcode :: accepts valid EAN-13, UPC-A and EAN-8", func(t *testing.T) {
+		for _, code := range []string{"4006381333931", "036000291452", "96385074"} {
+			assert.Nil(t, ValidateBarcode(code), code)
+		}
+	})
+
+	t.Run("ValidateBarcode :: rejects wrong check digit and length", func(t *testing.T) {
+		for _, code := range []string{"4006381333932", "036000291453", "12345", "40063813339A1"} {
+			assert.ErrorIs(t, ValidateBarcode(code), ErrInvalidAlbum, code)
+		}
+	})
+
+	t.Run("Validate :: normalizes barcode and format", func(t *testing.T) {
+		album := Album{Barcode: "4 006381 333931", Format: "Vinyl"}
+		assert.Nil(t, album.Validate())
+		assert.Equal(t, "4006381333931", album.Barcode)
+		assert.Equal(t, FormatVinyl, album.Format)
+	})
+
+	t.Run("Validate :: rejects unknown format", func(t *testing.T) {
+		album := Album{Format: "cassette"}
+		assert.ErrorIs(t, album.Validate(), ErrInvalidAlbum)
+	})
+
+	t.Run("Date :: JSON round trip", func(t *testing.T) {
+		album := Album{ReleaseDate: NewDate(1969, time.September, 26)}
+		data, err := json.Marshal(album)
+		assert.Nil(t, err)
+		assert.Contains(t, string(data), `"release_date":"1969-09-26"`)
+
+		var decoded Album
+		assert.Nil(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, album.ReleaseDate, decoded.ReleaseDate)
+	})
+
+	t.Run("Date :: XML round trip", func(t *testing.T) {
+		album := Album{ReleaseDate: NewDate(1969, time.September, 26), Tracks: []Track{{Position: 1, Title: "Come Together"}}}
+		data, err := xml.Marshal(album)
+		assert.Nil(t, err)
+		assert.Contains(t, string(data), `<release_date>1969-09-26</release_date>`)
+		assert.Contains(t, string(data), `<tracks><track><disc_number>0</disc_number><position>1</position>`)
+
+		var decoded Album
+		assert.Nil(t, xml.Unmarshal(data, &decoded))
+		assert.Equal(t, album.ReleaseDate, decoded.ReleaseDate)
+		assert.Equal(t, album.Tracks, decoded.Tracks)
+	})
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidReview   = errors.New("invalid review")
+	ErrDuplicateReview = errors.New("album already reviewed by this author")
+	ErrReviewToken     = errors.New("review token does not match")
+	ErrDuplicateFlag   = errors.New("review already flagged by this client")
+)
+
+const maxReviewLength = 5000
+
+// Album review, each author reviews an album at most once. The author email is never returned.
+type Review struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	AlbumID uint   `json:"album_id" gorm:"uniqueIndex:idx_reviews_album_author"`
+	Author  string `json:"-" gorm:"size:255;uniqueIndex:idx_reviews_album_author"`
+	Rating  int    `json:"rating"`
+	Text    string `json:"text" gorm:"type:text"`
+
+	// Only returned when the review is created, the author edits and deletes the review with it
+	Token     string `json:"token,omitempty" gorm:"-"`
+	TokenHash string `json:"-" gorm:"size:64"`
+
+	// Moderation, flagged by readers and hidden by editors
+	FlagCount int  `json:"flag_count"`
+	Hidden    bool `json:"hidden"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// One flag per client and review, so a single reader can not push a review into moderation.
+type ReviewFlag struct {
+	ID        uint   `gorm:"primaryKey"`
+	ReviewID  uint   `gorm:"uniqueIndex:idx_review_flags_review_client"`
+	Client    string `gorm:"size:64;uniqueIndex:idx_review_flags_review_client"`
+	CreatedAt time.Time
+}
+
+// SetToken gives the review the author token, only its hash is stored.
+func (r *Review) SetToken(token string) {
+	sum := sha256.Sum256([]byte(token))
+	r.Token, r.TokenHash = token, hex.EncodeToString(sum[:])
+}
+
+// AuthoredWith reports whether token is the author token of the review.
+func (r Review) AuthoredWith(token string) bool {
+	if token == "" || r.TokenHash == "" {
+		return false
+	}
+	sum := sha256.Sum256([]byte(token))
+	return subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(r.TokenHash)) == 1
+}
+
+// NormalizeReviewAuthor makes author emails comparable for the one review per author rule.
+func NormalizeReviewAuthor(author string) string {
+	return strings.ToLower(strings.TrimSpace(author))
+}
+
+func (r Review) Validate() error {
+	if _, err := mail.ParseAddress(r.Author); err != nil {
+		return fmt.Errorf("%w: author must be an email address", ErrInvalidReview)
+	}
+	if r.Rating < 1 || r.Rating > 5 {
+		return fmt.Errorf("%w: rating must be between 1 and 5", ErrInvalidReview)
+	}
+	if len(r.Text) > maxReviewLength {
+		return fmt.Errorf("%w: text is longer than %d characters", ErrInvalidReview, maxReviewLength)
+	}
+	return nil
+}
+
+// ratingContribution is what the review adds to its album rating aggregates, hidden reviews do not count.
+func (r Review) ratingContribution() (sum, count int) {
+	if r.Hidden {
+		return 0, 0
+	}
+	return r.Rating, 1
+}
+
+// UpdateRating adjusts the album aggregates incrementally for a review change, before is nil
+// for a new review and after is nil for a deleted one.
+func (a *Album) UpdateRating(before, after *Review) {
+	if before != nil {
+		sum, count := before.ratingContribution()
+		a.RatingSum -= sum
+		a.ReviewCount -= count
+	}
+	if after != nil {
+		sum, count := after.ratingContribution()
+		a.RatingSum += sum
+		a.ReviewCount += count
+	}
+	a.AverageRating = 0
+	if a.ReviewCount > 0 {
+		a.AverageRating = roundCents(float64(a.RatingSum) / float64(a.ReviewCount))
+	}
+}
+
+// Review service interface definition.
+type ReviewService interface {
+	// GetAlbumReviews lists reviews newest first, hidden ones only when includeHidden is set
+	GetAlbumReviews(albumID int, includeHidden bool) ([]Review, error)
+	GetReview(albumID int, id int) (Review, error)
+	CreateReview(albumID int, review Review) (Review, error)
+	UpdateReview(albumID int, review Review) (Review, error)
+	DeleteReview(albumID int, id int) error
+	// FlagReview counts one flag per client, identified by its address
+	FlagReview(albumID int, id int, client string) (Review, error)
+	ModerateReview(albumID int, id int, hidden bool) (Review, error)
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviews(t *testing.T) {
+	t.Run("Validate :: rating, author and text", func(t *testing.T) {
+		assert.NoError(t, Review{Author: "fan@example.com", Rating: 5}.Validate())
+		assert.ErrorIs(t, Review{Author: "fan@example.com", Rating: 0}.Validate(), ErrInvalidReview)
+		assert.ErrorIs(t, Review{Author: "fan@example.com", Rating: 6}.Validate(), ErrInvalidReview)
+		assert.ErrorIs(t, Review{Author: "fan", Rating: 3}.Validate(), ErrInvalidReview)
+		assert.Equal(t, "fan@example.com", NormalizeReviewAuthor(" Fan@Example.com "))
+	})
+
+	t.Run("AuthoredWith :: only the author token matches", func(t *testing.T) {
+		var review Review
+		assert.False(t, review.AuthoredWith(""))
+
+		review.SetToken("author-token")
+		assert.Len(t, review.TokenHash, 64)
+		assert.NotContains(t, review.TokenHash, "author-token")
+		assert.True(t, review.AuthoredWith("author-token"))
+		assert.False(t, review.AuthoredWith("other-token"))
+		assert.False(t, review.AuthoredWith(""))
+	})
+
+	t.Run("UpdateRating :: aggregates follow review changes", func(t *testing.T) {
+		var album Album
+		first := Review{Rating: 5}
+		second := Review{Rating: 2}
+		album.UpdateRating(nil, &first)
+		album.UpdateRating(nil, &second)
+		assert.Equal(t, 2, album.ReviewCount)
+		assert.Equal(t, 3.5, album.AverageRating)
+
+		edited := Review{Rating: 4}
+		album.UpdateRating(&second, &edited)
+		assert.Equal(t, 4.5, album.AverageRating)
+
+		hidden := Review{Rating: 5, Hidden: true}
+		album.UpdateRating(&first, &hidden)
+		assert.Equal(t, 1, album.ReviewCount)
+		assert.Equal(t, 4.0, album.AverageRating)
+
+		album.UpdateRating(&edited, nil)
+		assert.Equal(t, 0, album.ReviewCount)
+		assert.Equal(t, 0.0, album.AverageRating)
+	})
+}
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidSchedule    = errors.New("invalid scheduled change")
+	ErrScheduleNotPending = errors.New("scheduled change is not pending")
+)
+
+// What a scheduled change does to its album when due.
+type ScheduleAction string
+
+const (
+	SchedulePrice   ScheduleAction = "price"
+	SchedulePublish ScheduleAction = "publish"
+	// Unpublishing archives the album
+	ScheduleUnpublish ScheduleAction = "unpublish"
+)
+
+// Scheduled change lifecycle: pending -> applied | failed | cancelled.
+type ScheduleStatus string
+
+const (
+	SchedulePending   ScheduleStatus = "pending"
+	ScheduleApplied   ScheduleStatus = "applied"
+	ScheduleFailed    ScheduleStatus = "failed"
+	ScheduleCancelled ScheduleStatus = "cancelled"
+)
+
+// Album change applied by the scheduler once RunAt has passed.
+type ScheduledChange struct {
+	ID      uint           `json:"id" gorm:"primaryKey"`
+	AlbumID uint           `json:"album_id" gorm:"index"`
+	Action  ScheduleAction `json:"action" gorm:"size:16"`
+	// New price, only for price changes
+	Price       *float64       `json:"price,omitempty"`
+	Reason      string         `json:"reason,omitempty" gorm:"size:255"`
+	RunAt       time.Time      `json:"run_at" gorm:"index:idx_scheduled_changes_status_run_at,priority:2"`
+	Status      ScheduleStatus `json:"status" gorm:"size:16;index:idx_scheduled_changes_status_run_at,priority:1"`
+	Error       string         `json:"error,omitempty" gorm:"size:255"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	AppliedAt   *time.Time     `json:"applied_at,omitempty"`
+	CancelledAt *time.Time     `json:"cancelled_at,omitempty"`
+}
+
+// Criteria for listing scheduled changes, zero values match everything.
+type ScheduleFilter struct {
+	AlbumID uint
+	Status  ScheduleStatus
+}
+
+// Validate checks a new change, which must be due after now.
+func (c ScheduledChange) Validate(now time.Time) error {
+	switch c.Action {
+	case SchedulePrice:
+		if c.Price == nil || *c.Price < 0 {
+			return fmt.Errorf("%w: price change needs a non-negative price", ErrInvalidSchedule)
+		}
+	case SchedulePublish, ScheduleUnpublish:
+		if c.Price != nil {
+			return fmt.Errorf("%w: %s does not take a price", ErrInvalidSchedule, c.Action)
+		}
+	default:
+		return fmt.Errorf("%w: action must be price, publish or unpublish", ErrInvalidSchedule)
+	}
+	if !c.RunAt.After(now) {
+		return fmt.Errorf("%w: run_at must be in the future", ErrInvalidSchedule)
+	}
+	return nil
+}
+
+// ApplyTo makes a scheduled price change on album. Publish and unpublish are album
+// workflow transitions, see Album.Publish and Album.Archive.
+func (c ScheduledChange) ApplyTo(album *Album) {
+	if c.Action != SchedulePrice {
+		return
+	}
+	album.Price = *c.Price
+	album.PriceChangeReason = c.Reason
+	if album.PriceChangeReason == "" {
+		album.PriceChangeReason = fmt.Sprintf("scheduled change #%d", c.ID)
+	}
+}
+
+// Finish records the outcome of applying the change, err is nil on success.
+func (c *ScheduledChange) Finish(err error, now time.Time) error {
+	if c.Status != SchedulePending {
+		return ErrScheduleNotPending
+	}
+	c.Status, c.AppliedAt = ScheduleApplied, &now
+	if err != nil {
+		c.Status, c.Error = ScheduleFailed, truncate(err.Error(), 255)
+	}
+	return nil
+}
+
+func (c *ScheduledChange) Cancel(now time.Time) error {
+	if c.Status != SchedulePending {
+		return ErrScheduleNotPending
+	}
+	c.Status, c.CancelledAt = ScheduleCancelled, &now
+	return nil
+}
+
+// truncate cuts value to at most length bytes without splitting a UTF-8 encoded rune.
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+	for length > 0 && !utf8.RuneStart(value[length]) {
+		length--
+	}
+	return value[:length]
+}
+
+// Scheduled change service interface definition.
+type ScheduleService interface {
+	ScheduleChange(albumID int, change ScheduledChange) (ScheduledChange, error)
+	GetSchedules(filter ScheduleFilter) ([]ScheduledChange, error)
+	GetScheduleByID(id int) (ScheduledChange, error)
+	CancelSchedule(id int) (ScheduledChange, error)
+	// ApplyDueChanges applies pending changes due at now, returns how many were processed
+	ApplyDueChanges(now time.Time) (int, error)
+}
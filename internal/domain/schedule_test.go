@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduledChanges(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	price := 12.5
+
+	t.Run("Validate :: accepts future changes", func(t *testing.T) {
+		assert.NoError(t, ScheduledChange{Action: SchedulePrice, Price: &price, RunAt: now.Add(time.Hour)}.Validate(now))
+		assert.NoError(t, ScheduledChange{Action: SchedulePublish, RunAt: now.Add(time.Hour)}.Validate(now))
+	})
+
+	t.Run("Validate :: rejects invalid changes", func(t *testing.T) {
+		negative := -1.0
+		invalid := []ScheduledChange{
+			{Action: SchedulePrice, RunAt: now.Add(time.Hour)},
+			{Action: SchedulePrice, Price: &negative, RunAt: now.Add(time.Hour)},
+			{Action: ScheduleUnpublish, Price: &price, RunAt: now.Add(time.Hour)},
+			{Action: "delete", RunAt: now.Add(time.Hour)},
+			{Action: SchedulePublish, RunAt: now},
+		}
+		for _, change := range invalid {
+			assert.ErrorIs(t, change.Validate(now), ErrInvalidSchedule)
+		}
+	})
+
+	t.Run("ApplyTo :: changes price with a reason", func(t *testing.T) {
+		album := Album{Price: 20, Status: AlbumDraft}
+		ScheduledChange{ID: 4, Action: SchedulePrice, Price: &price}.ApplyTo(&album)
+		assert.Equal(t, 12.5, album.Price)
+		assert.Equal(t, "scheduled change #4", album.PriceChangeReason)
+
+		ScheduledChange{Action: SchedulePublish}.ApplyTo(&album)
+		assert.Equal(t, AlbumDraft, album.Status)
+	})
+
+	t.Run("Finish :: records success and failure once", func(t *testing.T) {
+		change := ScheduledChange{Status: SchedulePending}
+		assert.NoError(t, change.Finish(nil, now))
+		assert.Equal(t, ScheduleApplied, change.Status)
+		assert.ErrorIs(t, change.Finish(nil, now), ErrScheduleNotPending)
+
+		change = ScheduledChange{Status: SchedulePending}
+		assert.NoError(t, change.Finish(errors.New("album not found"), now))
+		assert.Equal(t, ScheduleFailed, change.Status)
+		assert.Equal(t, "album not found", change.Error)
+
+		change = ScheduledChange{Status: SchedulePending}
+		assert.NoError(t, change.Finish(errors.New("invalid album: titles "+strings.Repeat("é", 200)), now))
+		assert.LessOrEqual(t, len(change.Error), 255)
+		assert.True(t, utf8.ValidString(change.Error))
+	})
+
+	t.Run("Cancel :: only pending changes", func(t *testing.T) {
+		change := ScheduledChange{Status: SchedulePending}
+		assert.NoError(t, change.Cancel(now))
+		assert.Equal(t, ScheduleCancelled, change.Status)
+		assert.ErrorIs(t, change.Cancel(now), ErrScheduleNotPending)
+	})
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"strings"
+)
+
+// Tag entity, free-form lower case labels attached to albums.
+type Tag struct {
+	ID   uint   `json:"id" xml:"id" gorm:"primaryKey"`
+	Name string `json:"name" xml:"name" binding:"required" gorm:"size:100;uniqueIndex"`
+}
+
+// NormalizeTagName lower cases a tag and collapses its whitespace.
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// Tag service interface definition.
+type TagService interface {
+	CreateTag(tag Tag) (Tag, error)
+	DeleteTag(id int) error
+	GetAllTags() ([]Tag, error)
+	GetTagByID(id int) (Tag, error)
+	UpdateTag(tag Tag) (Tag, error)
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidTrack = errors.New("invalid track")
+
+// ISRC without hyphens: country code, registrant code, year of reference and designation code.
+var isrcPattern = regexp.MustCompile(`^[A-Z]{2}[A-Z0-9]{3}[0-9]{7}$`)
+
+// Track entity, owned by an Album. Duration is expressed in seconds.
+type Track struct {
+	ID         uint   `json:"-" xml:"-" gorm:"primaryKey"`
+	AlbumID    uint   `json:"-" xml:"-" gorm:"index"`
+	DiscNumber int    `json:"disc_number" xml:"disc_number"`
+	Position   int    `json:"position" xml:"position"`
+	Title      string `json:"title" xml:"title"`
+	Duration   int    `json:"duration" xml:"duration"`
+	ISRC       string `json:"isrc,omitempty" xml:"isrc,omitempty" gorm:"size:12"`
+}
+
+// NormalizeISRC strips the optional hyphens and upper cases the code.
+func NormalizeISRC(isrc string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(isrc), "-", ""))
+}
+
+// ValidateTracklist normalizes a full tracklist in place and checks it is consistent.
+// Tracks default to disc 1 and every disc/position pair must be unique.
+func ValidateTracklist(tracks []Track) error {
+	seen := map[[2]int]bool{}
+	for i := range tracks {
+		track := &tracks[i]
+		if track.DiscNumber == 0 {
+			track.DiscNumber = 1
+		}
+		track.ISRC = NormalizeISRC(track.ISRC)
+		track.Title = strings.TrimSpace(track.Title)
+
+		switch {
+		case track.DiscNumber < 0:
+			return fmt.Errorf("%w: track %d has a negative disc number", ErrInvalidTrack, i+1)
+		case track.Position <= 0:
+			return fmt.Errorf("%w: track %d must have a positive position", ErrInvalidTrack, i+1)
+		case track.Title == "":
+			return fmt.Errorf("%w: track %d has no title", ErrInvalidTrack, i+1)
+		case track.Duration < 0:
+			return fmt.Errorf("%w: track %d has a negative duration", ErrInvalidTrack, i+1)
+		case track.ISRC != "" && !isrcPattern.MatchString(track.ISRC):
+			return fmt.Errorf("%w: track %d has a malformed ISRC %q", ErrInvalidTrack, i+1, track.ISRC)
+		}
+
+		key := [2]int{track.DiscNumber, track.Position}
+		if seen[key] {
+			return fmt.Errorf("%w: duplicate position %d on disc %d", ErrInvalidTrack, track.Position, track.DiscNumber)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// RefreshTracklist orders tracks by disc and position and recomputes the track totals.
+func (a *Album) RefreshTracklist() {
+	sort.SliceStable(a.Tracks, func(i, j int) bool {
+		if a.Tracks[i].DiscNumber != a.Tracks[j].DiscNumber {
+			return a.Tracks[i].DiscNumber < a.Tracks[j].DiscNumber
+		}
+		return a.Tracks[i].Position < a.Tracks[j].Position
+	})
+	a.TrackCount = len(a.Tracks)
+	a.TotalDuration = 0
+	for _, track := range a.Tracks {
+		a.TotalDuration += track.Duration
+	}
+}
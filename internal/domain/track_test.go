@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracklist(t *testing.T) {
+	t.Run("ValidateTracklist :: normalizes valid tracks", func(t *testing.T) {
+		tracks := []Track{{Position: 1, Title: " Come Together ", Duration: 259, ISRC: "gb-aye-06-01690"}}
+		err := ValidateTracklist(tracks)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, tracks[0].DiscNumber)
+		assert.Equal(t, "Come Together", tracks[0].Title)
+		assert.Equal(t, "GBAYE0601690", tracks[0].ISRC)
+	})
+
+	t.Run("ValidateTracklist :: rejects duplicate positions on a disc", func(t *testing.T) {
+		tracks := []Track{{Position: 1, Title: "A"}, {DiscNumber: 1, Position: 1, Title: "B"}, {DiscNumber: 2, Position: 1, Title: "C"}}
+		assert.ErrorIs(t, ValidateTracklist(tracks), ErrInvalidTrack)
+	})
+
+	t.Run("ValidateTracklist :: rejects malformed ISRC", func(t *testing.T) {
+		tracks := []Track{{Position: 1, Title: "A", ISRC: "NOT-AN-ISRC"}}
+		assert.ErrorIs(t, ValidateTracklist(tracks), ErrInvalidTrack)
+	})
+
+	t.Run("RefreshTracklist :: orders tracks and computes totals", func(t *testing.T) {
+		album := Album{Tracks: []Track{
+			{DiscNumber: 2, Position: 1, Title: "C", Duration: 30},
+			{DiscNumber: 1, Position: 2, Title: "B", Duration: 20},
+			{DiscNumber: 1, Position: 1, Title: "A", Duration: 10},
+		}}
+		album.RefreshTracklist()
+		assert.Equal(t, []string{"A", "B", "C"}, []string{album.Tracks[0].Title, album.Tracks[1].Title, album.Tracks[2].Title})
+		assert.Equal(t, 3, album.TrackCount)
+		assert.Equal(t, 60, album.TotalDuration)
+	})
+}
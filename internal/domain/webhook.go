@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidWebhook = errors.New("invalid webhook")
+	// The delivery is not pending or another worker is sending it
+	ErrDeliveryNotDue = errors.New("webhook delivery is not due")
+	// Only dead deliveries are redelivered
+	ErrDeliveryNotDead = errors.New("webhook delivery is not dead")
+)
+
+const (
+	// Attempts made to deliver an event before it is given up as dead.
+	MaxWebhookAttempts = 8
+	// Wait before the first retry, doubled with every further attempt.
+	WebhookBackoff = 30 * time.Second
+	// Shortest secret accepted, secrets are generated when left out.
+	minWebhookSecret = 16
+	// Bytes of the last error kept with a delivery, the size of its column.
+	maxDeliveryError = 1024
+)
+
+// Event type of the deliveries sent by WebhookService.TestWebhook, pings are not retried.
+const WebhookPing = "ping"
+
+// HTTP callback told about album changes. The secret signs every delivery, it is only
+// returned when the webhook is created.
+type Webhook struct {
+	ID        uint             `json:"id" gorm:"primaryKey"`
+	URL       string           `json:"url" binding:"required" gorm:"size:2048"`
+	Events    []AlbumEventType `json:"events" binding:"required" gorm:"serializer:json;type:text"`
+	Secret    string           `json:"secret,omitempty" gorm:"size:255"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// Validate normalizes the webhook and checks it can be delivered to.
+func (w *Webhook) Validate() error {
+	w.URL = strings.TrimSpace(w.URL)
+	target, err := url.Parse(w.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	if len(w.Events) == 0 {
+		return fmt.Errorf("%w: events must list %s, %s or %s", ErrInvalidWebhook, AlbumCreated, AlbumUpdated, AlbumDeleted)
+	}
+	for _, event := range w.Events {
+		if event != AlbumCreated && event != AlbumUpdated && event != AlbumDeleted {
+			return fmt.Errorf("%w: unknown event %q, expected %s, %s or %s", ErrInvalidWebhook, event, AlbumCreated, AlbumUpdated, AlbumDeleted)
+		}
+	}
+	slices.Sort(w.Events)
+	w.Events = slices.Compact(w.Events)
+	if len(w.Secret) < minWebhookSecret {
+		return fmt.Errorf("%w: secret must have at least %d characters", ErrInvalidWebhook, minWebhookSecret)
+	}
+	return nil
+}
+
+func (w Webhook) Subscribes(eventType AlbumEventType) bool {
+	return slices.Contains(w.Events, eventType)
+}
+
+// Redacted returns the webhook without its secret.
+func (w Webhook) Redacted() Webhook {
+	w.Secret = ""
+	return w
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "pending"
+	DeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// Given up after MaxWebhookAttempts, kept until redelivered
+	DeliveryDead WebhookDeliveryStatus = "dead"
+)
+
+// Event sent to a webhook, with the outcome of the latest attempt. A pending delivery is sent
+// once NextAttemptAt passed, attempts that fail are retried with exponential backoff.
+type WebhookDelivery struct {
+	ID             uint                  `json:"id" gorm:"primaryKey"`
+	WebhookID      uint                  `json:"webhook_id" gorm:"index"`
+	EventID        string                `json:"event_id" gorm:"size:64"`
+	EventType      string                `json:"event_type" gorm:"size:16"`
+	Payload        string                `json:"payload" gorm:"type:text"`
+	Status         WebhookDeliveryStatus `json:"status" gorm:"size:16;index:idx_webhook_deliveries_due"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at,omitempty" gorm:"index:idx_webhook_deliveries_due"`
+	ResponseStatus int                   `json:"response_status,omitempty"`
+	Error          string                `json:"error,omitempty" gorm:"size:1024"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+}
+
+func (d WebhookDelivery) Due(now time.Time) bool {
+	return d.Status == DeliveryPending && d.NextAttemptAt != nil && !d.NextAttemptAt.After(now)
+}
+
+// Claim counts an attempt about to be made. The next attempt is pushed back by timeout, so a
+// delivery whose sender died is sent again rather than lost.
+func (d *WebhookDelivery) Claim(now time.Time, timeout time.Duration) error {
+	if !d.Due(now) {
+		return ErrDeliveryNotDue
+	}
+	d.Attempts++
+	next := now.Add(timeout)
+	d.NextAttemptAt = &next
+	return nil
+}
+
+// Record stores the outcome of the attempt, err being nil when the webhook answered with a
+// 2xx status. A failed delivery is retried after a backoff until it has been attempted
+// MaxWebhookAttempts times, then it is dead. Pings are not retried.
+func (d *WebhookDelivery) Record(status int, err error, now time.Time) {
+	d.ResponseStatus = status
+	if err == nil {
+		d.Status, d.Error, d.NextAttemptAt, d.DeliveredAt = DeliveryDelivered, "", nil, &now
+		return
+	}
+	d.Error = truncate(err.Error(), maxDeliveryError)
+	if d.Attempts >= MaxWebhookAttempts || d.EventType == WebhookPing {
+		d.Status, d.NextAttemptAt = DeliveryDead, nil
+		return
+	}
+	next := now.Add(WebhookBackoff << (d.Attempts - 1))
+	d.NextAttemptAt = &next
+}
+
+// Redeliver queues a dead delivery again, with a fresh count of MaxWebhookAttempts attempts.
+func (d *WebhookDelivery) Redeliver(now time.Time) error {
+	if d.Status != DeliveryDead {
+		return ErrDeliveryNotDead
+	}
+	d.Status, d.Attempts, d.NextAttemptAt = DeliveryPending, 0, &now
+	return nil
+}
+
+// Webhook sender interface definition (port).
+type WebhookSender interface {
+	// Send posts the payload of the delivery to the webhook signed with its secret. It returns
+	// the response status, and an error unless the status is 2xx.
+	Send(webhook Webhook, delivery WebhookDelivery) (int, error)
+}
+
+// Webhook service interface definition.
+type WebhookService interface {
+	CreateWebhook(webhook Webhook) (Webhook, error)
+	DeleteWebhook(id int) error
+	GetAllWebhooks() ([]Webhook, error)
+	GetWebhookByID(id int) (Webhook, error)
+	GetWebhookDeliveries(id int) ([]WebhookDelivery, error)
+	RedeliverWebhook(id int, deliveryID int) (WebhookDelivery, error)
+	// TestWebhook sends a ping right away and returns its outcome
+	TestWebhook(id int) (WebhookDelivery, error)
+	UpdateWebhook(webhook Webhook) (Webhook, error)
+}
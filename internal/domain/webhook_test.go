@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhooks(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	secret := "0123456789abcdef"
+
+	t.Run("Validate :: needs an http URL, known events and a long secret", func(t *testing.T) {
+		webhook := Webhook{URL: " https://example.com/hooks ", Events: []AlbumEventType{AlbumUpdated, AlbumCreated, AlbumUpdated}, Secret: secret}
+		assert.NoError(t, webhook.Validate())
+		assert.Equal(t, "https://example.com/hooks", webhook.URL)
+		assert.Equal(t, []AlbumEventType{AlbumCreated, AlbumUpdated}, webhook.Events)
+		assert.True(t, webhook.Subscribes(AlbumCreated))
+		assert.False(t, webhook.Subscribes(AlbumDeleted))
+
+		for _, invalid := range []Webhook{
+			{URL: "ftp://example.com", Events: []AlbumEventType{AlbumCreated}, Secret: secret},
+			{URL: "/hooks", Events: []AlbumEventType{AlbumCreated}, Secret: secret},
+			{URL: "https://example.com", Secret: secret},
+			{URL: "https://example.com", Events: []AlbumEventType{"published"}, Secret: secret},
+			{URL: "https://example.com", Events: []AlbumEventType{AlbumCreated}, Secret: "short"},
+		} {
+			assert.ErrorIs(t, invalid.Validate(), ErrInvalidWebhook)
+		}
+	})
+
+	t.Run("Record :: retries with exponential backoff until dead", func(t *testing.T) {
+		delivery := WebhookDelivery{Status: DeliveryPending, NextAttemptAt: &now}
+		assert.NoError(t, delivery.Claim(now, time.Minute))
+		assert.ErrorIs(t, delivery.Claim(now, time.Minute), ErrDeliveryNotDue)
+		delivery.Record(500, errors.New("webhook answered 500"), now)
+		assert.Equal(t, DeliveryPending, delivery.Status)
+		assert.Equal(t, now.Add(WebhookBackoff), *delivery.NextAttemptAt)
+
+		at := *delivery.NextAttemptAt
+		assert.NoError(t, delivery.Claim(at, time.Minute))
+		delivery.Record(0, errors.New("connection refused"), at)
+		assert.Equal(t, at.Add(2*WebhookBackoff), *delivery.NextAttemptAt)
+		assert.Equal(t, 0, delivery.ResponseStatus)
+
+		delivery.Attempts = MaxWebhookAttempts
+		delivery.Record(503, errors.New("webhook answered 503"), at)
+		assert.Equal(t, DeliveryDead, delivery.Status)
+		assert.Nil(t, delivery.NextAttemptAt)
+		assert.False(t, delivery.Due(at))
+
+		assert.NoError(t, delivery.Redeliver(at))
+		assert.Equal(t, 0, delivery.Attempts)
+		assert.True(t, delivery.Due(at))
+		assert.ErrorIs(t, delivery.Redeliver(at), ErrDeliveryNotDead)
+	})
+
+	t.Run("Record :: delivered and failed pings", func(t *testing.T) {
+		delivery := WebhookDelivery{Status: DeliveryPending, NextAttemptAt: &now, Attempts: 2}
+		delivery.Record(204, nil, now)
+		assert.Equal(t, DeliveryDelivered, delivery.Status)
+		assert.Equal(t, now, *delivery.DeliveredAt)
+		assert.Nil(t, delivery.NextAttemptAt)
+
+		ping := WebhookDelivery{Status: DeliveryPending, NextAttemptAt: &now, EventType: WebhookPing}
+		assert.NoError(t, ping.Claim(now, time.Minute))
+		ping.Record(404, errors.New("webhook answered 404"), now)
+		assert.Equal(t, DeliveryDead, ping.Status)
+	})
+
+	t.Run("Record :: truncates long errors on a rune boundary", func(t *testing.T) {
+		delivery := WebhookDelivery{Status: DeliveryPending, NextAttemptAt: &now, Attempts: 1}
+		delivery.Record(500, errors.New("webhook answered 500:"+strings.Repeat("ü", 1024)), now)
+		assert.LessOrEqual(t, len(delivery.Error), maxDeliveryError)
+		assert.Greater(t, len(delivery.Error), maxDeliveryError-utf8.UTFMax)
+		assert.True(t, utf8.ValidString(delivery.Error))
+	})
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidAlbumTransition = errors.New("invalid album status transition")
+
+// Editorial workflow: draft -> review -> published -> archived. Only published albums are public.
+type AlbumStatus string
+
+const (
+	AlbumDraft     AlbumStatus = "draft"
+	AlbumReview    AlbumStatus = "review"
+	AlbumPublished AlbumStatus = "published"
+	AlbumArchived  AlbumStatus = "archived"
+)
+
+// Statuses each transition may start from.
+var albumTransitions = map[AlbumStatus][]AlbumStatus{
+	AlbumReview: {AlbumDraft},
+	// Editors may publish without review and republish archived albums
+	AlbumPublished: {AlbumDraft, AlbumReview, AlbumArchived},
+	AlbumArchived:  {AlbumDraft, AlbumReview, AlbumPublished},
+}
+
+// Submit sends a draft for editorial review.
+func (a *Album) Submit() error {
+	return a.transition(AlbumReview)
+}
+
+// Approve publishes an album that is under review.
+func (a *Album) Approve(now time.Time) error {
+	if a.Status != AlbumReview {
+		return fmt.Errorf("%w: only albums under review can be approved, album is %s", ErrInvalidAlbumTransition, a.Status)
+	}
+	return a.Publish(now)
+}
+
+func (a *Album) Publish(now time.Time) error {
+	if err := a.transition(AlbumPublished); err != nil {
+		return err
+	}
+	a.PublishedAt = &now
+	return nil
+}
+
+func (a *Album) Archive() error {
+	return a.transition(AlbumArchived)
+}
+
+// IsPublic reports whether the album is visible outside the editorial team.
+func (a Album) IsPublic() bool {
+	return a.Status == AlbumPublished
+}
+
+func (a *Album) transition(to AlbumStatus) error {
+	for _, from := range albumTransitions[to] {
+		if a.Status == from {
+			a.Status = to
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s album cannot become %s", ErrInvalidAlbumTransition, a.Status, to)
+}
+
+// ParseAlbumStatus validates a status given by a client.
+func ParseAlbumStatus(value string) (AlbumStatus, error) {
+	switch status := AlbumStatus(value); status {
+	case AlbumDraft, AlbumReview, AlbumPublished, AlbumArchived:
+		return status, nil
+	}
+	return "", fmt.Errorf("%w: status must be draft, review, published or archived", ErrInvalidFilter)
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlbumWorkflow(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Workflow :: draft -> review -> published -> archived", func(t *testing.T) {
+		album := Album{Status: AlbumDraft}
+		assert.False(t, album.IsPublic())
+		assert.NoError(t, album.Submit())
+		assert.Equal(t, AlbumReview, album.Status)
+		assert.NoError(t, album.Approve(now))
+		assert.Equal(t, AlbumPublished, album.Status)
+		assert.Equal(t, now, *album.PublishedAt)
+		assert.True(t, album.IsPublic())
+		assert.NoError(t, album.Archive())
+		assert.Equal(t, AlbumArchived, album.Status)
+	})
+
+	t.Run("Workflow :: editors may publish drafts and republish archived albums", func(t *testing.T) {
+		album := Album{Status: AlbumDraft}
+		assert.NoError(t, album.Publish(now))
+		album.Status = AlbumArchived
+		assert.NoError(t, album.Publish(now))
+		assert.Equal(t, AlbumPublished, album.Status)
+	})
+
+	t.Run("Workflow :: rejects invalid transitions", func(t *testing.T) {
+		album := Album{Status: AlbumDraft}
+		assert.ErrorIs(t, album.Approve(now), ErrInvalidAlbumTransition)
+		album.Status = AlbumPublished
+		assert.ErrorIs(t, album.Submit(), ErrInvalidAlbumTransition)
+		assert.ErrorIs(t, album.Publish(now), ErrInvalidAlbumTransition)
+		album.Status = AlbumArchived
+		assert.ErrorIs(t, album.Archive(), ErrInvalidAlbumTransition)
+		assert.Equal(t, AlbumArchived, album.Status)
+	})
+
+	t.Run("ParseAlbumStatus :: known statuses only", func(t *testing.T) {
+		status, err := ParseAlbumStatus("review")
+		assert.NoError(t, err)
+		assert.Equal(t, AlbumReview, status)
+		_, err = ParseAlbumStatus("deleted")
+		assert.ErrorIs(t, err, ErrInvalidFilter)
+	})
+}
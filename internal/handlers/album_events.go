@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Heartbeat interval of event streams unless configured, proxies close connections idle for long.
+const defaultEventHeartbeat = 15 * time.Second
+
+// WithEvents enables the album event stream, sending a heartbeat comment every heartbeat.
+func WithEvents(source domain.AlbumEventSource, heartbeat time.Duration) AlbumHandlerOption {
+	return func(h *AlbumHandler) {
+		h.events = source
+		h.heartbeat = heartbeat
+	}
+}
+
+// Data of an album event. The album is left out of deleted events, and with the artist out of
+// events of withdrawn albums sent to anyone but editors.
+type albumEventData struct {
+	ID       string                `json:"id"`
+	Type     domain.AlbumEventType `json:"type"`
+	AlbumID  uint                  `json:"album_id"`
+	ArtistID uint                  `json:"artist_id,omitempty"`
+	At       time.Time             `json:"at"`
+	Album    *albumResponse        `json:"album,omitempty"`
+}
+
+// StreamAlbumEvents streams album changes as server-sent events, limited to the albums and
+// artists given by ?album_id= and ?artist_id=. A client reconnecting with Last-Event-ID first
+// receives the events it missed, or a reset event when they are no longer buffered. A client
+// too slow to keep up is disconnected and catches up the same way. Anyone but editors only
+// sees changes of published albums, albums withdrawn since only with their ID. Events are
+// process-local, the stream misses changes made through other replicas.
+func (h *AlbumHandler) StreamAlbumEvents(c *gin.Context) {
+	if h.events == nil {
+		abortWithError(c, http.StatusNotImplemented, errors.New("album events are not available"))
+		return
+	}
+	var filter domain.AlbumEventFilter
+	var err error
+	if filter.AlbumIDs, err = queryIDs(c, "album_id"); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if filter.ArtistIDs, err = queryIDs(c, "artist_id"); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	subscription := h.events.Subscribe(filter, lastEventID)
+	defer subscription.Close()
+	editor := isEditor(c)
+	heartbeat := h.heartbeat
+	if heartbeat <= 0 {
+		heartbeat = defaultEventHeartbeat
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	if subscription.Gap {
+		fmt.Fprint(c.Writer, "event: reset\ndata: {\"type\":\"reset\"}\n\n")
+	}
+	for _, event := range subscription.Replay {
+		if editor || event.Public() {
+			writeAlbumEvent(c, event, editor)
+		}
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ticker.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+		case event, ok := <-subscription.Events:
+			if !ok {
+				// Dropped for falling behind, the client reconnects and resumes
+				return
+			}
+			if !editor && !event.Public() {
+				continue
+			}
+			writeAlbumEvent(c, event, editor)
+		}
+		c.Writer.Flush()
+	}
+}
+
+func newAlbumEventData(event domain.AlbumEvent) albumEventData {
+	data := albumEventData{
+		ID:       event.ID,
+		Type:     event.Type,
+		AlbumID:  event.Album.ID,
+		ArtistID: event.Album.ArtistID,
+		At:       event.At,
+	}
+	if event.Type != domain.AlbumDeleted {
+		album := exportRow(event.Album)
+		data.Album = &album
+	}
+	return data
+}
+
+func writeAlbumEvent(c *gin.Context, event domain.AlbumEvent, editor bool) {
+	data := newAlbumEventData(event)
+	if !editor && event.Withdrawn() {
+		data = albumEventData{ID: event.ID, Type: event.Type, AlbumID: event.Album.ID, At: event.At}
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, encoded)
+}
+
+// queryIDs reads a list of IDs given like queryList.
+func queryIDs(c *gin.Context, key string) ([]uint, error) {
+	var ids []uint
+	for _, value := range queryList(c, key) {
+		id, err := strconv.ParseUint(value, 10, 0)
+		if err != nil || id == 0 {
+			return nil, fmt.Errorf("%s must be a list of positive integers", key)
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
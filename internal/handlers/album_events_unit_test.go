@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/services"
+	"github.com/ssitko/hex-domain/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// sseStream reads the events of a server-sent event stream.
+type sseStream struct {
+	body   io.ReadCloser
+	reader *bufio.Reader
+}
+
+// next returns the fields of the next block, a comment is returned under the empty name.
+func (s *sseStream) next(t *testing.T) map[string]string {
+	fields := map[string]string{}
+	for {
+		line, err := s.reader.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return fields
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return fields
+		}
+		name, value, _ := strings.Cut(line, ":")
+		fields[name] = strings.TrimPrefix(value, " ")
+	}
+}
+
+// nextEvent skips heartbeats.
+func (s *sseStream) nextEvent(t *testing.T) map[string]string {
+	for {
+		if fields := s.next(t); fields[""] == "" {
+			return fields
+		}
+	}
+}
+
+func TestStreamAlbumEvents(t *testing.T) {
+	broker := services.NewAlbumEventBroker(3, 2)
+	r := gin.New()
+	r.Use(Problems(logger.NewLogger()), IdentifyEditor("secret"), ValidateRequests(AlbumAPISpec(), true))
+	r.GET("/v1/albums/events", NewAlbumHandler(new(MockAlbumService), WithEvents(broker, 20*time.Millisecond)).StreamAlbumEvents)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	open := func(t *testing.T, query string, header http.Header) *sseStream {
+		req, _ := http.NewRequest("GET", server.URL+"/v1/albums/events"+query, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		res, err := http.DefaultClient.Do(req)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+		t.Cleanup(func() { res.Body.Close() })
+		return &sseStream{body: res.Body, reader: bufio.NewReader(res.Body)}
+	}
+	editor := http.Header{"Authorization": {"Bearer secret"}}
+	published := domain.Album{ID: 1, ArtistID: 7, Title: "Abbey Road", Status: domain.AlbumPublished}
+	draft := domain.Album{ID: 2, ArtistID: 8, Title: "Let It Be", Status: domain.AlbumDraft}
+
+	t.Run("GET :: /v1/albums/events endpoint streams album changes with heartbeats", func(t *testing.T) {
+		stream := open(t, "", editor)
+		assert.Equal(t, "heartbeat", stream.next(t)[""])
+
+		broker.Publish(domain.AlbumUpdated, published)
+		broker.Publish(domain.AlbumDeleted, draft)
+
+		event := stream.nextEvent(t)
+		assert.Equal(t, "updated", event["event"])
+		var data albumEventData
+		assert.Nil(t, json.Unmarshal([]byte(event["data"]), &data))
+		assert.Equal(t, event["id"], data.ID)
+		assert.Equal(t, uint(1), data.AlbumID)
+		assert.Equal(t, uint(7), data.ArtistID)
+		assert.Equal(t, "Abbey Road", data.Album.Title)
+
+		event = stream.nextEvent(t)
+		assert.Equal(t, "deleted", event["event"])
+		assert.NotContains(t, event["data"], `"album":`)
+	})
+
+	t.Run("GET :: /v1/albums/events endpoint filters by album and artist", func(t *testing.T) {
+		stream := open(t, "?album_id=3&artist_id=8", editor)
+
+		broker.Publish(domain.AlbumUpdated, published)
+		broker.Publish(domain.AlbumUpdated, domain.Album{ID: 3, ArtistID: 9, Status: domain.AlbumDraft})
+		broker.Publish(domain.AlbumUpdated, draft)
+
+		assert.Contains(t, stream.nextEvent(t)["data"], `"album_id":3`)
+		assert.Contains(t, stream.nextEvent(t)["data"], `"album_id":2`)
+	})
+
+	t.Run("GET :: /v1/albums/events endpoint keeps drafts from anyone but editors", func(t *testing.T) {
+		stream := open(t, "", nil)
+
+		broker.Publish(domain.AlbumCreated, draft)
+		broker.Publish(domain.AlbumCreated, published)
+
+		assert.Contains(t, stream.nextEvent(t)["data"], `"album_id":1`)
+	})
+
+	t.Run("GET :: /v1/albums/events endpoint withholds archived albums from anyone but editors", func(t *testing.T) {
+		stream := open(t, "?album_id=1", nil)
+		editorStream := open(t, "?album_id=1", editor)
+		publishedAt := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+		archived := published
+		archived.Status, archived.PublishedAt = domain.AlbumArchived, &publishedAt
+
+		broker.Publish(domain.AlbumUpdated, archived)
+
+		var data map[string]interface{}
+		assert.Nil(t, json.Unmarshal([]byte(stream.nextEvent(t)["data"]), &data))
+		assert.ElementsMatch(t, []string{"id", "type", "album_id", "at"}, slices.Collect(maps.Keys(data)))
+		assert.Equal(t, 1.0, data["album_id"])
+		assert.Contains(t, editorStream.nextEvent(t)["data"], `"title":"Abbey Road"`)
+	})
+
+	t.Run("GET :: /v1/albums/events endpoint replays events missed since Last-Event-ID", func(t *testing.T) {
+		stream := open(t, "", editor)
+		broker.Publish(domain.AlbumUpdated, published)
+		last := stream.nextEvent(t)["id"]
+		broker.Publish(domain.AlbumUpdated, draft)
+		broker.Publish(domain.AlbumDeleted, published)
+
+		stream = open(t, "", http.Header{"Authorization": {"Bearer secret"}, "Last-Event-ID": {last}})
+		assert.Contains(t, stream.nextEvent(t)["data"], `"album_id":2`)
+		assert.Equal(t, "deleted", stream.nextEvent(t)["event"])
+
+		stream = open(t, "?last_event_id="+last, nil)
+		assert.Equal(t, "deleted", stream.nextEvent(t)["event"])
+	})
+
+	t.Run("GET :: /v1/albums/events endpoint sends a reset for events no longer buffered", func(t *testing.T) {
+		stream := open(t, "", editor)
+		broker.Publish(domain.AlbumUpdated, published)
+		last := stream.nextEvent(t)["id"]
+		for i := 0; i < 4; i++ {
+			broker.Publish(domain.AlbumUpdated, draft)
+		}
+
+		stream = open(t, "", http.Header{"Authorization": {"Bearer secret"}, "Last-Event-ID": {last}})
+		assert.Equal(t, "reset", stream.nextEvent(t)["event"])
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, "updated", stream.nextEvent(t)["event"])
+		}
+
+		stream = open(t, "", http.Header{"Last-Event-ID": {"earlier-run-5"}})
+		assert.Equal(t, "reset", stream.nextEvent(t)["event"])
+	})
+
+	t.Run("GET :: /v1/albums/events endpoint rejects malformed IDs", func(t *testing.T) {
+		res, err := http.Get(server.URL + "/v1/albums/events?artist_id=abc")
+		assert.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("GET :: /v1/albums/events endpoint needs an event source", func(t *testing.T) {
+		r := gin.New()
+		r.Use(Problems(logger.NewLogger()))
+		r.GET("/v1/albums/events", NewAlbumHandler(new(MockAlbumService)).StreamAlbumEvents)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/v1/albums/events", nil)
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotImplemented, w.Code)
+	})
+
+	t.Run("Subscribe :: drops subscribers falling behind", func(t *testing.T) {
+		subscription := broker.Subscribe(domain.AlbumEventFilter{}, "")
+		for i := 0; i < 3; i++ {
+			broker.Publish(domain.AlbumUpdated, published)
+		}
+		var received int
+		for range subscription.Events {
+			received++
+		}
+		assert.Equal(t, 2, received)
+		subscription.Close()
+	})
+
+	t.Run("GET :: /v1/albums/events endpoint ends the stream on shutdown", func(t *testing.T) {
+		stream := open(t, "", editor)
+		broker.Close()
+		_, err := io.ReadAll(stream.body)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// AlbumFiles reads and writes the import and export formats of the album endpoints for the
+// background jobs, so a job file matches what the endpoint would accept or return.
+type AlbumFiles struct{}
+
+func (AlbumFiles) ReadImport(params domain.ImportParams, input io.Reader) ([]domain.ImportRow, error) {
+	return readImport(params, input)
+}
+
+// NewExportWriter writes the rows of ExportAlbums, CSV for the csv format and JSON lines otherwise.
+func (AlbumFiles) NewExportWriter(format string, w io.Writer, header bool) (domain.AlbumWriter, string) {
+	if format == exportCSV {
+		writer := csv.NewWriter(w)
+		if header {
+			writer.Write(albumCSVHeader)
+		}
+		return albumFileWriter{csv: writer}, "text/csv; charset=utf-8"
+	}
+	return albumFileWriter{json: json.NewEncoder(w)}, ndjsonContentType
+}
+
+// albumFileWriter writes export rows as CSV records or JSON lines.
+type albumFileWriter struct {
+	json *json.Encoder
+	csv  *csv.Writer
+}
+
+func (w albumFileWriter) Write(albums []domain.Album) error {
+	for _, album := range albums {
+		row := exportRow(album)
+		if w.csv != nil {
+			w.csv.Write(albumCSVRecord(row))
+			continue
+		}
+		if err := w.json.Encode(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w albumFileWriter) Flush() error {
+	if w.csv == nil {
+		return nil
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
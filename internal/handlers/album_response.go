@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Album as returned by the album GET endpoints. The optional fields are only
+// present when the album has a cover or currency conversion or pricing apply.
+type albumResponse struct {
+	domain.Album
+	CoverURL       string                   `json:"cover_url,omitempty" xml:"cover_url,omitempty"`
+	CoverSrcset    srcset                   `json:"cover_srcset,omitempty" xml:"cover_srcset,omitempty"`
+	Currency       string                   `json:"currency,omitempty" xml:"currency,omitempty"`
+	RateDate       string                   `json:"rate_date,omitempty" xml:"rate_date,omitempty"`
+	EffectivePrice *float64                 `json:"effective_price,omitempty" xml:"effective_price,omitempty"`
+	Discounts      []domain.AppliedDiscount `json:"discounts,omitempty" xml:"discounts>discount,omitempty"`
+}
+
+// presentAlbums applies pricing (?quantity=, ?coupon=) and currency conversion (?currency=)
+// to albums, writing an error response and returning false on failure.
+func (h *AlbumHandler) presentAlbums(c *gin.Context, albums []domain.Album) ([]albumResponse, bool) {
+	response := make([]albumResponse, 0, len(albums))
+	for _, album := range albums {
+		response = append(response, albumResponse{Album: album, CoverURL: coverURL(album), CoverSrcset: coverSrcset(album)})
+	}
+
+	if h.pricing != nil {
+		request := domain.PriceRequest{Quantity: 1, CouponCode: c.Query("coupon")}
+		if quantity := c.Query("quantity"); quantity != "" {
+			var err error
+			if request.Quantity, err = strconv.Atoi(quantity); err != nil || request.Quantity < 1 {
+				abortWithError(c, http.StatusBadRequest, errors.New("quantity must be a positive integer"))
+				return nil, false
+			}
+		}
+		quotes, err := h.pricing.QuoteAlbums(albums, request)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return nil, false
+		}
+		for i := range response {
+			effectivePrice := quotes[i].EffectivePrice
+			response[i].EffectivePrice = &effectivePrice
+			response[i].Discounts = quotes[i].Discounts
+		}
+	}
+
+	if currency := c.Query("currency"); currency != "" {
+		rate, ok := h.exchangeRate(c, currency)
+		if !ok {
+			return nil, false
+		}
+		for i := range response {
+			response[i].convert(rate)
+		}
+	}
+	return response, true
+}
+
+// exchangeRate resolves the rate from the base currency, writing an error response on failure.
+func (h *AlbumHandler) exchangeRate(c *gin.Context, currency string) (domain.ExchangeRate, bool) {
+	if h.rates == nil {
+		abortWithError(c, http.StatusBadRequest, errors.New("currency conversion is not available"))
+		return domain.ExchangeRate{}, false
+	}
+	rate, err := h.rates.Rate(domain.BaseCurrency, currency)
+	if errors.Is(err, domain.ErrUnsupportedCurrency) {
+		abortWithError(c, http.StatusBadRequest, err)
+		return domain.ExchangeRate{}, false
+	}
+	if err != nil {
+		abortWithError(c, http.StatusServiceUnavailable, err)
+		return domain.ExchangeRate{}, false
+	}
+	return rate, true
+}
+
+// convert expresses every amount of the response in the rate's target currency.
+func (r *albumResponse) convert(rate domain.ExchangeRate) {
+	r.Price = rate.Convert(r.Price)
+	if r.EffectivePrice != nil {
+		effectivePrice := rate.Convert(*r.EffectivePrice)
+		r.EffectivePrice = &effectivePrice
+	}
+	discounts := make([]domain.AppliedDiscount, 0, len(r.Discounts))
+	for _, discount := range r.Discounts {
+		discount.Amount = rate.Convert(discount.Amount)
+		discounts = append(discounts, discount)
+	}
+	if len(discounts) > 0 {
+		r.Discounts = discounts
+	}
+	r.Currency = rate.To
+	r.RateDate = rate.Date.Format(time.DateOnly)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Handles artist HTTP requests.
+type ArtistHandler struct {
+	service domain.ArtistService
+}
+
+func NewArtistHandler(service domain.ArtistService) *ArtistHandler {
+	return &ArtistHandler{service: service}
+}
+
+func (h *ArtistHandler) GetArtists(c *gin.Context) {
+	artists, err := h.service.GetAllArtists()
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, artists)
+}
+
+func (h *ArtistHandler) GetArtistByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	artist, err := h.service.GetArtistByID(id)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, artist)
+}
+
+func (h *ArtistHandler) GetArtistAlbums(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	albums, err := h.service.GetAlbumsByArtist(id)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, visibleAlbums(c, albums))
+}
+
+func (h *ArtistHandler) CreateArtist(c *gin.Context) {
+	var artist domain.Artist
+	if err := c.ShouldBindJSON(&artist); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	createdArtist, err := h.service.CreateArtist(artist)
+	if errors.Is(err, domain.ErrArtistExists) {
+		abortWithError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, createdArtist)
+}
+
+func (h *ArtistHandler) UpdateArtist(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	var artist domain.Artist
+	if err := c.ShouldBindJSON(&artist); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	artist.ID = uint(id)
+	updatedArtist, err := h.service.UpdateArtist(artist)
+	if errors.Is(err, domain.ErrArtistExists) {
+		abortWithError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, updatedArtist)
+}
+
+func (h *ArtistHandler) DeleteArtist(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	err = h.service.DeleteArtist(id)
+	if errors.Is(err, domain.ErrArtistHasAlbums) {
+		abortWithError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockArtistService is a mock implementation of the ArtistService interface (contained in the Artist domain)
+type MockArtistService struct {
+	mock.Mock
+}
+
+func (m *MockArtistService) GetAllArtists() ([]domain.Artist, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Artist), args.Error(1)
+}
+
+func (m *MockArtistService) GetArtistByID(id int) (domain.Artist, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Artist), args.Error(1)
+}
+
+func (m *MockArtistService) GetAlbumsByArtist(id int) ([]domain.Album, error) {
+	args := m.Called(id)
+	return args.Get(0).([]domain.Album), args.Error(1)
+}
+
+func (m *MockArtistService) CreateArtist(artist domain.Artist) (domain.Artist, error) {
+	args := m.Called(artist)
+	return args.Get(0).(domain.Artist), args.Error(1)
+}
+
+func (m *MockArtistService) UpdateArtist(artist domain.Artist) (domain.Artist, error) {
+	args := m.Called(artist)
+	return args.Get(0).(domain.Artist), args.Error(1)
+}
+
+func (m *MockArtistService) DeleteArtist(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func setupArtistTestRouter(service *MockArtistService) *gin.Engine {
+	r := gin.Default()
+	r.Use(Problems(logger.NewLogger()))
+	handler := NewArtistHandler(service)
+	r.GET("/artists", handler.GetArtists)
+	r.GET("/artists/:id", handler.GetArtistByID)
+	r.GET("/artists/:id/albums", handler.GetArtistAlbums)
+	r.POST("/artists", handler.CreateArtist)
+	r.PUT("/artists/:id", handler.UpdateArtist)
+	r.DELETE("/artists/:id", handler.DeleteArtist)
+	return r
+}
+
+func TestArtistHandlers(t *testing.T) {
+	mockService := new(MockArtistService)
+	r := setupArtistTestRouter(mockService)
+
+	t.Run("GET :: /artists/:id/albums endpoint", func(t *testing.T) {
+		albums := []domain.Album{{ID: 1, Title: "Abbey Road", ArtistID: 1, Price: 9.99, Status: domain.AlbumPublished}}
+		mockService.On("GetAlbumsByArtist", 1).Return(albums, nil)
+
+		req, _ := http.NewRequest("GET", "/artists/1/albums", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var responseAlbums []domain.Album
+		err := json.Unmarshal(w.Body.Bytes(), &responseAlbums)
+		assert.Nil(t, err)
+		assert.Equal(t, albums, responseAlbums)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("POST :: /artists endpoint", func(t *testing.T) {
+		artist := domain.Artist{Name: "The Beatles"}
+		mockService.On("CreateArtist", artist).Return(domain.Artist{ID: 1, Name: "The Beatles"}, nil)
+
+		jsonValue, _ := json.Marshal(artist)
+		req, _ := http.NewRequest("POST", "/artists", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("POST :: /artists endpoint with duplicate name", func(t *testing.T) {
+		artist := domain.Artist{Name: "Beatles"}
+		mockService.On("CreateArtist", artist).Return(domain.Artist{}, domain.ErrArtistExists)
+
+		jsonValue, _ := json.Marshal(artist)
+		req, _ := http.NewRequest("POST", "/artists", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("PUT :: /artists/:id endpoint", func(t *testing.T) {
+		artist := domain.Artist{ID: 1, Name: "The Beatles"}
+		mockService.On("UpdateArtist", artist).Return(artist, nil)
+
+		req, _ := http.NewRequest("PUT", "/artists/1", bytes.NewBufferString(`{"name":"The Beatles"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("DELETE :: /artists/:id endpoint with albums", func(t *testing.T) {
+		mockService.On("DeleteArtist", 2).Return(domain.ErrArtistHasAlbums)
+
+		req, _ := http.NewRequest("DELETE", "/artists/2", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
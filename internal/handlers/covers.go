@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Multipart overhead allowed on top of the cover size limit.
+const multipartOverhead = 1 << 20
+
+// UploadCover stores the image from the multipart "file" field as the album cover.
+func (h *AlbumHandler) UploadCover(c *gin.Context) {
+	if h.covers == nil {
+		abortWithError(c, http.StatusNotImplemented, errors.New("cover art is not available"))
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, domain.MaxCoverSize+multipartOverhead)
+	header, err := c.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		abortWithError(c, http.StatusRequestEntityTooLarge, domain.ErrCoverTooLarge)
+		return
+	}
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, errors.New("multipart field file is required"))
+		return
+	}
+	if header.Size > domain.MaxCoverSize {
+		abortWithError(c, http.StatusRequestEntityTooLarge, domain.ErrCoverTooLarge)
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(io.LimitReader(file, domain.MaxCoverSize+1))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	album, err := h.covers.UploadCover(id, content)
+	if err != nil {
+		abortWithError(c, coverErrorStatus(err), err)
+		return
+	}
+	response, ok := h.presentAlbums(c, []domain.Album{album})
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, response[0])
+}
+
+// GetCover serves the album cover, or with ?size= one of its thumbnails as JPEG or PNG
+// (?format=, the cover's own format by default). Until the thumbnails are generated the
+// original image is served instead. Versioned URLs, as given by cover_url and cover_srcset,
+// are cacheable for a day, others are revalidated through the entity tag.
+func (h *AlbumHandler) GetCover(c *gin.Context) {
+	if h.covers == nil {
+		abortWithError(c, http.StatusNotImplemented, errors.New("cover art is not available"))
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	size, contentType, err := thumbnailQuery(c)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	album, err := h.service.GetAlbumByID(id)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if len(visibleAlbums(c, []domain.Album{album})) == 0 {
+		abortWithError(c, http.StatusNotFound, errAlbumNotFound)
+		return
+	}
+	cover := album.Cover
+	if !cover.Exists() {
+		abortWithError(c, http.StatusNotFound, domain.ErrNoCover)
+		return
+	}
+
+	etag, length := `"`+cover.Checksum+`"`, cover.Size
+	if size == 0 || !cover.ThumbnailsReady() {
+		size, contentType = 0, cover.ContentType
+	} else {
+		if contentType == "" {
+			contentType = cover.DefaultThumbnailType()
+		}
+		etag, length = fmt.Sprintf(`"%s-%d-%s"`, cover.Checksum, size, strings.TrimPrefix(contentType, "image/")), -1
+	}
+	c.Header("ETag", etag)
+	if cover.UpdatedAt != nil {
+		c.Header("Last-Modified", cover.UpdatedAt.UTC().Format(http.TimeFormat))
+	}
+	// A fallback to the original must not be cached in place of the thumbnail
+	if c.Query("v") == coverVersion(cover) && (size != 0 || c.Query("size") == "") {
+		c.Header("Cache-Control", "public, max-age=86400")
+	} else {
+		c.Header("Cache-Control", "public, no-cache")
+	}
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	content, err := h.covers.OpenCover(album, size, contentType)
+	if err != nil {
+		abortWithError(c, coverErrorStatus(err), err)
+		return
+	}
+	defer content.Close()
+	c.DataFromReader(http.StatusOK, length, contentType, content, nil)
+}
+
+// thumbnailQuery parses ?size= and ?format=, size is zero when the original is requested.
+func thumbnailQuery(c *gin.Context) (int, string, error) {
+	var contentType string
+	if format := c.Query("format"); format != "" {
+		contentType = "image/" + format
+	}
+	sizeParam := c.Query("size")
+	if sizeParam == "" {
+		if contentType != "" {
+			return 0, "", errors.New("format requires size")
+		}
+		return 0, "", nil
+	}
+	size, err := strconv.Atoi(sizeParam)
+	if err != nil {
+		return 0, "", fmt.Errorf("size must be one of %v", domain.CoverSizes)
+	}
+	validated := contentType
+	if validated == "" {
+		validated = domain.ThumbnailTypes[0]
+	}
+	if err := domain.ValidateThumbnail(size, validated); err != nil {
+		return 0, "", err
+	}
+	return size, contentType, nil
+}
+
+// coverURL links the current cover version, empty when the album has no cover.
+func coverURL(album domain.Album) string {
+	if !album.Cover.Exists() {
+		return ""
+	}
+	return fmt.Sprintf("/v1/albums/%d/cover?v=%s", album.ID, coverVersion(album.Cover))
+}
+
+// coverSrcset links every thumbnail width in the cover's default thumbnail format, nil until
+// the thumbnails are generated.
+func coverSrcset(album domain.Album) map[string]string {
+	if !album.Cover.ThumbnailsReady() {
+		return nil
+	}
+	srcset := make(map[string]string, len(domain.CoverSizes))
+	for _, size := range domain.CoverSizes {
+		srcset[fmt.Sprintf("%dw", size)] = fmt.Sprintf("/v1/albums/%d/cover?size=%d&v=%s", album.ID, size, coverVersion(album.Cover))
+	}
+	return srcset
+}
+
+func coverVersion(cover domain.CoverImage) string {
+	return cover.Checksum[:12]
+}
+
+func coverErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrCoverTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, domain.ErrInvalidCover):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, domain.ErrNoCover), errors.Is(err, domain.ErrBlobNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCoverService is a mock implementation of the CoverService interface (contained in the Cover domain)
+type MockCoverService struct {
+	mock.Mock
+}
+
+func (m *MockCoverService) UploadCover(albumID int, content []byte) (domain.Album, error) {
+	args := m.Called(albumID, content)
+	return args.Get(0).(domain.Album), args.Error(1)
+}
+
+func (m *MockCoverService) OpenCover(album domain.Album, size int, contentType string) (io.ReadCloser, error) {
+	args := m.Called(album, size, contentType)
+	content, _ := args.Get(0).(io.ReadCloser)
+	return content, args.Error(1)
+}
+
+func multipartCover(t *testing.T, content []byte) (*bytes.Buffer, string) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "cover.png")
+	assert.Nil(t, err)
+	part.Write(content)
+	writer.Close()
+	return &body, writer.FormDataContentType()
+}
+
+func TestCovers(t *testing.T) {
+	mockService := new(MockAlbumService)
+	mockCovers := new(MockCoverService)
+	r := gin.Default()
+	r.Use(Problems(logger.NewLogger()))
+	handler := NewAlbumHandler(mockService, WithCovers(mockCovers))
+	r.GET("/albums/:id/cover", handler.GetCover)
+	r.PUT("/albums/:id/cover", handler.UploadCover)
+
+	updatedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cover := domain.CoverImage{Key: "covers/1/abc.png", ContentType: "image/png", Size: 5, Checksum: "0123456789abcdef", UpdatedAt: &updatedAt}
+	album := domain.Album{ID: 1, Title: "Test Album", Status: domain.AlbumPublished, Cover: cover}
+
+	t.Run("PUT :: /albums/:id/cover endpoint", func(t *testing.T) {
+		mockCovers.On("UploadCover", 1, []byte("image")).Return(album, nil)
+		body, contentType := multipartCover(t, []byte("image"))
+
+		req, _ := http.NewRequest("PUT", "/albums/1/cover", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response albumResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Nil(t, err)
+		assert.Equal(t, "/v1/albums/1/cover?v=0123456789ab", response.CoverURL)
+		mockCovers.AssertExpectations(t)
+	})
+
+	t.Run("PUT :: /albums/:id/cover endpoint rejects other file types", func(t *testing.T) {
+		mockCovers.On("UploadCover", 2, []byte("text")).Return(domain.Album{}, domain.ErrInvalidCover)
+		body, contentType := multipartCover(t, []byte("text"))
+
+		req, _ := http.NewRequest("PUT", "/albums/2/cover", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+		req, _ = http.NewRequest("PUT", "/albums/2/cover", strings.NewReader("image"))
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("GET :: /albums/:id/cover endpoint", func(t *testing.T) {
+		mockService.On("GetAlbumByID", 1).Return(album, nil)
+		mockCovers.On("OpenCover", album, 0, "image/png").Return(io.NopCloser(strings.NewReader("image")), nil)
+
+		req, _ := http.NewRequest("GET", "/albums/1/cover?v=0123456789ab", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image", w.Body.String())
+		assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		assert.Equal(t, `"0123456789abcdef"`, w.Header().Get("ETag"))
+		assert.Equal(t, "public, max-age=86400", w.Header().Get("Cache-Control"))
+		assert.Equal(t, "Sun, 01 Jun 2025 12:00:00 GMT", w.Header().Get("Last-Modified"))
+	})
+
+	t.Run("GET :: /albums/:id/cover endpoint revalidates by entity tag", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/albums/1/cover", nil)
+		req.Header.Set("If-None-Match", `"0123456789abcdef"`)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Equal(t, "public, no-cache", w.Header().Get("Cache-Control"))
+		mockCovers.AssertNumberOfCalls(t, "OpenCover", 1)
+	})
+
+	t.Run("GET :: /albums/:id/cover endpoint serves the original until thumbnails exist", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/albums/1/cover?size=256&v=0123456789ab", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		assert.Equal(t, "public, no-cache", w.Header().Get("Cache-Control"))
+
+		req, _ = http.NewRequest("GET", "/albums/1/cover?size=100", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("GET :: /albums/:id/cover endpoint serves thumbnails", func(t *testing.T) {
+		withThumbnails := album
+		withThumbnails.ID = 3
+		withThumbnails.Cover.ThumbnailChecksum = cover.Checksum
+		mockService.On("GetAlbumByID", 3).Return(withThumbnails, nil)
+		mockCovers.On("OpenCover", withThumbnails, 256, "image/png").Return(io.NopCloser(strings.NewReader("png thumbnail")), nil)
+		mockCovers.On("OpenCover", withThumbnails, 64, "image/jpeg").Return(io.NopCloser(strings.NewReader("jpeg thumbnail")), nil)
+
+		req, _ := http.NewRequest("GET", "/albums/3/cover?size=256&v=0123456789ab", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "png thumbnail", w.Body.String())
+		assert.Equal(t, `"0123456789abcdef-256-png"`, w.Header().Get("ETag"))
+		assert.Equal(t, "public, max-age=86400", w.Header().Get("Cache-Control"))
+
+		req, _ = http.NewRequest("GET", "/albums/3/cover?size=64&format=jpeg", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/jpeg", w.Header().Get("Content-Type"))
+		assert.Equal(t, "jpeg thumbnail", w.Body.String())
+
+		srcset := coverSrcset(withThumbnails)
+		assert.Equal(t, "/v1/albums/3/cover?size=1024&v=0123456789ab", srcset["1024w"])
+		assert.Len(t, srcset, 3)
+		assert.Nil(t, coverSrcset(album))
+	})
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/openapi"
+	swaggerfiles "github.com/swaggo/files/v2"
+)
+
+// Swagger UI setup pointing at the served document, replaces the bundled petstore example.
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "/openapi.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// Serves the OpenAPI document and the API explorer. The Swagger UI assets are embedded in
+// the binary, the explorer works without internet access.
+type DocsHandler struct {
+	spec   []byte
+	assets http.Handler
+}
+
+func NewDocsHandler(spec *openapi.Document) (*DocsHandler, error) {
+	encoded, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &DocsHandler{spec: encoded, assets: http.StripPrefix("/docs", http.FileServer(http.FS(swaggerfiles.FS)))}, nil
+}
+
+func (h *DocsHandler) GetSpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", h.spec)
+}
+
+// GetUI serves the Swagger UI files below /docs/.
+func (h *DocsHandler) GetUI(c *gin.Context) {
+	if c.Param("filepath") == "/swagger-initializer.js" {
+		c.Data(http.StatusOK, "text/javascript; charset=utf-8", []byte(swaggerInitializer))
+		return
+	}
+	h.assets.ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Context key set for requests authenticated as an editor.
+const editorKey = "editor"
+
+// Prefix of the WebSocket subprotocol carrying the editor token, see IdentifyEditor.
+const tokenSubprotocol = "bearer."
+
+// IdentifyEditor marks requests carrying "Authorization: Bearer <token>" as coming from an editor.
+// Editors see albums in every workflow status, an empty token disables editor access. Browsers
+// can not set headers on WebSocket handshakes, those may offer the subprotocol "bearer.<token>".
+func IdentifyEditor(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bearer, ok := bearerToken(c)
+		if ok && token != "" && subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1 {
+			c.Set(editorKey, true)
+		}
+		c.Next()
+	}
+}
+
+// RequireEditor rejects requests not identified as an editor by IdentifyEditor.
+func RequireEditor(c *gin.Context) {
+	if isEditor(c) {
+		c.Next()
+		return
+	}
+	if c.GetHeader("Authorization") == "" {
+		c.Header("WWW-Authenticate", "Bearer")
+		abortWithError(c, http.StatusUnauthorized, errors.New("editor token required"))
+		return
+	}
+	abortWithError(c, http.StatusForbidden, errors.New("editor access required"))
+}
+
+func bearerToken(c *gin.Context) (string, bool) {
+	if bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		return bearer, true
+	}
+	for _, protocol := range strings.Split(c.GetHeader("Sec-WebSocket-Protocol"), ",") {
+		if bearer, ok := strings.CutPrefix(strings.TrimSpace(protocol), tokenSubprotocol); ok {
+			return bearer, true
+		}
+	}
+	return "", false
+}
+
+func isEditor(c *gin.Context) bool {
+	return c.GetBool(editorKey)
+}
+
+// visibleAlbums drops albums that are not public unless the request comes from an editor.
+func visibleAlbums(c *gin.Context, albums []domain.Album) []domain.Album {
+	if isEditor(c) {
+		return albums
+	}
+	visible := make([]domain.Album, 0, len(albums))
+	for _, album := range albums {
+		if album.IsPublic() {
+			visible = append(visible, album)
+		}
+	}
+	return visible
+}
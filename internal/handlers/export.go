@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Formats of the catalog export, as given by ?format=.
+const (
+	exportNDJSON = "ndjson"
+	exportCSV    = "csv"
+)
+
+// Media type of newline delimited JSON.
+const ndjsonContentType = "application/x-ndjson"
+
+// ExportAlbums streams the catalog as newline delimited JSON or CSV, filtered like GetAlbums and
+// in ID order, ?after_id= resumes an export after the last album it delivered. Albums are read
+// and flushed batch by batch. A failure once rows were sent aborts the connection, so clients
+// do not take a truncated export for a complete one.
+func (h *AlbumHandler) ExportAlbums(c *gin.Context) {
+	format := c.DefaultQuery("format", exportNDJSON)
+	if format != exportNDJSON && format != exportCSV {
+		abortWithError(c, http.StatusNotAcceptable, fmt.Errorf("unsupported export format %q, expected %s or %s", format, exportNDJSON, exportCSV))
+		return
+	}
+	filter, err := albumFilterFromQuery(c)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if !isEditor(c) {
+		filter.Status = domain.AlbumPublished
+	}
+	var afterID uint64
+	if value := c.Query("after_id"); value != "" {
+		if afterID, err = strconv.ParseUint(value, 10, 0); err != nil {
+			abortWithError(c, http.StatusBadRequest, errors.New("after_id must be a non-negative integer"))
+			return
+		}
+	}
+
+	export := &albumExport{c: c, format: format}
+	err = h.service.ExportAlbums(filter, uint(afterID), export.write)
+	if err == nil {
+		err = export.finish()
+	}
+	switch {
+	case err == nil:
+	case c.Request.Context().Err() != nil:
+		// The client went away, there is no one left to tell
+	case !export.started:
+		abortWithError(c, http.StatusInternalServerError, err)
+	default:
+		c.Error(err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// albumExport writes the rows of each batch as it arrives, the response starts with the first one.
+type albumExport struct {
+	c       *gin.Context
+	format  string
+	started bool
+	json    *json.Encoder
+	csv     *csv.Writer
+}
+
+func (e *albumExport) start() {
+	e.started = true
+	if e.format == exportCSV {
+		e.c.Header("Content-Type", "text/csv; charset=utf-8")
+		e.csv = csv.NewWriter(e.c.Writer)
+		e.csv.Write(albumCSVHeader)
+	} else {
+		e.c.Header("Content-Type", ndjsonContentType)
+		e.json = json.NewEncoder(e.c.Writer)
+	}
+	e.c.Status(http.StatusOK)
+}
+
+func (e *albumExport) write(albums []domain.Album) error {
+	if err := e.c.Request.Context().Err(); err != nil {
+		return err
+	}
+	if !e.started {
+		e.start()
+	}
+	for _, album := range albums {
+		row := exportRow(album)
+		if e.csv != nil {
+			e.csv.Write(albumCSVRecord(row))
+			continue
+		}
+		if err := e.json.Encode(row); err != nil {
+			return err
+		}
+	}
+	return e.flush()
+}
+
+// finish sends the response of an export without albums, the CSV header only.
+func (e *albumExport) finish() error {
+	if e.started {
+		return nil
+	}
+	e.start()
+	return e.flush()
+}
+
+func (e *albumExport) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	e.c.Writer.Flush()
+	return nil
+}
+
+// exportRow is an album as exported, without pricing or currency conversion.
+func exportRow(album domain.Album) albumResponse {
+	return albumResponse{Album: album, CoverURL: coverURL(album), CoverSrcset: coverSrcset(album)}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExportAlbums(t *testing.T) {
+	mockService := new(MockAlbumService)
+	r := gin.New()
+	r.Use(Problems(logger.NewLogger()), ValidateRequests(AlbumAPISpec(), true))
+	handler := NewAlbumHandler(mockService)
+	r.GET("/v1/albums/export", handler.ExportAlbums)
+
+	batches := [][]domain.Album{
+		{{ID: 1, Title: "Abbey Road", Price: 9.99, Status: domain.AlbumPublished}, {ID: 2, Title: "Let It Be", Price: 8.99, Status: domain.AlbumPublished}},
+		{{ID: 5, Title: "Revolver", Price: 7.99, Status: domain.AlbumPublished}},
+	}
+	published := func(genres ...string) interface{} {
+		return mock.MatchedBy(func(filter domain.AlbumFilter) bool {
+			return filter.Status == domain.AlbumPublished && assert.ObjectsAreEqual(genres, filter.Genres)
+		})
+	}
+
+	t.Run("GET :: /v1/albums/export endpoint streams NDJSON, one album per line", func(t *testing.T) {
+		mockService.On("ExportAlbums", published("Rock"), uint(0)).Return(batches, nil).Once()
+
+		req, _ := http.NewRequest("GET", "/v1/albums/export?genre=Rock", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, ndjsonContentType, w.Header().Get("Content-Type"))
+		assert.True(t, w.Flushed)
+		var ids []uint
+		scanner := bufio.NewScanner(w.Body)
+		for scanner.Scan() {
+			var album albumResponse
+			assert.Nil(t, json.Unmarshal(scanner.Bytes(), &album))
+			ids = append(ids, album.ID)
+		}
+		assert.Equal(t, []uint{1, 2, 5}, ids)
+	})
+
+	t.Run("GET :: /v1/albums/export endpoint streams CSV and resumes after an ID", func(t *testing.T) {
+		mockService.On("ExportAlbums", published(), uint(2)).Return(batches[1:], nil).Once()
+
+		req, _ := http.NewRequest("GET", "/v1/albums/export?format=csv&after_id=2", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		records, err := csv.NewReader(w.Body).ReadAll()
+		assert.Nil(t, err)
+		assert.Equal(t, albumCSVHeader, records[0])
+		assert.Len(t, records, 2)
+		assert.Equal(t, []string{"5", "Revolver"}, records[1][:2])
+	})
+
+	t.Run("GET :: /v1/albums/export endpoint sends the CSV header of an empty export", func(t *testing.T) {
+		mockService.On("ExportAlbums", published(), uint(99)).Return([][]domain.Album{}, nil).Once()
+
+		req, _ := http.NewRequest("GET", "/v1/albums/export?format=csv&after_id=99", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		records, _ := csv.NewReader(w.Body).ReadAll()
+		assert.Equal(t, [][]string{albumCSVHeader}, records)
+	})
+
+	t.Run("GET :: /v1/albums/export endpoint rejects invalid parameters", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/albums/export?format=xml", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+
+		req, _ = http.NewRequest("GET", "/v1/albums/export?after_id=-1", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("GET :: /v1/albums/export endpoint reports failures before the first row as problems", func(t *testing.T) {
+		mockService.On("ExportAlbums", published(), uint(10)).Return([][]domain.Album{}, errors.New("connection refused")).Once()
+
+		req, _ := http.NewRequest("GET", "/v1/albums/export?after_id=10", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+	})
+
+	t.Run("GET :: /v1/albums/export endpoint aborts the connection when failing midway", func(t *testing.T) {
+		mockService.On("ExportAlbums", published(), uint(20)).Return(batches[:1], errors.New("connection reset")).Once()
+
+		server := httptest.NewServer(r)
+		defer server.Close()
+		resp, err := http.Get(server.URL + "/v1/albums/export?after_id=20")
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var lines int
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines++
+		}
+		assert.Equal(t, 2, lines)
+		assert.NotNil(t, scanner.Err(), "the truncated body must not end like a complete one")
+	})
+}
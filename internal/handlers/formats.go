@@ -0,0 +1,464 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/openapi"
+	"github.com/ugorji/go/codec"
+	"gopkg.in/yaml.v3"
+)
+
+// Names of the album representations, as given by ?format=.
+const (
+	formatJSON    = "json"
+	formatXML     = "xml"
+	formatCSV     = "csv"
+	formatYAML    = "yaml"
+	formatMsgPack = "msgpack"
+)
+
+// Representation of albums in responses and, except for CSV, request bodies.
+type albumFormat struct {
+	name string
+	// Content-Type of responses
+	mediaType string
+	// Further media types recognized in Accept and Content-Type
+	aliases []string
+}
+
+func (f albumFormat) mediaTypes() []string {
+	return append([]string{f.mediaType}, f.aliases...)
+}
+
+// Album representations, the first one is served when the client has no preference.
+var albumFormats = []albumFormat{
+	{name: formatJSON, mediaType: binding.MIMEJSON},
+	{name: formatXML, mediaType: binding.MIMEXML, aliases: []string{binding.MIMEXML2}},
+	{name: formatCSV, mediaType: "text/csv"},
+	{name: formatYAML, mediaType: binding.MIMEYAML2, aliases: []string{binding.MIMEYAML, "text/yaml"}},
+	{name: formatMsgPack, mediaType: binding.MIMEMSGPACK2, aliases: []string{binding.MIMEMSGPACK, "application/vnd.msgpack"}},
+}
+
+// Rows written to a CSV response between flushes.
+const csvFlushRows = 100
+
+// Columns of the CSV representation, list values are separated by |.
+var albumCSVHeader = []string{
+	"id", "title", "artist_id", "artist", "price", "currency", "effective_price", "release_date", "label",
+	"catalog_number", "format", "barcode", "status", "published_at", "average_rating", "review_count",
+	"track_count", "total_duration", "genres", "tags", "cover_url",
+}
+
+// Schema-less MessagePack handle decoding maps and strings the way encoding/json does.
+var msgpackHandle = func() *codec.MsgpackHandle {
+	handle := &codec.MsgpackHandle{WriteExt: true}
+	handle.RawToString = true
+	handle.MapType = reflect.TypeOf(map[string]interface{}(nil))
+	return handle
+}()
+
+// XML root of album lists, single albums are rendered as <album>.
+type albumList struct {
+	XMLName xml.Name        `xml:"albums"`
+	Albums  []albumResponse `xml:"album"`
+}
+
+// negotiateAlbumFormat picks the representation from ?format=, which takes precedence, or the
+// Accept header. The error is answered with 406 Not Acceptable.
+func negotiateAlbumFormat(c *gin.Context) (albumFormat, error) {
+	c.Header("Vary", "Accept")
+	if name := c.Query("format"); name != "" {
+		for _, format := range albumFormats {
+			if format.name == name {
+				return format, nil
+			}
+		}
+		return albumFormat{}, fmt.Errorf("unsupported format %q, expected one of %s", name, strings.Join(albumFormatNames(), ", "))
+	}
+
+	ranges := parseAccept(c.GetHeader("Accept"))
+	if len(ranges) == 0 {
+		return albumFormats[0], nil
+	}
+	best, bestQuality := albumFormat{}, 0.0
+	for _, format := range albumFormats {
+		if quality := acceptQuality(ranges, format); quality > bestQuality {
+			best, bestQuality = format, quality
+		}
+	}
+	if bestQuality == 0 {
+		return albumFormat{}, fmt.Errorf("none of the accepted media types is available, albums are served as %s", strings.Join(albumMediaTypes(), ", "))
+	}
+	return best, nil
+}
+
+// Media range of an Accept header with its quality value.
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+func parseAccept(header string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if value, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+	}
+	return ranges
+}
+
+// acceptQuality is the quality of the most specific range matching one of the format's
+// media types, 0 when none matches.
+func acceptQuality(ranges []mediaRange, format albumFormat) float64 {
+	quality, specificity := 0.0, 0
+	for _, mediaType := range format.mediaTypes() {
+		kind, _, _ := strings.Cut(mediaType, "/")
+		for _, r := range ranges {
+			matched := 0
+			switch r.mediaType {
+			case mediaType:
+				matched = 3
+			case kind + "/*":
+				matched = 2
+			case "*/*":
+				matched = 1
+			}
+			if matched > specificity {
+				quality, specificity = r.quality, matched
+			}
+		}
+	}
+	return quality
+}
+
+func albumFormatNames() []string {
+	names := make([]string, 0, len(albumFormats))
+	for _, format := range albumFormats {
+		names = append(names, format.name)
+	}
+	return names
+}
+
+func albumMediaTypes() []string {
+	mediaTypes := make([]string, 0, len(albumFormats))
+	for _, format := range albumFormats {
+		mediaTypes = append(mediaTypes, format.mediaType)
+	}
+	return mediaTypes
+}
+
+// writeAlbums renders a list of albums, CSV is streamed row by row.
+func writeAlbums(c *gin.Context, status int, format albumFormat, albums []albumResponse) {
+	switch format.name {
+	case formatCSV:
+		writeAlbumsCSV(c, status, albums)
+	case formatXML:
+		writeXML(c, status, albumList{Albums: albums}, xml.StartElement{})
+	default:
+		writeData(c, status, format, albums)
+	}
+}
+
+// writeAlbum renders a single album, as a one row table in CSV.
+func writeAlbum(c *gin.Context, status int, format albumFormat, album albumResponse) {
+	switch format.name {
+	case formatCSV:
+		writeAlbumsCSV(c, status, []albumResponse{album})
+	case formatXML:
+		writeXML(c, status, album, xml.StartElement{Name: xml.Name{Local: "album"}})
+	default:
+		writeData(c, status, format, album)
+	}
+}
+
+// writeData renders value as JSON, YAML or MessagePack. YAML and MessagePack are converted from
+// the JSON representation, so every format uses the same member names.
+func writeData(c *gin.Context, status int, format albumFormat, value interface{}) {
+	if format.name == formatJSON {
+		c.JSON(status, value)
+		return
+	}
+	plain, err := plainValue(value)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	var data []byte
+	switch format.name {
+	case formatYAML:
+		data, err = yaml.Marshal(plain)
+	case formatMsgPack:
+		err = codec.NewEncoderBytes(&data, msgpackHandle).Encode(plain)
+	default:
+		err = fmt.Errorf("no encoder for format %s", format.name)
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Data(status, format.mediaType, data)
+}
+
+// writeXML encodes value into a buffer first, so encoding errors are still reported as problems.
+func writeXML(c *gin.Context, status int, value interface{}, start xml.StartElement) {
+	var buffer bytes.Buffer
+	buffer.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buffer)
+	var err error
+	if start.Name.Local != "" {
+		err = encoder.EncodeElement(value, start)
+	} else {
+		err = encoder.Encode(value)
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Data(status, binding.MIMEXML+"; charset=utf-8", buffer.Bytes())
+}
+
+func writeAlbumsCSV(c *gin.Context, status int, albums []albumResponse) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(status)
+	writer := csv.NewWriter(c.Writer)
+	writer.Write(albumCSVHeader)
+	for i, album := range albums {
+		writer.Write(albumCSVRecord(album))
+		if (i+1)%csvFlushRows == 0 {
+			writer.Flush()
+			c.Writer.Flush()
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		c.Error(err)
+	}
+}
+
+// albumCSVRecord is the row of album in albumCSVHeader order.
+func albumCSVRecord(album albumResponse) []string {
+	artist := ""
+	if album.Artist != nil {
+		artist = album.Artist.Name
+	}
+	currency := album.Currency
+	if currency == "" {
+		currency = domain.BaseCurrency
+	}
+	effectivePrice := ""
+	if album.EffectivePrice != nil {
+		effectivePrice = formatCSVNumber(*album.EffectivePrice)
+	}
+	publishedAt := ""
+	if album.PublishedAt != nil {
+		publishedAt = album.PublishedAt.UTC().Format(time.RFC3339)
+	}
+	genres := make([]string, 0, len(album.Genres))
+	for _, genre := range album.Genres {
+		genres = append(genres, genre.Name)
+	}
+	tags := make([]string, 0, len(album.Tags))
+	for _, tag := range album.Tags {
+		tags = append(tags, tag.Name)
+	}
+	return []string{
+		strconv.FormatUint(uint64(album.ID), 10),
+		csvText(album.Title),
+		strconv.FormatUint(uint64(album.ArtistID), 10),
+		csvText(artist),
+		formatCSVNumber(album.Price),
+		currency,
+		effectivePrice,
+		album.ReleaseDate.String(),
+		csvText(album.Label),
+		csvText(album.CatalogNumber),
+		string(album.Format),
+		album.Barcode,
+		string(album.Status),
+		publishedAt,
+		formatCSVNumber(album.AverageRating),
+		strconv.Itoa(album.ReviewCount),
+		strconv.Itoa(album.TrackCount),
+		strconv.Itoa(album.TotalDuration),
+		csvText(strings.Join(genres, "|")),
+		csvText(strings.Join(tags, "|")),
+		album.CoverURL,
+	}
+}
+
+func formatCSVNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// csvText keeps spreadsheets from evaluating free text as a formula.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// plainValue converts value to its JSON representation made of maps, slices and scalars,
+// integers are kept apart from floating point numbers.
+func plainValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := openapi.DecodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return plainNumbers(decoded), nil
+}
+
+func plainNumbers(value interface{}) interface{} {
+	switch value := value.(type) {
+	case json.Number:
+		if integer, err := value.Int64(); err == nil {
+			return integer
+		}
+		number, _ := value.Float64()
+		return number
+	case []interface{}:
+		for i, item := range value {
+			value[i] = plainNumbers(item)
+		}
+	case map[string]interface{}:
+		for name, item := range value {
+			value[name] = plainNumbers(item)
+		}
+	}
+	return value
+}
+
+// bindAlbum decodes and validates an album from a JSON, XML, YAML or MessagePack body, bodies
+// without Content-Type are read as JSON. It returns the status to answer with on failure.
+func bindAlbum(c *gin.Context, album *domain.Album) (int, error) {
+	mediaType := c.ContentType()
+	name := formatJSON
+	if mediaType != "" {
+		name = ""
+		for _, format := range albumFormats {
+			if format.name != formatCSV && slices.Contains(format.mediaTypes(), mediaType) {
+				name = format.name
+			}
+		}
+	}
+
+	var err error
+	switch name {
+	case formatJSON:
+		err = c.ShouldBindJSON(album)
+	case formatXML:
+		err = c.ShouldBindXML(album)
+	case formatYAML, formatMsgPack:
+		err = bindPlainBody(c.Request.Body, name, album)
+	default:
+		return http.StatusUnsupportedMediaType, fmt.Errorf("unsupported media type %q, albums are accepted as %s",
+			mediaType, strings.Join([]string{binding.MIMEJSON, binding.MIMEXML, binding.MIMEYAML2, binding.MIMEMSGPACK2}, ", "))
+	}
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	return 0, nil
+}
+
+// bindPlainBody decodes a YAML or MessagePack body and binds it through its JSON
+// representation, so member names and field errors match JSON bodies.
+func bindPlainBody(body io.Reader, name string, album *domain.Album) error {
+	if body == nil {
+		return errors.New("request body is empty")
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	var value interface{}
+	if name == formatYAML {
+		err = yaml.Unmarshal(data, &value)
+	} else {
+		err = codec.NewDecoderBytes(data, msgpackHandle).Decode(&value)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if value == nil {
+		return errors.New("request body is empty")
+	}
+	if data, err = json.Marshal(yamlDates(value)); err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return binding.JSON.BindBody(data, album)
+}
+
+// yamlDates turns the timestamps YAML makes of unquoted dates such as 1969-09-26 back into
+// dates, other timestamps are encoded as RFC 3339 by encoding/json.
+func yamlDates(value interface{}) interface{} {
+	switch value := value.(type) {
+	case time.Time:
+		if value.Equal(value.Truncate(24 * time.Hour)) {
+			return value.Format(time.DateOnly)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = yamlDates(item)
+		}
+	case map[string]interface{}:
+		for name, item := range value {
+			value[name] = yamlDates(item)
+		}
+	}
+	return value
+}
+
+// srcset renders cover_srcset in XML as <source width="256w"> elements ordered by width.
+type srcset map[string]string
+
+func (s srcset) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	widths := make([]string, 0, len(s))
+	for width := range s {
+		widths = append(widths, width)
+	}
+	sort.Slice(widths, func(i, j int) bool {
+		a, _ := strconv.Atoi(strings.TrimSuffix(widths[i], "w"))
+		b, _ := strconv.Atoi(strings.TrimSuffix(widths[j], "w"))
+		return a < b
+	})
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, width := range widths {
+		source := xml.StartElement{Name: xml.Name{Local: "source"}, Attr: []xml.Attr{{Name: xml.Name{Local: "width"}, Value: width}}}
+		if err := e.EncodeElement(s[width], source); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
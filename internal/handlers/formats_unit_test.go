@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/ugorji/go/codec"
+)
+
+func TestAlbumFormats(t *testing.T) {
+	mockService := new(MockAlbumService)
+	r := gin.New()
+	r.Use(Problems(logger.NewLogger()), ValidateRequests(AlbumAPISpec(), true))
+	handler := NewAlbumHandler(mockService)
+	r.GET("/v1/albums", handler.GetAlbums)
+	r.GET("/v1/albums/:id", handler.GetAlbumByID)
+	r.POST("/v1/albums", handler.CreateAlbum)
+
+	album := domain.Album{
+		ID: 1, Title: "Abbey Road", ArtistID: 3, Artist: &domain.Artist{ID: 3, Name: "The Beatles"}, Price: 9.99,
+		ReleaseDate: domain.NewDate(1969, time.September, 26), Status: domain.AlbumPublished,
+		Tracks: []domain.Track{{DiscNumber: 1, Position: 1, Title: "Come Together", Duration: 259}},
+		Genres: []domain.Genre{{ID: 1, Name: "Rock"}, {ID: 2, Name: "Pop"}},
+	}
+	formula := domain.Album{ID: 2, Title: "=HYPERLINK(\"http://evil\")", Price: 5, Status: domain.AlbumPublished}
+	mockService.On("GetAllAlbums", mock.Anything).Return([]domain.Album{album, formula}, nil)
+	mockService.On("GetAlbumByID", 1).Return(album, nil)
+
+	get := func(path, accept string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("GET :: /v1/albums/:id endpoint negotiates the representation from Accept", func(t *testing.T) {
+		for accept, contentType := range map[string]string{
+			"":                                 "application/json; charset=utf-8",
+			"*/*":                              "application/json; charset=utf-8",
+			"text/xml":                         "application/xml; charset=utf-8",
+			"text/csv;q=0.5, application/xml":  "application/xml; charset=utf-8",
+			"application/*;q=0.1, text/csv":    "text/csv; charset=utf-8",
+			"application/x-yaml":               "application/yaml",
+			"application/x-msgpack, */*;q=0.1": "application/msgpack",
+		} {
+			w := get("/v1/albums/1", accept)
+			assert.Equal(t, http.StatusOK, w.Code, accept)
+			assert.Equal(t, contentType, w.Header().Get("Content-Type"), accept)
+			assert.Equal(t, "Accept", w.Header().Get("Vary"))
+		}
+	})
+
+	t.Run("GET :: /v1/albums/:id endpoint renders XML, YAML and MessagePack", func(t *testing.T) {
+		w := get("/v1/albums/1?format=xml", "application/json")
+		assert.Contains(t, w.Body.String(), `<album><id>1</id><title>Abbey Road</title><artist_id>3</artist_id><artist><id>3</id><name>The Beatles</name></artist>`)
+		assert.Contains(t, w.Body.String(), `<tracks><track><disc_number>1</disc_number><position>1</position><title>Come Together</title>`)
+		assert.Contains(t, w.Body.String(), `<release_date>1969-09-26</release_date>`)
+
+		w = get("/v1/albums/1?format=yaml", "")
+		assert.Contains(t, w.Body.String(), "\nprice: 9.99\n")
+		assert.Contains(t, w.Body.String(), "\nrelease_date: \"1969-09-26\"\n")
+		assert.Contains(t, w.Body.String(), "\ntitle: Abbey Road\n")
+
+		w = get("/v1/albums/1?format=msgpack", "")
+		var decoded map[string]interface{}
+		assert.Nil(t, codec.NewDecoderBytes(w.Body.Bytes(), msgpackHandle).Decode(&decoded))
+		assert.Equal(t, "Abbey Road", decoded["title"])
+		assert.Equal(t, int64(1), decoded["id"])
+		assert.Equal(t, 9.99, decoded["price"])
+	})
+
+	t.Run("GET :: /v1/albums endpoint lists albums in XML and CSV", func(t *testing.T) {
+		w := get("/v1/albums", "application/xml")
+		assert.Contains(t, w.Body.String(), `<albums><album><id>1</id>`)
+		assert.Contains(t, w.Body.String(), `</album><album><id>2</id>`)
+
+		w = get("/v1/albums?format=csv", "")
+		records, err := csv.NewReader(w.Body).ReadAll()
+		assert.Nil(t, err)
+		assert.Len(t, records, 3)
+		assert.Equal(t, albumCSVHeader, records[0])
+		assert.Equal(t, []string{"1", "Abbey Road", "3", "The Beatles", "9.99", "USD", "", "1969-09-26", "", "", "", "",
+			"published", "", "0", "0", "0", "0", "Rock|Pop", "", ""}, records[1])
+		assert.Equal(t, `'=HYPERLINK("http://evil")`, records[2][1])
+	})
+
+	t.Run("GET :: /v1/albums endpoint answers unsupported representations with 406", func(t *testing.T) {
+		w := get("/v1/albums", "application/pdf")
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+		assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+
+		w = get("/v1/albums?format=pdf", "")
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+		assert.Contains(t, decodeProblem(t, w).Detail, "json, xml, csv, yaml, msgpack")
+
+		w = get("/v1/albums", "application/json;q=0, application/pdf")
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	})
+
+	t.Run("POST :: /v1/albums endpoint decodes XML, YAML and MessagePack bodies", func(t *testing.T) {
+		expected := domain.Album{
+			Title: "Abbey Road", ArtistID: 3, Price: 9.99, ReleaseDate: domain.NewDate(1969, time.September, 26),
+			Tracks: []domain.Track{{Position: 1, Title: "Come Together", Duration: 259}},
+		}
+		mockService.On("CreateAlbum", expected).Return(album, nil)
+
+		var msgpackBody []byte
+		assert.Nil(t, codec.NewEncoderBytes(&msgpackBody, msgpackHandle).Encode(map[string]interface{}{
+			"title": "Abbey Road", "artist_id": 3, "price": 9.99, "release_date": "1969-09-26",
+			"tracks": []interface{}{map[string]interface{}{"position": 1, "title": "Come Together", "duration": 259}},
+		}))
+		for contentType, body := range map[string][]byte{
+			"application/xml": []byte(`<album><title>Abbey Road</title><artist_id>3</artist_id><price>9.99</price>` +
+				`<release_date>1969-09-26</release_date><tracks><track><position>1</position><title>Come Together</title>` +
+				`<duration>259</duration></track></tracks></album>`),
+			"application/yaml; charset=utf-8": []byte("title: Abbey Road\nartist_id: 3\nprice: 9.99\nrelease_date: 1969-09-26\n" +
+				"tracks:\n  - position: 1\n    title: Come Together\n    duration: 259\n"),
+			"application/msgpack": msgpackBody,
+		} {
+			req, _ := http.NewRequest("POST", "/v1/albums", bytes.NewReader(body))
+			req.Header.Set("Content-Type", contentType)
+			req.Header.Set("Accept", "application/xml")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusCreated, w.Code, contentType)
+			assert.True(t, strings.HasPrefix(w.Body.String(), `<?xml version="1.0" encoding="UTF-8"?>`+"\n<album>"), contentType)
+		}
+	})
+
+	t.Run("POST :: /v1/albums endpoint reports field errors of YAML bodies like JSON", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v1/albums", strings.NewReader("title: Abbey Road\ntracks:\n  - title: 1\n"))
+		req.Header.Set("Content-Type", "application/yaml")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, []FieldError{{In: "body", Pointer: "/tracks/0/title", Detail: "must be string"}}, decodeProblem(t, w).Errors)
+	})
+
+	t.Run("POST :: /v1/albums endpoint answers CSV bodies with 415", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v1/albums", strings.NewReader("title\nAbbey Road\n"))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+		// Without the validation middleware the handler rejects the body itself
+		direct := gin.New()
+		direct.Use(Problems(logger.NewLogger()))
+		direct.POST("/v1/albums", handler.CreateAlbum)
+		req, _ = http.NewRequest("POST", "/v1/albums", strings.NewReader("title\nAbbey Road\n"))
+		req.Header.Set("Content-Type", "text/csv")
+		w = httptest.NewRecorder()
+		direct.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		assert.Contains(t, decodeProblem(t, w).Detail, `unsupported media type "text/csv"`)
+	})
+}
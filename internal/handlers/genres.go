@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Handles genre HTTP requests.
+type GenreHandler struct {
+	service domain.GenreService
+}
+
+func NewGenreHandler(service domain.GenreService) *GenreHandler {
+	return &GenreHandler{service: service}
+}
+
+func (h *GenreHandler) GetGenres(c *gin.Context) {
+	genres, err := h.service.GetAllGenres()
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, genres)
+}
+
+func (h *GenreHandler) GetGenreByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	genre, err := h.service.GetGenreByID(id)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, genre)
+}
+
+func (h *GenreHandler) CreateGenre(c *gin.Context) {
+	var genre domain.Genre
+	if err := c.ShouldBindJSON(&genre); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	createdGenre, err := h.service.CreateGenre(genre)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, createdGenre)
+}
+
+func (h *GenreHandler) UpdateGenre(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	var genre domain.Genre
+	if err := c.ShouldBindJSON(&genre); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	genre.ID = uint(id)
+	updatedGenre, err := h.service.UpdateGenre(genre)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, updatedGenre)
+}
+
+func (h *GenreHandler) DeleteGenre(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := h.service.DeleteGenre(id); err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -1,83 +1,452 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ssitko/hex-domain/internal/domain"
 )
 
+// Unpublished albums are reported as missing to anyone but editors.
+var errAlbumNotFound = fmt.Errorf("album %w", domain.ErrNotFound)
+
 // Handler Layer
 // Handles HTTP requests and maps them to service calls.
 type AlbumHandler struct {
 	service domain.AlbumService
+	rates   domain.ExchangeRateProvider
+	pricing domain.PricingService
+	covers  domain.CoverService
+
+	events    domain.AlbumEventSource
+	heartbeat time.Duration
+}
+
+// Optional AlbumHandler dependencies.
+type AlbumHandlerOption func(*AlbumHandler)
+
+// WithExchangeRates enables the ?currency= query parameter on album GET endpoints.
+func WithExchangeRates(rates domain.ExchangeRateProvider) AlbumHandlerOption {
+	return func(h *AlbumHandler) {
+		h.rates = rates
+	}
+}
+
+// WithPricing adds promotion based effective prices to album GET responses.
+func WithPricing(pricing domain.PricingService) AlbumHandlerOption {
+	return func(h *AlbumHandler) {
+		h.pricing = pricing
+	}
+}
+
+// WithCovers enables cover art upload and download.
+func WithCovers(covers domain.CoverService) AlbumHandlerOption {
+	return func(h *AlbumHandler) {
+		h.covers = covers
+	}
 }
 
-func NewAlbumHandler(service domain.AlbumService) *AlbumHandler {
-	return &AlbumHandler{service: service}
+func NewAlbumHandler(service domain.AlbumService, opts ...AlbumHandlerOption) *AlbumHandler {
+	h := &AlbumHandler{service: service}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
+// GetAlbums lists published albums, editors see every status and may filter by ?status=.
 func (h *AlbumHandler) GetAlbums(c *gin.Context) {
-	albums, err := h.service.GetAllAlbums()
+	format, err := negotiateAlbumFormat(c)
+	if err != nil {
+		abortWithError(c, http.StatusNotAcceptable, err)
+		return
+	}
+	filter, err := albumFilterFromQuery(c)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if !isEditor(c) {
+		filter.Status = domain.AlbumPublished
+	}
+	albums, err := h.service.GetAllAlbums(filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		abortWithError(c, http.StatusInternalServerError, err)
 		return
 	}
-	c.JSON(http.StatusOK, albums)
+	response, ok := h.presentAlbums(c, albums)
+	if !ok {
+		return
+	}
+	writeAlbums(c, http.StatusOK, format, response)
 }
 
 func (h *AlbumHandler) GetAlbumByID(c *gin.Context) {
+	format, err := negotiateAlbumFormat(c)
+	if err != nil {
+		abortWithError(c, http.StatusNotAcceptable, err)
+		return
+	}
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		abortWithError(c, http.StatusBadRequest, err)
 		return
 	}
 	album, err := h.service.GetAlbumByID(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if len(visibleAlbums(c, []domain.Album{album})) == 0 {
+		abortWithError(c, http.StatusNotFound, errAlbumNotFound)
+		return
+	}
+	response, ok := h.presentAlbums(c, []domain.Album{album})
+	if !ok {
+		return
+	}
+	writeAlbum(c, http.StatusOK, format, response[0])
+}
+
+// albumVisible aborts with 404 unless the album is public or the request comes from an editor.
+func (h *AlbumHandler) albumVisible(c *gin.Context, id int) bool {
+	if isEditor(c) {
+		return true
+	}
+	album, err := h.service.GetAlbumByID(id)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return false
+	}
+	if !album.IsPublic() {
+		abortWithError(c, http.StatusNotFound, errAlbumNotFound)
+		return false
+	}
+	return true
+}
+
+func (h *AlbumHandler) GetAlbumsByBarcode(c *gin.Context) {
+	format, err := negotiateAlbumFormat(c)
+	if err != nil {
+		abortWithError(c, http.StatusNotAcceptable, err)
+		return
+	}
+	albums, err := h.service.GetAlbumsByBarcode(c.Param("code"))
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	albums = visibleAlbums(c, albums)
+	if len(albums) == 0 {
+		abortWithError(c, http.StatusNotFound, errors.New("no album found for barcode"))
+		return
+	}
+	response, ok := h.presentAlbums(c, albums)
+	if !ok {
+		return
+	}
+	writeAlbums(c, http.StatusOK, format, response)
+}
+
+func (h *AlbumHandler) SubmitAlbum(c *gin.Context) {
+	h.transitionAlbum(c, h.service.SubmitAlbum)
+}
+
+func (h *AlbumHandler) ApproveAlbum(c *gin.Context) {
+	h.transitionAlbum(c, h.service.ApproveAlbum)
+}
+
+func (h *AlbumHandler) PublishAlbum(c *gin.Context) {
+	h.transitionAlbum(c, h.service.PublishAlbum)
+}
+
+func (h *AlbumHandler) ArchiveAlbum(c *gin.Context) {
+	h.transitionAlbum(c, h.service.ArchiveAlbum)
+}
+
+func (h *AlbumHandler) transitionAlbum(c *gin.Context, transition func(id int) (domain.Album, error)) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	album, err := transition(id)
+	if errors.Is(err, domain.ErrInvalidAlbumTransition) {
+		abortWithError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
 		return
 	}
 	c.JSON(http.StatusOK, album)
 }
 
+// Range of price history returned when the request gives no from parameter.
+const defaultPriceHistoryRange = 90 * 24 * time.Hour
+
+// GetAlbumPriceHistory returns price changes and aggregates, ?bucket=day|week adds per-period
+// min/max/avg. lowest_30_days is the EU Omnibus reference price before a reduction.
+func (h *AlbumHandler) GetAlbumPriceHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	query, err := priceHistoryQueryFromQuery(c, time.Now().UTC())
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if !h.albumVisible(c, id) {
+		return
+	}
+	history, err := h.service.GetAlbumPriceHistory(id, query)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidPriceHistoryQuery) {
+			status = http.StatusBadRequest
+		}
+		abortWithError(c, status, err)
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// CreateAlbum accepts and answers with any album representation but CSV.
 func (h *AlbumHandler) CreateAlbum(c *gin.Context) {
+	format, err := negotiateAlbumFormat(c)
+	if err != nil {
+		abortWithError(c, http.StatusNotAcceptable, err)
+		return
+	}
 	var album domain.Album
-	if err := c.BindJSON(&album); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if status, err := bindAlbum(c, &album); err != nil {
+		abortWithError(c, status, err)
 		return
 	}
 	createdAlbum, err := h.service.CreateAlbum(album)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		abortWithError(c, albumWriteErrorStatus(err), err)
 		return
 	}
-	c.JSON(http.StatusCreated, createdAlbum)
+	writeAlbum(c, http.StatusCreated, format, albumResponse{Album: createdAlbum})
 }
 
+// UpdateAlbum accepts and answers with any album representation but CSV.
 func (h *AlbumHandler) UpdateAlbum(c *gin.Context) {
+	format, err := negotiateAlbumFormat(c)
+	if err != nil {
+		abortWithError(c, http.StatusNotAcceptable, err)
+		return
+	}
 	var album domain.Album
-	if err := c.BindJSON(&album); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if status, err := bindAlbum(c, &album); err != nil {
+		abortWithError(c, status, err)
 		return
 	}
 	updatedAlbum, err := h.service.UpdateAlbum(album)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		abortWithError(c, albumWriteErrorStatus(err), err)
 		return
 	}
-	c.JSON(http.StatusOK, updatedAlbum)
+	writeAlbum(c, http.StatusOK, format, albumResponse{Album: updatedAlbum})
 }
 
 func (h *AlbumHandler) DeleteAlbum(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		abortWithError(c, http.StatusBadRequest, err)
 		return
 	}
 	if err := h.service.DeleteAlbum(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		abortWithError(c, http.StatusInternalServerError, err)
 		return
 	}
 	c.JSON(http.StatusNoContent, nil)
 }
+
+func (h *AlbumHandler) GetAlbumTracks(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if !h.albumVisible(c, id) {
+		return
+	}
+	tracks, err := h.service.GetAlbumTracks(id)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, tracks)
+}
+
+func (h *AlbumHandler) ReplaceAlbumTracks(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	var tracks []domain.Track
+	if err := c.ShouldBindJSON(&tracks); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	replacedTracks, err := h.service.ReplaceAlbumTracks(id, tracks)
+	if errors.Is(err, domain.ErrInvalidTrack) {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, replacedTracks)
+}
+
+func (h *AlbumHandler) AddAlbumGenre(c *gin.Context) {
+	h.updateAlbumLabel(c, c.Param("genre"), h.service.AddAlbumGenre)
+}
+
+func (h *AlbumHandler) RemoveAlbumGenre(c *gin.Context) {
+	h.updateAlbumLabel(c, c.Param("genre"), h.service.RemoveAlbumGenre)
+}
+
+func (h *AlbumHandler) AddAlbumTag(c *gin.Context) {
+	h.updateAlbumLabel(c, c.Param("tag"), h.service.AddAlbumTag)
+}
+
+func (h *AlbumHandler) RemoveAlbumTag(c *gin.Context) {
+	h.updateAlbumLabel(c, c.Param("tag"), h.service.RemoveAlbumTag)
+}
+
+// updateAlbumLabel applies a genre or tag change to the album from the :id path param.
+func (h *AlbumHandler) updateAlbumLabel(c *gin.Context, label string, update func(id int, label string) (domain.Album, error)) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if strings.TrimSpace(label) == "" {
+		abortWithError(c, http.StatusBadRequest, errors.New("label must not be empty"))
+		return
+	}
+	album, err := update(id, label)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, album)
+}
+
+// albumWriteErrorStatus maps album create and update errors to a response status.
+func albumWriteErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidAlbum):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrDuplicateBarcode):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// albumFilterFromQuery reads album list filters. Values may be repeated or comma separated,
+// genre_match and tag_match select any-of (default) or all-of semantics.
+func albumFilterFromQuery(c *gin.Context) (domain.AlbumFilter, error) {
+	var err error
+	filter := domain.AlbumFilter{
+		Genres: queryList(c, "genre"),
+		Tags:   queryList(c, "tag"),
+	}
+	if filter.GenreMatch, err = matchMode(c.Query("genre_match")); err != nil {
+		return filter, err
+	}
+	if filter.TagMatch, err = matchMode(c.Query("tag_match")); err != nil {
+		return filter, err
+	}
+	if releasedAfter := c.Query("released_after"); releasedAfter != "" {
+		if filter.ReleasedAfter, err = domain.ParseDate(releasedAfter); err != nil {
+			return filter, fmt.Errorf("%w: %s", domain.ErrInvalidFilter, err)
+		}
+	}
+	if status := c.Query("status"); status != "" {
+		if filter.Status, err = domain.ParseAlbumStatus(status); err != nil {
+			return filter, err
+		}
+	}
+	if inStock := c.Query("in_stock"); inStock != "" {
+		value, err := strconv.ParseBool(inStock)
+		if err != nil {
+			return filter, fmt.Errorf("%w: in_stock must be true or false", domain.ErrInvalidFilter)
+		}
+		filter.InStock = &value
+	}
+	return filter, nil
+}
+
+// priceHistoryQueryFromQuery reads from and to as RFC 3339 timestamps or dates, a date as to
+// includes that whole day. The range defaults to the 90 days before now.
+func priceHistoryQueryFromQuery(c *gin.Context, now time.Time) (domain.PriceHistoryQuery, error) {
+	query := domain.PriceHistoryQuery{To: now, Bucket: domain.PriceBucketSize(c.Query("bucket"))}
+	if to := c.Query("to"); to != "" {
+		at, isDate, err := parseQueryTime(to)
+		if err != nil {
+			return query, fmt.Errorf("%w: to: %s", domain.ErrInvalidPriceHistoryQuery, err)
+		}
+		if isDate {
+			at = at.AddDate(0, 0, 1)
+		}
+		query.To = at
+	}
+	query.From = query.To.Add(-defaultPriceHistoryRange)
+	if from := c.Query("from"); from != "" {
+		at, _, err := parseQueryTime(from)
+		if err != nil {
+			return query, fmt.Errorf("%w: from: %s", domain.ErrInvalidPriceHistoryQuery, err)
+		}
+		query.From = at
+	}
+	return query, nil
+}
+
+func parseQueryTime(value string) (time.Time, bool, error) {
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at.UTC(), false, nil
+	}
+	date, err := domain.ParseDate(value)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return date.Time, true, nil
+}
+
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, param := range c.QueryArray(key) {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func matchMode(value string) (domain.MatchMode, error) {
+	switch domain.MatchMode(value) {
+	case "", domain.MatchAny:
+		return domain.MatchAny, nil
+	case domain.MatchAll:
+		return domain.MatchAll, nil
+	default:
+		return "", fmt.Errorf("%w: match mode must be any or all", domain.ErrInvalidFilter)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/ssitko/hex-domain/internal/infrastructure/persistence"
 	"github.com/ssitko/hex-domain/internal/repositories"
 	"github.com/ssitko/hex-domain/internal/services"
+	"github.com/ssitko/hex-domain/pkg/logger"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -38,7 +39,9 @@ func init() {
 	if err != nil {
 		log.Fatalf("failed to connect database: %s", err)
 	}
-	mysqlDB.AutoMigrate(&album.Album{})
+	if err := persistence.Migrate(mysqlDB); err != nil {
+		log.Fatalf("failed to migrate database: %s", err)
+	}
 	db := persistence.NewGormDBWrapper(mysqlDB)
 
 	repo := repositories.NewGormAlbumRepository(db)
@@ -48,6 +51,7 @@ func init() {
 
 func setupRouter() *gin.Engine {
 	r := gin.Default()
+	r.Use(Problems(logger.NewLogger()))
 
 	r.GET("/albums", albumHandler.GetAlbums)
 	r.GET("/albums/:id", albumHandler.GetAlbumByID)
@@ -93,7 +97,7 @@ func TestIntegrationAlbumHandlers(t *testing.T) {
 	t.Run("POST :: /albums endpoint", func(t *testing.T) {
 		r := setupRouter()
 
-		albumEntity := album.Album{Title: "Test Album", Artist: "Test Artist", Price: 9.99}
+		albumEntity := album.Album{Title: "Test Album", ArtistID: 1, Price: 9.99}
 		jsonValue, _ := json.Marshal(albumEntity)
 		req, _ := http.NewRequest("POST", "/albums", bytes.NewBuffer(jsonValue))
 		req.Header.Set("Content-Type", "application/json")
@@ -110,7 +114,7 @@ func TestIntegrationAlbumHandlers(t *testing.T) {
 	t.Run("PUT :: /albums/1 endpoint", func(t *testing.T) {
 		r := setupRouter()
 
-		albumEntity := album.Album{ID: 1, Title: "Updated Album", Artist: "Updated Artist", Price: 19.99}
+		albumEntity := album.Album{ID: 1, Title: "Updated Album", ArtistID: 1, Price: 19.99}
 		jsonValue, _ := json.Marshal(albumEntity)
 		req, _ := http.NewRequest("PUT", "/albums/1", bytes.NewBuffer(jsonValue))
 		req.Header.Set("Content-Type", "application/json")
@@ -6,9 +6,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/pkg/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -18,8 +20,8 @@ type MockAlbumService struct {
 	mock.Mock
 }
 
-func (m *MockAlbumService) GetAllAlbums() ([]domain.Album, error) {
-	args := m.Called()
+func (m *MockAlbumService) GetAllAlbums(filter domain.AlbumFilter) ([]domain.Album, error) {
+	args := m.Called(filter)
 	return args.Get(0).([]domain.Album), args.Error(1)
 }
 
@@ -28,6 +30,16 @@ func (m *MockAlbumService) GetAlbumByID(id int) (domain.Album, error) {
 	return args.Get(0).(domain.Album), args.Error(1)
 }
 
+func (m *MockAlbumService) GetAlbumPriceHistory(id int, query domain.PriceHistoryQuery) (domain.PriceHistory, error) {
+	args := m.Called(id, query)
+	return args.Get(0).(domain.PriceHistory), args.Error(1)
+}
+
+func (m *MockAlbumService) GetAlbumsByBarcode(code string) ([]domain.Album, error) {
+	args := m.Called(code)
+	return args.Get(0).([]domain.Album), args.Error(1)
+}
+
 func (m *MockAlbumService) CreateAlbum(album domain.Album) (domain.Album, error) {
 	args := m.Called(album)
 	return args.Get(0).(domain.Album), args.Error(1)
@@ -38,19 +50,91 @@ func (m *MockAlbumService) UpdateAlbum(album domain.Album) (domain.Album, error)
 	return args.Get(0).(domain.Album), args.Error(1)
 }
 
+func (m *MockAlbumService) GetAlbumTracks(id int) ([]domain.Track, error) {
+	args := m.Called(id)
+	return args.Get(0).([]domain.Track), args.Error(1)
+}
+
+func (m *MockAlbumService) ReplaceAlbumTracks(id int, tracks []domain.Track) ([]domain.Track, error) {
+	args := m.Called(id, tracks)
+	return args.Get(0).([]domain.Track), args.Error(1)
+}
+
+func (m *MockAlbumService) AddAlbumGenre(id int, genre string) (domain.Album, error) {
+	args := m.Called(id, genre)
+	return args.Get(0).(domain.Album), args.Error(1)
+}
+
+func (m *MockAlbumService) RemoveAlbumGenre(id int, genre string) (domain.Album, error) {
+	args := m.Called(id, genre)
+	return args.Get(0).(domain.Album), args.Error(1)
+}
+
+func (m *MockAlbumService) AddAlbumTag(id int, tag string) (domain.Album, error) {
+	args := m.Called(id, tag)
+	return args.Get(0).(domain.Album), args.Error(1)
+}
+
+func (m *MockAlbumService) RemoveAlbumTag(id int, tag string) (domain.Album, error) {
+	args := m.Called(id, tag)
+	return args.Get(0).(domain.Album), args.Error(1)
+}
+
+func (m *MockAlbumService) SubmitAlbum(id int) (domain.Album, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Album), args.Error(1)
+}
+
+func (m *MockAlbumService) ApproveAlbum(id int) (domain.Album, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Album), args.Error(1)
+}
+
+func (m *MockAlbumService) PublishAlbum(id int) (domain.Album, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Album), args.Error(1)
+}
+
+func (m *MockAlbumService) ArchiveAlbum(id int) (domain.Album, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Album), args.Error(1)
+}
+
 func (m *MockAlbumService) DeleteAlbum(id int) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
+// ExportAlbums passes the batches given to Return, followed by the error.
+func (m *MockAlbumService) ExportAlbums(filter domain.AlbumFilter, afterID uint, batch func(albums []domain.Album) error) error {
+	args := m.Called(filter, afterID)
+	for _, albums := range args.Get(0).([][]domain.Album) {
+		if err := batch(albums); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockAlbumService) ImportAlbums(rows []domain.ImportRow, dryRun bool) (domain.ImportReport, error) {
+	args := m.Called(rows, dryRun)
+	return args.Get(0).(domain.ImportReport), args.Error(1)
+}
+
 func setupTestRouter(service *MockAlbumService) *gin.Engine {
 	r := gin.Default()
+	r.Use(Problems(logger.NewLogger()))
 	handler := NewAlbumHandler(service)
 	r.GET("/albums", handler.GetAlbums)
 	r.GET("/albums/:id", handler.GetAlbumByID)
+	r.GET("/albums/by-barcode/:code", handler.GetAlbumsByBarcode)
 	r.POST("/albums", handler.CreateAlbum)
 	r.PUT("/albums/:id", handler.UpdateAlbum)
 	r.DELETE("/albums/:id", handler.DeleteAlbum)
+	r.GET("/albums/:id/tracks", handler.GetAlbumTracks)
+	r.PUT("/albums/:id/tracks", handler.ReplaceAlbumTracks)
+	r.POST("/albums/:id/tags/:tag", handler.AddAlbumTag)
+	r.DELETE("/albums/:id/tags/:tag", handler.RemoveAlbumTag)
 	return r
 }
 
@@ -59,8 +143,8 @@ func TestHandlers(t *testing.T) {
 	r := setupTestRouter(mockService)
 
 	t.Run("GET :: /albums endpoint", func(t *testing.T) {
-		albums := []domain.Album{{ID: 1, Title: "Test Album", Artist: "Test Artist", Price: 9.99}}
-		mockService.On("GetAllAlbums").Return(albums, nil)
+		albums := []domain.Album{{ID: 1, Title: "Test Album", ArtistID: 1, Price: 9.99, Status: domain.AlbumPublished}}
+		mockService.On("GetAllAlbums", domain.AlbumFilter{GenreMatch: domain.MatchAny, TagMatch: domain.MatchAny, Status: domain.AlbumPublished}).Return(albums, nil)
 
 		req, _ := http.NewRequest("GET", "/albums", nil)
 		w := httptest.NewRecorder()
@@ -75,7 +159,7 @@ func TestHandlers(t *testing.T) {
 	})
 
 	t.Run("GET :: /albums/:id endpoint", func(t *testing.T) {
-		album := domain.Album{ID: 1, Title: "Test Album", Artist: "Test Artist", Price: 9.99}
+		album := domain.Album{ID: 1, Title: "Test Album", ArtistID: 1, Price: 9.99, Status: domain.AlbumPublished}
 		mockService.On("GetAlbumByID", 1).Return(album, nil)
 
 		req, _ := http.NewRequest("GET", "/albums/1", nil)
@@ -91,8 +175,8 @@ func TestHandlers(t *testing.T) {
 	})
 
 	t.Run("POST :: /albums endpoint", func(t *testing.T) {
-		album := domain.Album{Title: "Test Album", Artist: "Test Artist", Price: 9.99}
-		createdAlbum := domain.Album{ID: 1, Title: "Test Album", Artist: "Test Artist", Price: 9.99}
+		album := domain.Album{Title: "Test Album", ArtistID: 1, Price: 9.99}
+		createdAlbum := domain.Album{ID: 1, Title: "Test Album", ArtistID: 1, Price: 9.99}
 		mockService.On("CreateAlbum", album).Return(createdAlbum, nil)
 
 		jsonValue, _ := json.Marshal(album)
@@ -110,7 +194,7 @@ func TestHandlers(t *testing.T) {
 	})
 
 	t.Run("PUT :: /albums/:id endpoint", func(t *testing.T) {
-		album := domain.Album{ID: 1, Title: "Updated Album", Artist: "Updated Artist", Price: 19.99}
+		album := domain.Album{ID: 1, Title: "Updated Album", ArtistID: 1, Price: 19.99}
 		mockService.On("UpdateAlbum", album).Return(album, nil)
 
 		jsonValue, _ := json.Marshal(album)
@@ -137,4 +221,395 @@ func TestHandlers(t *testing.T) {
 		assert.Equal(t, http.StatusNoContent, w.Code)
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("PUT :: /albums/:id/tracks endpoint", func(t *testing.T) {
+		tracks := []domain.Track{{Position: 1, Title: "Come Together", Duration: 259, ISRC: "GBAYE0601690"}}
+		mockService.On("ReplaceAlbumTracks", 1, tracks).Return(tracks, nil)
+
+		jsonValue, _ := json.Marshal(tracks)
+		req, _ := http.NewRequest("PUT", "/albums/1/tracks", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var responseTracks []domain.Track
+		err := json.Unmarshal(w.Body.Bytes(), &responseTracks)
+		assert.Nil(t, err)
+		assert.Equal(t, tracks, responseTracks)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("PUT :: /albums/:id/tracks endpoint with invalid tracklist", func(t *testing.T) {
+		tracks := []domain.Track{{Position: 0, Title: "Something"}}
+		mockService.On("ReplaceAlbumTracks", 2, tracks).Return([]domain.Track(nil), domain.ErrInvalidTrack)
+
+		jsonValue, _ := json.Marshal(tracks)
+		req, _ := http.NewRequest("PUT", "/albums/2/tracks", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("PUT :: /albums/:id/tracks endpoint for a missing album", func(t *testing.T) {
+		tracks := []domain.Track{{Position: 1, Title: "Something"}}
+		mockService.On("ReplaceAlbumTracks", 404, tracks).Return([]domain.Track(nil), domain.ErrNotFound)
+
+		jsonValue, _ := json.Marshal(tracks)
+		req, _ := http.NewRequest("PUT", "/albums/404/tracks", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("GET :: /albums?genre=&tag= endpoint", func(t *testing.T) {
+		filter := domain.AlbumFilter{
+			Genres:     []string{"Rock", "Jazz"},
+			GenreMatch: domain.MatchAny,
+			Tags:       []string{"live", "remastered"},
+			TagMatch:   domain.MatchAll,
+			Status:     domain.AlbumPublished,
+		}
+		mockService.On("GetAllAlbums", filter).Return([]domain.Album{}, nil)
+
+		req, _ := http.NewRequest("GET", "/albums?genre=Rock,Jazz&tag=live&tag=remastered&tag_match=all", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("GET :: /albums?tag_match= endpoint with invalid mode", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/albums?tag=live&tag_match=some", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("GET :: /albums?released_after= endpoint", func(t *testing.T) {
+		filter := domain.AlbumFilter{GenreMatch: domain.MatchAny, TagMatch: domain.MatchAny, ReleasedAfter: domain.NewDate(2024, time.January, 1), Status: domain.AlbumPublished}
+		mockService.On("GetAllAlbums", filter).Return([]domain.Album{}, nil)
+
+		req, _ := http.NewRequest("GET", "/albums?released_after=2024-01-01", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("GET :: /albums/by-barcode/:code endpoint", func(t *testing.T) {
+		albums := []domain.Album{{ID: 1, Title: "Test Album", Label: "Apple", Barcode: "4006381333931", ReleaseDate: domain.NewDate(1969, time.September, 26), Status: domain.AlbumPublished}}
+		mockService.On("GetAlbumsByBarcode", "4006381333931").Return(albums, nil)
+
+		req, _ := http.NewRequest("GET", "/albums/by-barcode/4006381333931", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var responseAlbums []domain.Album
+		err := json.Unmarshal(w.Body.Bytes(), &responseAlbums)
+		assert.Nil(t, err)
+		assert.Equal(t, albums, responseAlbums)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("POST :: /albums endpoint with duplicate barcode", func(t *testing.T) {
+		album := domain.Album{Title: "Other Album", Label: "Apple", Barcode: "4006381333931"}
+		mockService.On("CreateAlbum", album).Return(domain.Album{}, domain.ErrDuplicateBarcode)
+
+		jsonValue, _ := json.Marshal(album)
+		req, _ := http.NewRequest("POST", "/albums", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("POST :: /albums/:id/tags/:tag endpoint", func(t *testing.T) {
+		album := domain.Album{ID: 1, Title: "Test Album", Tags: []domain.Tag{{ID: 1, Name: "live"}}}
+		mockService.On("AddAlbumTag", 1, "live").Return(album, nil)
+
+		req, _ := http.NewRequest("POST", "/albums/1/tags/live", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var responseAlbum domain.Album
+		err := json.Unmarshal(w.Body.Bytes(), &responseAlbum)
+		assert.Nil(t, err)
+		assert.Equal(t, album, responseAlbum)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("DELETE :: /albums/:id/tags/:tag endpoint", func(t *testing.T) {
+		mockService.On("RemoveAlbumTag", 1, "live").Return(domain.Album{ID: 1, Title: "Test Album"}, nil)
+
+		req, _ := http.NewRequest("DELETE", "/albums/1/tags/live", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+// MockExchangeRateProvider is a mock implementation of the ExchangeRateProvider port
+type MockExchangeRateProvider struct {
+	mock.Mock
+}
+
+func (m *MockExchangeRateProvider) Rate(from, to string) (domain.ExchangeRate, error) {
+	args := m.Called(from, to)
+	return args.Get(0).(domain.ExchangeRate), args.Error(1)
+}
+
+func TestCurrencyConversion(t *testing.T) {
+	mockService := new(MockAlbumService)
+	mockRates := new(MockExchangeRateProvider)
+	r := gin.Default()
+	r.Use(Problems(logger.NewLogger()))
+	handler := NewAlbumHandler(mockService, WithExchangeRates(mockRates))
+	r.GET("/albums", handler.GetAlbums)
+	r.GET("/albums/:id", handler.GetAlbumByID)
+
+	rateDate := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	mockRates.On("Rate", "USD", "EUR").Return(domain.ExchangeRate{From: "USD", To: "EUR", Rate: 0.5, Date: rateDate}, nil)
+	mockRates.On("Rate", "USD", "XXX").Return(domain.ExchangeRate{}, domain.ErrUnsupportedCurrency)
+
+	t.Run("GET :: /albums/:id?currency=EUR endpoint", func(t *testing.T) {
+		mockService.On("GetAlbumByID", 1).Return(domain.Album{ID: 1, Title: "Test Album", ArtistID: 1, Price: 9.99, Status: domain.AlbumPublished}, nil)
+
+		req, _ := http.NewRequest("GET", "/albums/1?currency=EUR", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Nil(t, err)
+		assert.Equal(t, 5.0, response["price"])
+		assert.Equal(t, "EUR", response["currency"])
+		assert.Equal(t, "2025-01-31", response["rate_date"])
+	})
+
+	t.Run("GET :: /albums?currency=XXX endpoint", func(t *testing.T) {
+		mockService.On("GetAllAlbums", mock.Anything).Return([]domain.Album{{ID: 1, Price: 9.99, Status: domain.AlbumPublished}}, nil)
+
+		req, _ := http.NewRequest("GET", "/albums?currency=XXX", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+// MockPricingService is a mock implementation of the PricingService interface (contained in the Promotion domain)
+type MockPricingService struct {
+	mock.Mock
+}
+
+func (m *MockPricingService) QuoteAlbums(albums []domain.Album, request domain.PriceRequest) ([]domain.PriceQuote, error) {
+	args := m.Called(albums, request)
+	return args.Get(0).([]domain.PriceQuote), args.Error(1)
+}
+
+func TestEffectivePrice(t *testing.T) {
+	mockService := new(MockAlbumService)
+	mockPricing := new(MockPricingService)
+	r := gin.Default()
+	r.Use(Problems(logger.NewLogger()))
+	handler := NewAlbumHandler(mockService, WithPricing(mockPricing))
+	r.GET("/albums/:id", handler.GetAlbumByID)
+
+	t.Run("GET :: /albums/:id?coupon= endpoint", func(t *testing.T) {
+		album := domain.Album{ID: 1, Title: "Test Album", ArtistID: 1, Price: 20, Status: domain.AlbumPublished}
+		discount := domain.AppliedDiscount{PromotionID: 3, Name: "Coupon", Amount: 5, Explanation: "Coupon: 5.00 off each unit on all albums with coupon SAVE5"}
+		mockService.On("GetAlbumByID", 1).Return(album, nil)
+		mockPricing.On("QuoteAlbums", []domain.Album{album}, domain.PriceRequest{Quantity: 1, CouponCode: "SAVE5"}).
+			Return([]domain.PriceQuote{{AlbumID: 1, Quantity: 1, ListPrice: 20, EffectivePrice: 15, Total: 15, Discounts: []domain.AppliedDiscount{discount}}}, nil)
+
+		req, _ := http.NewRequest("GET", "/albums/1?coupon=SAVE5", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response albumResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Nil(t, err)
+		assert.Equal(t, 20.0, response.Price)
+		assert.Equal(t, 15.0, *response.EffectivePrice)
+		assert.Equal(t, []domain.AppliedDiscount{discount}, response.Discounts)
+		mockPricing.AssertExpectations(t)
+	})
+}
+
+func TestPriceHistory(t *testing.T) {
+	mockService := new(MockAlbumService)
+	r := gin.Default()
+	r.Use(Problems(logger.NewLogger()))
+	handler := NewAlbumHandler(mockService)
+	r.GET("/albums/:id/prices", handler.GetAlbumPriceHistory)
+
+	t.Run("GET :: /albums/:id/prices endpoint", func(t *testing.T) {
+		query := domain.PriceHistoryQuery{
+			From:   time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+			To:     time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+			Bucket: domain.BucketWeek,
+		}
+		low := 8.99
+		history := domain.PriceHistory{AlbumID: 1, From: query.From, To: query.To, Changes: []domain.PriceChange{}, Lowest30Days: &low}
+		mockService.On("GetAlbumByID", 1).Return(domain.Album{ID: 1, Status: domain.AlbumPublished}, nil)
+		mockService.On("GetAlbumPriceHistory", 1, query).Return(history, nil)
+
+		req, _ := http.NewRequest("GET", "/albums/1/prices?from=2025-05-01&to=2025-05-31&bucket=week", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response domain.PriceHistory
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Nil(t, err)
+		assert.Equal(t, 8.99, *response.Lowest30Days)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("GET :: /albums/:id/prices endpoint rejects invalid range", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/albums/1/prices?from=last-week", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestAlbumWorkflow(t *testing.T) {
+	mockService := new(MockAlbumService)
+	r := gin.Default()
+	r.Use(Problems(logger.NewLogger()))
+	r.Use(IdentifyEditor("secret"))
+	handler := NewAlbumHandler(mockService)
+	r.GET("/albums", handler.GetAlbums)
+	r.GET("/albums/:id", handler.GetAlbumByID)
+	r.GET("/albums/:id/tracks", handler.GetAlbumTracks)
+	r.GET("/albums/:id/prices", handler.GetAlbumPriceHistory)
+	r.POST("/albums/:id/submit", handler.SubmitAlbum)
+	r.POST("/albums/:id/publish", RequireEditor, handler.PublishAlbum)
+
+	draft := domain.Album{ID: 2, Title: "Draft Album", Status: domain.AlbumDraft}
+
+	t.Run("GET :: /albums endpoint shows editors every status", func(t *testing.T) {
+		filter := domain.AlbumFilter{GenreMatch: domain.MatchAny, TagMatch: domain.MatchAny, Status: domain.AlbumDraft}
+		mockService.On("GetAllAlbums", filter).Return([]domain.Album{draft}, nil)
+
+		req, _ := http.NewRequest("GET", "/albums?status=draft", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("GET :: /albums/:id endpoint hides unpublished albums", func(t *testing.T) {
+		mockService.On("GetAlbumByID", 2).Return(draft, nil)
+
+		req, _ := http.NewRequest("GET", "/albums/2", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		req, _ = http.NewRequest("GET", "/albums/2", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("GET :: /albums/:id/tracks endpoint hides unpublished albums", func(t *testing.T) {
+		tracks := []domain.Track{{Position: 1, Title: "Demo"}}
+		mockService.On("GetAlbumByID", 2).Return(draft, nil)
+		mockService.On("GetAlbumTracks", 2).Return(tracks, nil).Once()
+
+		req, _ := http.NewRequest("GET", "/albums/2/tracks", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		req, _ = http.NewRequest("GET", "/albums/2/tracks", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("GET :: /albums/:id/prices endpoint hides unpublished albums", func(t *testing.T) {
+		query := domain.PriceHistoryQuery{
+			From: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		}
+		history := domain.PriceHistory{AlbumID: 2, From: query.From, To: query.To, Changes: []domain.PriceChange{}}
+		mockService.On("GetAlbumByID", 2).Return(draft, nil)
+		mockService.On("GetAlbumPriceHistory", 2, query).Return(history, nil).Once()
+
+		req, _ := http.NewRequest("GET", "/albums/2/prices?from=2025-05-01&to=2025-05-31", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		req, _ = http.NewRequest("GET", "/albums/2/prices?from=2025-05-01&to=2025-05-31", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("POST :: /albums/:id/submit endpoint", func(t *testing.T) {
+		mockService.On("SubmitAlbum", 2).Return(domain.Album{ID: 2, Status: domain.AlbumReview}, nil)
+		mockService.On("SubmitAlbum", 3).Return(domain.Album{}, domain.ErrInvalidAlbumTransition)
+
+		req, _ := http.NewRequest("POST", "/albums/2/submit", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		req, _ = http.NewRequest("POST", "/albums/3/submit", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("POST :: /albums/:id/publish endpoint requires an editor", func(t *testing.T) {
+		mockService.On("PublishAlbum", 2).Return(domain.Album{ID: 2, Status: domain.AlbumPublished}, nil)
+
+		req, _ := http.NewRequest("POST", "/albums/2/publish", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		req, _ = http.NewRequest("POST", "/albums/2/publish", nil)
+		req.Header.Set("Authorization", "Bearer wrong")
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		req, _ = http.NewRequest("POST", "/albums/2/publish", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
 }
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Largest accepted import body.
+const maxImportSize = 10 << 20
+
+// Media type of JSON lines, accepted next to ndjsonContentType.
+const jsonLinesContentType = "application/jsonl"
+
+// Fields an import row may set, read from the column of the same name unless ?map[field]=
+// names another. Prices are in the base currency, a currency column may only confirm it.
+var importFields = []string{"title", "artist", "price", "currency", "release_date", "label", "catalog_number", "format", "barcode"}
+
+// ImportAlbums creates and updates albums from CSV with a header row or JSON lines, matching
+// stored albums by label and barcode. Empty cells keep the stored value. With ?dry_run=true
+// only the per row report is returned, otherwise nothing is saved when a row is rejected and
+// the report comes with 422.
+func (h *AlbumHandler) ImportAlbums(c *gin.Context) {
+	params, ok := importParams(c)
+	if !ok {
+		return
+	}
+	rows, err := readImport(params, http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		abortWithError(c, http.StatusRequestEntityTooLarge, fmt.Errorf("import must not exceed %d MiB", maxImportSize>>20))
+		return
+	}
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := h.service.ImportAlbums(rows, params.DryRun)
+	if errors.Is(err, domain.ErrImportRejected) {
+		abortWithErrorData(c, http.StatusUnprocessableEntity, err, gin.H{"report": report})
+		return
+	}
+	if errors.Is(err, domain.ErrDuplicateBarcode) {
+		abortWithError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// importParams reads ?dry_run=, ?map[field]= and the body media type of an import, writing an
+// error response and returning false when they are invalid.
+func importParams(c *gin.Context) (domain.ImportParams, bool) {
+	params := domain.ImportParams{ContentType: c.ContentType()}
+	var err error
+	if params.DryRun, err = strconv.ParseBool(c.DefaultQuery("dry_run", "false")); err != nil {
+		abortWithError(c, http.StatusBadRequest, errors.New("dry_run must be true or false"))
+		return params, false
+	}
+	if params.Mapping, err = importMapping(c.QueryMap("map")); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return params, false
+	}
+	switch params.ContentType {
+	case "text/csv", ndjsonContentType, jsonLinesContentType:
+	default:
+		abortWithError(c, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported media type %q, imports are accepted as text/csv or %s", params.ContentType, ndjsonContentType))
+		return params, false
+	}
+	return params, true
+}
+
+// readImport reads the rows of an import body in the media type of params.
+func readImport(params domain.ImportParams, body io.Reader) ([]domain.ImportRow, error) {
+	if params.ContentType == "text/csv" {
+		return readCSVImport(body, params.Mapping)
+	}
+	return readJSONLinesImport(body, params.Mapping)
+}
+
+// importMapping returns the column of every import field, columns match regardless of case.
+func importMapping(query map[string]string) (map[string]string, error) {
+	mapping := make(map[string]string, len(importFields))
+	for _, field := range importFields {
+		mapping[field] = field
+	}
+	for field, column := range query {
+		if _, ok := mapping[field]; !ok {
+			return nil, fmt.Errorf("unknown import field %q, expected one of %s", field, strings.Join(importFields, ", "))
+		}
+		mapping[field] = strings.ToLower(strings.TrimSpace(column))
+	}
+	return mapping, nil
+}
+
+// readCSVImport reads the rows following the header, numbered by their line.
+func readCSVImport(body io.Reader, mapping map[string]string) ([]domain.ImportRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("import is empty, a header row is expected")
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		// Spreadsheets commonly save CSV with a byte order mark
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for field, column := range mapping {
+			if column == name {
+				columns[field] = i
+			}
+		}
+	}
+	if _, ok := columns["barcode"]; !ok {
+		return nil, fmt.Errorf("column %q not found, albums are matched by label and barcode", mapping["barcode"])
+	}
+
+	var rows []domain.ImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		values := map[string]string{}
+		for field, i := range columns {
+			if i < len(record) && record[i] != "" {
+				values[field] = unescapeCSVText(record[i])
+			}
+		}
+		if len(values) > 0 {
+			rows = append(rows, importRow(line, values))
+		}
+	}
+}
+
+// readJSONLinesImport reads one JSON object per line, blank lines are skipped.
+func readJSONLinesImport(body io.Reader, mapping map[string]string) ([]domain.ImportRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxImportSize)
+	var rows []domain.ImportRow
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var object map[string]interface{}
+		if err := decoder.Decode(&object); err != nil {
+			rows = append(rows, domain.ImportRow{Row: line, Err: fmt.Errorf("%w: invalid JSON: %s", domain.ErrInvalidAlbum, err)})
+			continue
+		}
+		values, err := jsonLineValues(object, mapping)
+		if err != nil {
+			rows = append(rows, domain.ImportRow{Row: line, Err: err})
+			continue
+		}
+		rows = append(rows, importRow(line, values))
+	}
+	return rows, scanner.Err()
+}
+
+func jsonLineValues(object map[string]interface{}, mapping map[string]string) (map[string]string, error) {
+	values := map[string]string{}
+	for key, value := range object {
+		for field, column := range mapping {
+			if !strings.EqualFold(key, column) {
+				continue
+			}
+			switch value := value.(type) {
+			case string:
+				values[field] = value
+			case json.Number:
+				values[field] = value.String()
+			case nil:
+			default:
+				return nil, fmt.Errorf("%w: %s must be a string or number", domain.ErrInvalidAlbum, key)
+			}
+		}
+	}
+	return values, nil
+}
+
+// importRow reads the album fields of a row, values are keyed by import field.
+func importRow(line int, values map[string]string) domain.ImportRow {
+	row := domain.ImportRow{Row: line}
+	album := &row.Album
+	for _, field := range importFields {
+		value := strings.TrimSpace(values[field])
+		if value == "" {
+			continue
+		}
+		var err error
+		switch field {
+		case "title":
+			album.Title = value
+		case "artist":
+			album.Artist = &domain.Artist{Name: value}
+		case "price":
+			album.Price, err = strconv.ParseFloat(value, 64)
+			if err != nil || math.IsNaN(album.Price) || math.IsInf(album.Price, 0) {
+				err = fmt.Errorf("price must be a number, got %q", value)
+			}
+		case "currency":
+			if !strings.EqualFold(value, domain.BaseCurrency) {
+				err = fmt.Errorf("prices must be given in %s", domain.BaseCurrency)
+			}
+		case "release_date":
+			album.ReleaseDate, err = domain.ParseDate(value)
+		case "label":
+			album.Label = value
+		case "catalog_number":
+			album.CatalogNumber = value
+		case "format":
+			album.Format = domain.AlbumFormat(value)
+		case "barcode":
+			album.Barcode = value
+		}
+		if err != nil {
+			row.Err = fmt.Errorf("%w: %s", domain.ErrInvalidAlbum, err)
+			return row
+		}
+		if field != "currency" {
+			row.Fields = append(row.Fields, field)
+		}
+	}
+	return row
+}
+
+// unescapeCSVText reverses csvText, so exported files can be imported again.
+func unescapeCSVText(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestImportAlbums(t *testing.T) {
+	mockService := new(MockAlbumService)
+	r := gin.New()
+	r.Use(Problems(logger.NewLogger()), ValidateRequests(AlbumAPISpec(), true))
+	handler := NewAlbumHandler(mockService)
+	r.POST("/v1/albums/import", handler.ImportAlbums)
+
+	post := func(path, contentType, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	report := domain.ImportReport{DryRun: true, Created: 1, Rows: []domain.ImportRowResult{{Row: 2, Action: domain.ImportCreated}}}
+
+	t.Run("POST :: /v1/albums/import endpoint reads CSV rows through the column mapping", func(t *testing.T) {
+		var rows []domain.ImportRow
+		mockService.On("ImportAlbums", mock.Anything, true).Run(func(args mock.Arguments) {
+			rows = args.Get(0).([]domain.ImportRow)
+		}).Return(report, nil).Once()
+
+		w := post("/v1/albums/import?dry_run=true&map[title]=Name&map[barcode]=EAN", "text/csv",
+			"\ufeffName,artist,price,currency,release_date,label,EAN,ignored\n"+
+				"Abbey Road,The Beatles,9.99,USD,1969-09-26,Apple,5099969945120,x\n"+
+				",,,,,,,\n"+
+				"'=SUM(A1),,10,,,Apple,0602547202437,\n")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"dry_run":true,"created":1,"updated":0,"rejected":0,"rows":[{"row":2,"action":"created"}]}`, w.Body.String())
+		assert.Equal(t, []domain.ImportRow{
+			{Row: 2, Album: domain.Album{
+				Title: "Abbey Road", Artist: &domain.Artist{Name: "The Beatles"}, Price: 9.99,
+				ReleaseDate: domain.NewDate(1969, time.September, 26), Label: "Apple", Barcode: "5099969945120",
+			}, Fields: []string{"title", "artist", "price", "release_date", "label", "barcode"}},
+			{Row: 4, Album: domain.Album{Title: "=SUM(A1)", Price: 10, Label: "Apple", Barcode: "0602547202437"},
+				Fields: []string{"title", "price", "label", "barcode"}},
+		}, rows)
+	})
+
+	t.Run("POST :: /v1/albums/import endpoint reads JSON lines and reports unreadable ones per row", func(t *testing.T) {
+		var rows []domain.ImportRow
+		mockService.On("ImportAlbums", mock.Anything, false).Run(func(args mock.Arguments) {
+			rows = args.Get(0).([]domain.ImportRow)
+		}).Return(domain.ImportReport{}, nil).Once()
+
+		w := post("/v1/albums/import", ndjsonContentType,
+			`{"title":"Abbey Road","price":9.99,"barcode":"5099969945120","catalog_number":null}`+"\n\n"+
+				`{"title":`+"\n"+
+				`{"barcode":"0602547202437","currency":"EUR"}`+"\n")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Len(t, rows, 3)
+		assert.Equal(t, domain.Album{Title: "Abbey Road", Price: 9.99, Barcode: "5099969945120"}, rows[0].Album)
+		assert.Equal(t, []string{"title", "price", "barcode"}, rows[0].Fields)
+		assert.Equal(t, 3, rows[1].Row)
+		assert.ErrorIs(t, rows[1].Err, domain.ErrInvalidAlbum)
+		assert.Equal(t, 4, rows[2].Row)
+		assert.ErrorContains(t, rows[2].Err, "prices must be given in USD")
+	})
+
+	t.Run("POST :: /v1/albums/import endpoint rejects prices that are not finite numbers", func(t *testing.T) {
+		var rows []domain.ImportRow
+		mockService.On("ImportAlbums", mock.Anything, true).Run(func(args mock.Arguments) {
+			rows = args.Get(0).([]domain.ImportRow)
+		}).Return(domain.ImportReport{}, nil).Once()
+
+		w := post("/v1/albums/import?dry_run=true", "text/csv",
+			"barcode,price\n5099969945120,NaN\n5099969945121,Inf\n5099969945122,-infinity\n5099969945123,1e400\n")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		if assert.Len(t, rows, 4) {
+			for _, row := range rows {
+				assert.ErrorIs(t, row.Err, domain.ErrInvalidAlbum)
+				assert.ErrorContains(t, row.Err, "price must be a number")
+			}
+		}
+	})
+
+	t.Run("POST :: /v1/albums/import endpoint answers rejected imports with 422 and the report", func(t *testing.T) {
+		rejected := domain.ImportReport{Rejected: 1, Rows: []domain.ImportRowResult{{Row: 2, Action: domain.ImportRejected, Error: "invalid album: title is required"}}}
+		mockService.On("ImportAlbums", mock.Anything, false).Return(rejected, domain.ErrImportRejected).Once()
+
+		w := post("/v1/albums/import", "text/csv", "barcode\n5099969945120\n")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `"report":{"dry_run":false,"created":0,"updated":0,"rejected":1,"rows":[{"row":2,"action":"rejected","error":"invalid album: title is required"}]}`)
+	})
+
+	t.Run("POST :: /v1/albums/import endpoint rejects unreadable imports", func(t *testing.T) {
+		w := post("/v1/albums/import", "application/json", `[]`)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+		w = post("/v1/albums/import", "text/csv", "title,price\nAbbey Road,9.99\n")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, decodeProblem(t, w).Detail, `column "barcode" not found`)
+
+		w = post("/v1/albums/import?map[year]=released", "text/csv", "barcode\n5099969945120\n")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, decodeProblem(t, w).Detail, `unknown import field "year"`)
+
+		w = post("/v1/albums/import", "text/csv", "barcode,title\n5099969945120,\"Abbey\n")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("POST :: /v1/albums/import endpoint answers concurrent duplicates with 409", func(t *testing.T) {
+		mockService.On("ImportAlbums", mock.Anything, false).Return(domain.ImportReport{}, domain.ErrDuplicateBarcode).Once()
+
+		w := post("/v1/albums/import", "text/csv", "barcode\n5099969945120\n")
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("POST :: /v1/albums/import endpoint answers service failures with 500", func(t *testing.T) {
+		mockService.On("ImportAlbums", mock.Anything, false).Return(domain.ImportReport{}, errors.New("database is down")).Once()
+
+		w := post("/v1/albums/import", "text/csv", "barcode\n5099969945120\n")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Handles album stock HTTP requests.
+type InventoryHandler struct {
+	service domain.InventoryService
+}
+
+func NewInventoryHandler(service domain.InventoryService) *InventoryHandler {
+	return &InventoryHandler{service: service}
+}
+
+// Body of PUT /albums/:id/stock/:warehouse
+type stockRequest struct {
+	OnHand            int `json:"on_hand"`
+	LowStockThreshold int `json:"low_stock_threshold"`
+}
+
+// Body of POST /albums/:id/stock/reservations, an empty warehouse picks the best stocked one.
+type reservationRequest struct {
+	Warehouse string `json:"warehouse"`
+	Quantity  int    `json:"quantity" binding:"required"`
+}
+
+func (h *InventoryHandler) GetStock(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	stock, err := h.service.GetStock(id)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, stock)
+}
+
+func (h *InventoryHandler) SetStock(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	var request stockRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	level, err := h.service.SetStock(domain.StockLevel{
+		AlbumID:           uint(id),
+		Warehouse:         c.Param("warehouse"),
+		OnHand:            request.OnHand,
+		LowStockThreshold: request.LowStockThreshold,
+	})
+	if err != nil {
+		abortWithError(c, stockErrorStatus(err), err)
+		return
+	}
+	c.JSON(http.StatusOK, level)
+}
+
+func (h *InventoryHandler) Reserve(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	var request reservationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	reservation, err := h.service.Reserve(id, request.Warehouse, request.Quantity)
+	if err != nil {
+		abortWithError(c, stockErrorStatus(err), err)
+		return
+	}
+	c.JSON(http.StatusCreated, reservation)
+}
+
+func (h *InventoryHandler) ReleaseReservation(c *gin.Context) {
+	h.settleReservation(c, h.service.ReleaseReservation)
+}
+
+func (h *InventoryHandler) CommitReservation(c *gin.Context) {
+	h.settleReservation(c, h.service.CommitReservation)
+}
+
+func (h *InventoryHandler) settleReservation(c *gin.Context, settle func(albumID int, reservationID int) (domain.Reservation, error)) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	reservationID, err := strconv.Atoi(c.Param("reservation"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	reservation, err := settle(id, reservationID)
+	if err != nil {
+		abortWithError(c, stockErrorStatus(err), err)
+		return
+	}
+	c.JSON(http.StatusOK, reservation)
+}
+
+// stockErrorStatus maps inventory errors to a response status, missing records are reported by abortWithError.
+func stockErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidQuantity):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrInsufficientStock),
+		errors.Is(err, domain.ErrReservationNotActive),
+		errors.Is(err, domain.ErrStockBelowReservation):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
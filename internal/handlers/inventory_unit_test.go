@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockInventoryService is a mock implementation of the InventoryService interface (contained in the Inventory domain)
+type MockInventoryService struct {
+	mock.Mock
+}
+
+func (m *MockInventoryService) GetStock(albumID int) (domain.StockSummary, error) {
+	args := m.Called(albumID)
+	return args.Get(0).(domain.StockSummary), args.Error(1)
+}
+
+func (m *MockInventoryService) SetStock(level domain.StockLevel) (domain.StockLevel, error) {
+	args := m.Called(level)
+	return args.Get(0).(domain.StockLevel), args.Error(1)
+}
+
+func (m *MockInventoryService) Reserve(albumID int, warehouse string, quantity int) (domain.Reservation, error) {
+	args := m.Called(albumID, warehouse, quantity)
+	return args.Get(0).(domain.Reservation), args.Error(1)
+}
+
+func (m *MockInventoryService) ReleaseReservation(albumID int, reservationID int) (domain.Reservation, error) {
+	args := m.Called(albumID, reservationID)
+	return args.Get(0).(domain.Reservation), args.Error(1)
+}
+
+func (m *MockInventoryService) CommitReservation(albumID int, reservationID int) (domain.Reservation, error) {
+	args := m.Called(albumID, reservationID)
+	return args.Get(0).(domain.Reservation), args.Error(1)
+}
+
+func setupInventoryTestRouter(service *MockInventoryService) *gin.Engine {
+	r := gin.Default()
+	r.Use(Problems(logger.NewLogger()), IdentifyEditor("secret"))
+	handler := NewInventoryHandler(service)
+	r.GET("/albums/:id/stock", handler.GetStock)
+	r.PUT("/albums/:id/stock/:warehouse", RequireEditor, handler.SetStock)
+	r.POST("/albums/:id/stock/reservations", RequireEditor, handler.Reserve)
+	r.POST("/albums/:id/stock/reservations/:reservation/release", RequireEditor, handler.ReleaseReservation)
+	r.POST("/albums/:id/stock/reservations/:reservation/commit", RequireEditor, handler.CommitReservation)
+	return r
+}
+
+func TestInventoryHandlers(t *testing.T) {
+	mockService := new(MockInventoryService)
+	r := setupInventoryTestRouter(mockService)
+
+	t.Run("PUT :: /albums/:id/stock/:warehouse endpoint", func(t *testing.T) {
+		level := domain.StockLevel{AlbumID: 1, Warehouse: "waw", OnHand: 10, LowStockThreshold: 2}
+		mockService.On("SetStock", level).Return(level, nil)
+
+		req, _ := http.NewRequest("PUT", "/albums/1/stock/waw", bytes.NewBufferString(`{"on_hand":10,"low_stock_threshold":2}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("POST :: /albums/:id/stock/reservations endpoint", func(t *testing.T) {
+		reservation := domain.Reservation{ID: 7, AlbumID: 1, Warehouse: "waw", Quantity: 2, Status: domain.ReservationActive}
+		mockService.On("Reserve", 1, "", 2).Return(reservation, nil)
+
+		req, _ := http.NewRequest("POST", "/albums/1/stock/reservations", bytes.NewBufferString(`{"quantity":2}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var responseReservation domain.Reservation
+		err := json.Unmarshal(w.Body.Bytes(), &responseReservation)
+		assert.Nil(t, err)
+		assert.Equal(t, reservation.ID, responseReservation.ID)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("POST :: /albums/:id/stock/reservations endpoint without stock", func(t *testing.T) {
+		mockService.On("Reserve", 2, "waw", 5).Return(domain.Reservation{}, domain.ErrInsufficientStock)
+
+		req, _ := http.NewRequest("POST", "/albums/2/stock/reservations", bytes.NewBufferString(`{"warehouse":"waw","quantity":5}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("POST :: /albums/:id/stock/reservations/:reservation/commit endpoint", func(t *testing.T) {
+		mockService.On("CommitReservation", 1, 7).Return(domain.Reservation{ID: 7, Status: domain.ReservationCommitted}, nil)
+
+		req, _ := http.NewRequest("POST", "/albums/1/stock/reservations/7/commit", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("PUT, POST :: stock and reservation changes require an editor", func(t *testing.T) {
+		for _, route := range [][2]string{
+			{"PUT", "/albums/1/stock/waw"},
+			{"POST", "/albums/1/stock/reservations"},
+			{"POST", "/albums/1/stock/reservations/7/release"},
+			{"POST", "/albums/1/stock/reservations/7/commit"},
+		} {
+			req, _ := http.NewRequest(route[0], route[1], nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+			req.Header.Set("Authorization", "Bearer wrong")
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusForbidden, w.Code)
+		}
+		mockService.AssertNotCalled(t, "ReleaseReservation", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Largest import accepted as a job, the file is kept in the blob store until the job finished.
+const maxImportJobSize = 64 << 20
+
+// Handles background job HTTP requests.
+type JobHandler struct {
+	service domain.JobService
+}
+
+func NewJobHandler(service domain.JobService) *JobHandler {
+	return &JobHandler{service: service}
+}
+
+// Job as returned by the job endpoints, with links to poll it, cancel it and download its result.
+type jobResponse struct {
+	domain.Job
+	Links jobLinks `json:"links"`
+}
+
+type jobLinks struct {
+	Self   string `json:"self"`
+	Cancel string `json:"cancel,omitempty"`
+	Result string `json:"result,omitempty"`
+}
+
+func newJobResponse(job domain.Job) jobResponse {
+	self := fmt.Sprintf("/v1/jobs/%d", job.ID)
+	response := jobResponse{Job: job, Links: jobLinks{Self: self}}
+	if !job.Finished() {
+		response.Links.Cancel = self + "/cancel"
+	}
+	if job.HasResult() {
+		response.Links.Result = self + "/result"
+	}
+	return response
+}
+
+// StartImportJob queues an import of the body, taking the parameters of ImportAlbums. A
+// rejected import fails the job, its report tells which rows to fix.
+func (h *JobHandler) StartImportJob(c *gin.Context) {
+	params, ok := importParams(c)
+	if !ok {
+		return
+	}
+	input, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportJobSize))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		abortWithError(c, http.StatusRequestEntityTooLarge, fmt.Errorf("import must not exceed %d MiB", maxImportJobSize>>20))
+		return
+	}
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	h.startJob(c, domain.JobImport, params, input)
+}
+
+// StartExportJob queues an export taking the parameters of ExportAlbums, except after_id.
+func (h *JobHandler) StartExportJob(c *gin.Context) {
+	params := domain.ExportParams{Format: c.DefaultQuery("format", exportNDJSON)}
+	if params.Format != exportNDJSON && params.Format != exportCSV {
+		abortWithError(c, http.StatusBadRequest, fmt.Errorf("unsupported export format %q, expected %s or %s", params.Format, exportNDJSON, exportCSV))
+		return
+	}
+	var err error
+	if params.Filter, err = albumFilterFromQuery(c); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	h.startJob(c, domain.JobExport, params, nil)
+}
+
+func (h *JobHandler) startJob(c *gin.Context, kind domain.JobKind, params interface{}, input []byte) {
+	job, err := h.service.StartJob(kind, params, input)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	response := newJobResponse(job)
+	c.Header("Location", response.Links.Self)
+	c.JSON(http.StatusAccepted, response)
+}
+
+// GetJob reports the status and progress of a job, poll it until the job finished.
+func (h *JobHandler) GetJob(c *gin.Context) {
+	job, ok := h.job(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, newJobResponse(job))
+}
+
+// CancelJob cancels a queued job, a running one stops at its next step.
+func (h *JobHandler) CancelJob(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	job, err := h.service.CancelJob(id)
+	if errors.Is(err, domain.ErrJobFinished) {
+		abortWithError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, newJobResponse(job))
+}
+
+// GetJobResult downloads the file written by a succeeded export job.
+func (h *JobHandler) GetJobResult(c *gin.Context) {
+	job, ok := h.job(c)
+	if !ok {
+		return
+	}
+	result, err := h.service.OpenJobResult(job)
+	if errors.Is(err, domain.ErrNoJobResult) {
+		// Unfinished jobs may still have one, imports and failed exports never will
+		status := http.StatusConflict
+		if job.Finished() {
+			status = http.StatusNotFound
+		}
+		abortWithError(c, status, fmt.Errorf("%w, the %s job is %s", err, job.Kind, job.Status))
+		return
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	defer result.Close()
+	extension := exportNDJSON
+	if job.ResultType != ndjsonContentType {
+		extension = exportCSV
+	}
+	c.DataFromReader(http.StatusOK, -1, job.ResultType, result, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="albums-%d.%s"`, job.ID, extension),
+	})
+}
+
+func (h *JobHandler) job(c *gin.Context) (domain.Job, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return domain.Job{}, false
+	}
+	job, err := h.service.GetJob(id)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return domain.Job{}, false
+	}
+	return job, true
+}
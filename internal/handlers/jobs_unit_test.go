@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockJobService is a mock implementation of the JobService interface (contained in the Job domain)
+type MockJobService struct {
+	mock.Mock
+}
+
+func (m *MockJobService) StartJob(kind domain.JobKind, params interface{}, input []byte) (domain.Job, error) {
+	args := m.Called(kind, params, input)
+	return args.Get(0).(domain.Job), args.Error(1)
+}
+
+func (m *MockJobService) GetJob(id int) (domain.Job, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Job), args.Error(1)
+}
+
+func (m *MockJobService) CancelJob(id int) (domain.Job, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Job), args.Error(1)
+}
+
+func (m *MockJobService) OpenJobResult(job domain.Job) (io.ReadCloser, error) {
+	args := m.Called(job)
+	result, _ := args.Get(0).(string)
+	return io.NopCloser(strings.NewReader(result)), args.Error(1)
+}
+
+// memoryBlobs is a blob store keeping blobs in a map.
+type memoryBlobs map[string][]byte
+
+func (b memoryBlobs) Put(key string, content []byte, contentType string) error {
+	b[key] = append([]byte(nil), content...)
+	return nil
+}
+
+func (b memoryBlobs) Open(key string) (io.ReadCloser, error) {
+	content, ok := b[key]
+	if !ok {
+		return nil, domain.ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (b memoryBlobs) Delete(key string) error {
+	delete(b, key)
+	return nil
+}
+
+func TestJobHandlers(t *testing.T) {
+	mockService := new(MockJobService)
+	r := gin.New()
+	r.Use(Problems(logger.NewLogger()))
+	handler := NewJobHandler(mockService)
+	r.POST("/v1/jobs/import", handler.StartImportJob)
+	r.POST("/v1/jobs/export", handler.StartExportJob)
+	r.GET("/v1/jobs/:id", handler.GetJob)
+	r.POST("/v1/jobs/:id/cancel", handler.CancelJob)
+	r.GET("/v1/jobs/:id/result", handler.GetJobResult)
+
+	send := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("POST :: /v1/jobs/import endpoint queues the upload and answers 202 with its location", func(t *testing.T) {
+		params := domain.ImportParams{ContentType: "text/csv", Mapping: map[string]string{
+			"title": "name", "artist": "artist", "price": "price", "currency": "currency", "release_date": "release_date",
+			"label": "label", "catalog_number": "catalog_number", "format": "format", "barcode": "barcode",
+		}, DryRun: true}
+		body := "name,barcode\nAbbey Road,5099969945120\n"
+		mockService.On("StartJob", domain.JobImport, params, []byte(body)).
+			Return(domain.Job{ID: 7, Kind: domain.JobImport, Status: domain.JobQueued}, nil).Once()
+
+		w := send("POST", "/v1/jobs/import?dry_run=true&map[title]=Name", "text/csv", body)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, "/v1/jobs/7", w.Header().Get("Location"))
+		assert.Contains(t, w.Body.String(), `"links":{"self":"/v1/jobs/7","cancel":"/v1/jobs/7/cancel"}`)
+
+		w = send("POST", "/v1/jobs/import", "application/json", "[]")
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("POST :: /v1/jobs/export endpoint queues an export of the filtered catalog", func(t *testing.T) {
+		params := domain.ExportParams{Format: exportCSV, Filter: domain.AlbumFilter{
+			Genres: []string{"Rock"}, GenreMatch: domain.MatchAny, TagMatch: domain.MatchAny, Status: domain.AlbumDraft,
+		}}
+		mockService.On("StartJob", domain.JobExport, params, []byte(nil)).
+			Return(domain.Job{ID: 8, Kind: domain.JobExport, Status: domain.JobQueued}, nil).Once()
+
+		w := send("POST", "/v1/jobs/export?format=csv&genre=Rock&status=draft", "", "")
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, "/v1/jobs/8", w.Header().Get("Location"))
+
+		w = send("POST", "/v1/jobs/export?format=xml", "", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("GET :: /v1/jobs/:id endpoint reports progress and links the result", func(t *testing.T) {
+		total := 4000
+		mockService.On("GetJob", 8).Return(domain.Job{ID: 8, Kind: domain.JobExport, Status: domain.JobSucceeded,
+			Processed: 4000, Total: &total, Parts: 2, ResultType: "text/csv; charset=utf-8"}, nil)
+		mockService.On("GetJob", 9).Return(domain.Job{}, domain.ErrNotFound)
+
+		w := send("GET", "/v1/jobs/8", "", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "succeeded", response["status"])
+		assert.Equal(t, 4000.0, response["processed"])
+		assert.Equal(t, map[string]interface{}{"self": "/v1/jobs/8", "result": "/v1/jobs/8/result"}, response["links"])
+
+		w = send("GET", "/v1/jobs/9", "", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("GET :: /v1/jobs/:id/result endpoint downloads the export", func(t *testing.T) {
+		mockService.On("OpenJobResult", mock.MatchedBy(func(job domain.Job) bool { return job.ID == 8 })).Return("id,title\n1,Abbey Road\n", nil)
+
+		w := send("GET", "/v1/jobs/8/result", "", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="albums-8.csv"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "id,title\n1,Abbey Road\n", w.Body.String())
+
+		running := domain.Job{ID: 10, Kind: domain.JobExport, Status: domain.JobRunning}
+		mockService.On("GetJob", 10).Return(running, nil)
+		mockService.On("OpenJobResult", running).Return(nil, domain.ErrNoJobResult)
+		w = send("GET", "/v1/jobs/10/result", "", "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("POST :: /v1/jobs/:id/cancel endpoint cancels unfinished jobs", func(t *testing.T) {
+		mockService.On("CancelJob", 7).Return(domain.Job{ID: 7, Status: domain.JobCancelled, CancelRequested: true}, nil).Once()
+		mockService.On("CancelJob", 8).Return(domain.Job{}, domain.ErrJobFinished).Once()
+
+		w := send("POST", "/v1/jobs/7/cancel", "", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"cancelled"`)
+
+		w = send("POST", "/v1/jobs/8/cancel", "", "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestAlbumFiles(t *testing.T) {
+	albums := []domain.Album{{ID: 1, Title: "Abbey Road", Status: domain.AlbumPublished}, {ID: 2, Title: "Revolver", Status: domain.AlbumPublished}}
+
+	t.Run("NewExportWriter :: writes CSV with the header of the first part only", func(t *testing.T) {
+		var first, second bytes.Buffer
+		writer, contentType := AlbumFiles{}.NewExportWriter(exportCSV, &first, true)
+		assert.Equal(t, "text/csv; charset=utf-8", contentType)
+		assert.Nil(t, writer.Write(albums[:1]))
+		assert.Nil(t, writer.Flush())
+		writer, _ = AlbumFiles{}.NewExportWriter(exportCSV, &second, false)
+		assert.Nil(t, writer.Write(albums[1:]))
+		assert.Nil(t, writer.Flush())
+
+		records, err := csv.NewReader(io.MultiReader(&first, &second)).ReadAll()
+		assert.Nil(t, err)
+		assert.Len(t, records, 3)
+		assert.Equal(t, albumCSVHeader, records[0])
+		assert.Equal(t, "Revolver", records[2][1])
+	})
+
+	t.Run("NewExportWriter :: writes JSON lines", func(t *testing.T) {
+		var part bytes.Buffer
+		writer, contentType := AlbumFiles{}.NewExportWriter(exportNDJSON, &part, true)
+		assert.Equal(t, ndjsonContentType, contentType)
+		assert.Nil(t, writer.Write(albums))
+		assert.Nil(t, writer.Flush())
+
+		lines := strings.Split(strings.TrimSpace(part.String()), "\n")
+		assert.Len(t, lines, 2)
+		var row domain.Album
+		assert.Nil(t, json.Unmarshal([]byte(lines[1]), &row))
+		assert.Equal(t, "Revolver", row.Title)
+	})
+
+	t.Run("ReadImport :: reads the rows of a CSV upload", func(t *testing.T) {
+		mapping, _ := importMapping(nil)
+		rows, err := AlbumFiles{}.ReadImport(domain.ImportParams{ContentType: "text/csv", Mapping: mapping}, strings.NewReader("barcode,title\n5099969945120,\n0602547202437,Revolver\n"))
+		assert.Nil(t, err)
+		assert.Len(t, rows, 2)
+		assert.Equal(t, "Revolver", rows[1].Album.Title)
+	})
+}
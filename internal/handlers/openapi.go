@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/openapi"
+)
+
+// Security requirement of editor only operations.
+var editorSecurity = []map[string][]string{{"editorToken": {}}}
+
+// AlbumAPISpec describes the routes registered by routers.RegisterAlbumHandlers.
+func AlbumAPISpec() *openapi.Document {
+	doc := openapi.New(openapi.Info{
+		Title:   "Album catalog API",
+		Version: "1.0.0",
+		Description: "Albums, their tracklists, cover art and price history. Only published albums are " +
+			"visible unless the request carries the editor token.",
+	})
+	doc.Components.SecuritySchemes = map[string]openapi.SecurityScheme{
+		"editorToken": {Type: "http", Scheme: "bearer", Description: "EDITOR_TOKEN of the deployment"},
+	}
+	// Empty dates are accepted in requests, YYYY-MM-DD is checked by pattern rather than format
+	doc.Define(domain.Date{}, &openapi.Schema{Type: openapi.Types{"string", "null"}, Pattern: `^(\d{4}-\d{2}-\d{2})?$`})
+	doc.Define(domain.AlbumStatus(""), &openapi.Schema{
+		Type: openapi.Types{"string"},
+		Enum: []interface{}{domain.AlbumDraft, domain.AlbumReview, domain.AlbumPublished, domain.AlbumArchived},
+	})
+	doc.Define(domain.PriceBucketSize(""), &openapi.Schema{
+		Type: openapi.Types{"string"},
+		Enum: []interface{}{domain.BucketNone, domain.BucketDay, domain.BucketWeek},
+	})
+
+	album, albumResponse := doc.Schema(domain.Album{}), doc.Schema(albumResponse{})
+	// Managed by the server, see Album.KeepManagedFields
+	for _, name := range []string{"status", "published_at", "average_rating", "review_count", "total_duration", "track_count"} {
+		doc.Resolve(album).Properties[name].ReadOnly = true
+	}
+	one := 1.0
+	albumID := pathParam("id", "Album ID")
+	formatParam := queryParam("format", "Representation, takes precedence over Accept: "+strings.Join(albumFormatNames(), ", "), openapi.Type("string"))
+	presentation := []openapi.Parameter{
+		queryParam("quantity", "Quantity the effective price is quoted for", &openapi.Schema{Type: openapi.Types{"integer"}, Minimum: &one}),
+		queryParam("coupon", "Coupon code applied to the effective price", openapi.Type("string")),
+		queryParam("currency", "Currency amounts are converted to", openapi.Type("string")),
+		formatParam,
+	}
+
+	filters := []openapi.Parameter{
+		listParam("genre", "Genre names"),
+		queryParam("genre_match", "Whether any or all genres must match", enum("any", "all")),
+		listParam("tag", "Tag names"),
+		queryParam("tag_match", "Whether any or all tags must match", enum("any", "all")),
+		queryParam("released_after", "Release date lower bound", &openapi.Schema{Type: openapi.Types{"string"}, Format: "date"}),
+		queryParam("status", "Workflow status, editors only", doc.Schema(domain.AlbumStatus(""))),
+		queryParam("in_stock", "Whether stock is available", openapi.Type("boolean")),
+	}
+
+	doc.Add(http.MethodGet, "/v1/albums", openapi.Operation{
+		OperationID: "listAlbums",
+		Summary:     "List albums",
+		Description: "Published albums, editors see every status and may filter by status. Genre and tag " +
+			"values may be repeated or comma separated.",
+		Tags:       []string{"albums"},
+		Parameters: append(append([]openapi.Parameter{}, filters...), presentation...),
+		Responses: negotiated(responses(http.StatusOK, "Albums", &openapi.Schema{Type: openapi.Types{"array"}, Items: albumResponse},
+			http.StatusBadRequest), http.StatusOK),
+	})
+	doc.Add(http.MethodGet, "/v1/albums/:id", openapi.Operation{
+		OperationID: "getAlbum",
+		Summary:     "Get an album",
+		Tags:        []string{"albums"},
+		Parameters:  append([]openapi.Parameter{albumID}, presentation...),
+		Responses:   negotiated(responses(http.StatusOK, "Album", albumResponse, http.StatusBadRequest, http.StatusNotFound), http.StatusOK),
+	})
+	doc.Add(http.MethodGet, "/v1/albums/by-barcode/:code", openapi.Operation{
+		OperationID: "getAlbumsByBarcode",
+		Summary:     "Find albums by barcode",
+		Tags:        []string{"albums"},
+		Parameters:  append([]openapi.Parameter{pathParam("code", "EAN-8, UPC-A or EAN-13 barcode")}, presentation...),
+		Responses: negotiated(responses(http.StatusOK, "Albums", &openapi.Schema{Type: openapi.Types{"array"}, Items: albumResponse},
+			http.StatusNotFound), http.StatusOK),
+	})
+	zero := 0.0
+	export := responses(http.StatusOK, "Albums in ID order", nil, http.StatusBadRequest, http.StatusNotAcceptable)
+	export["200"].Content = map[string]openapi.MediaType{
+		ndjsonContentType: {Schema: albumResponse},
+		"text/csv":        {Schema: openapi.Type("string")},
+	}
+	doc.Add(http.MethodGet, "/v1/albums/export", openapi.Operation{
+		OperationID: "exportAlbums",
+		Summary:     "Export the catalog",
+		Description: "Streams the albums matching the filters as newline delimited JSON, one album per line, or CSV. " +
+			"An interrupted export is resumed with after_id set to the last ID received, the connection is " +
+			"aborted when the export fails midway.",
+		Tags: []string{"albums"},
+		Parameters: append([]openapi.Parameter{
+			queryParam("format", "Export format, "+exportNDJSON+" by default or "+exportCSV, openapi.Type("string")),
+			queryParam("after_id", "Only albums with a greater ID", &openapi.Schema{Type: openapi.Types{"integer"}, Minimum: &zero}),
+		}, filters...),
+		Responses: export,
+	})
+	events := responses(http.StatusOK, "Album events", nil, http.StatusBadRequest, http.StatusNotImplemented)
+	events["200"].Content = map[string]openapi.MediaType{"text/event-stream": {Schema: openapi.Type("string")}}
+	doc.Add(http.MethodGet, "/v1/albums/events", openapi.Operation{
+		OperationID: "streamAlbumEvents",
+		Summary:     "Follow album changes",
+		Description: "Server-sent created, updated and deleted events, the data is JSON with the album as exported, " +
+			"left out of deleted events. Comments are sent as heartbeats. A client reconnecting with Last-Event-ID " +
+			"receives the events it missed, or a reset event when it should reload the albums. Clients falling " +
+			"behind are disconnected and resume the same way. Only published albums are followed unless the " +
+			"request carries the editor token, changes of albums no longer public, such as archived ones, only " +
+			"carry the event id, type, album_id and at. Events are not shared between replicas: the stream " +
+			"only carries changes made through the replica serving it, run a single replica when clients " +
+			"must see every change.",
+		Tags: []string{"albums"},
+		Parameters: []openapi.Parameter{
+			listParam("album_id", "Album IDs to follow"),
+			listParam("artist_id", "Artist IDs whose albums to follow"),
+			{Name: "Last-Event-ID", In: openapi.InHeader, Description: "ID of the last event received", Schema: openapi.Type("string")},
+			queryParam("last_event_id", "Last-Event-ID for clients unable to set headers", openapi.Type("string")),
+		},
+		Responses: events,
+	})
+	importResponses := responses(http.StatusOK, "Outcome of every row", doc.Schema(domain.ImportReport{}),
+		http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict,
+		http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity)
+	importResponses["409"].Description = "An album of the same label and barcode was saved concurrently, nothing was saved"
+	importResponses["422"].Description = "Rows were rejected and nothing was saved, the report is in the report member"
+	doc.Add(http.MethodPost, "/v1/albums/import", openapi.Operation{
+		OperationID: "importAlbums",
+		Summary:     "Import albums",
+		Description: "Creates and updates albums from CSV with a header row or JSON lines, albums are matched by " +
+			"label and barcode. Empty values keep the stored ones, new albums start as drafts. Nothing is saved " +
+			"unless every row is valid.",
+		Tags:     []string{"albums"},
+		Security: editorSecurity,
+		Parameters: []openapi.Parameter{
+			queryParam("dry_run", "Only report what the import would do", openapi.Type("boolean")),
+			{
+				Name: "map", In: openapi.InQuery, Style: "deepObject",
+				Description: "Column of a field when it differs from the field name, as map[field]=column. Fields: " +
+					strings.Join(importFields, ", "),
+				Schema: &openapi.Schema{Type: openapi.Types{"object"}, AdditionalProperties: openapi.Type("string")},
+			},
+		},
+		RequestBody: &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{
+			"text/csv":           {Schema: openapi.Type("string")},
+			ndjsonContentType:    {Schema: openapi.Type("string")},
+			jsonLinesContentType: {Schema: openapi.Type("string")},
+		}},
+		Responses: importResponses,
+	})
+	doc.Add(http.MethodPost, "/v1/albums", openapi.Operation{
+		OperationID: "createAlbum",
+		Summary:     "Create an album",
+		Description: "New albums start as drafts.",
+		Tags:        []string{"albums"},
+		Parameters:  []openapi.Parameter{formatParam},
+		RequestBody: albumBody(album),
+		Responses: negotiated(responses(http.StatusCreated, "Created album", album,
+			http.StatusBadRequest, http.StatusConflict, http.StatusUnsupportedMediaType), http.StatusCreated),
+	})
+	doc.Add(http.MethodPut, "/v1/albums", openapi.Operation{
+		OperationID: "updateAlbum",
+		Summary:     "Update an album",
+		Description: "The album is identified by its id, the workflow status, review aggregates and cover are kept.",
+		Tags:        []string{"albums"},
+		Parameters:  []openapi.Parameter{formatParam},
+		RequestBody: albumBody(album),
+		Responses: negotiated(responses(http.StatusOK, "Updated album", album,
+			http.StatusBadRequest, http.StatusConflict, http.StatusUnsupportedMediaType), http.StatusOK),
+	})
+	doc.Add(http.MethodDelete, "/v1/albums/:id", openapi.Operation{
+		OperationID: "deleteAlbum",
+		Summary:     "Delete an album",
+		Tags:        []string{"albums"},
+		Parameters:  []openapi.Parameter{albumID},
+		Responses:   responses(http.StatusNoContent, "Album deleted", nil, http.StatusBadRequest),
+	})
+
+	tracks := &openapi.Schema{Type: openapi.Types{"array"}, Items: doc.Schema(domain.Track{})}
+	doc.Add(http.MethodGet, "/v1/albums/:id/tracks", openapi.Operation{
+		OperationID: "getAlbumTracks",
+		Summary:     "Get the tracklist",
+		Tags:        []string{"tracks"},
+		Parameters:  []openapi.Parameter{albumID},
+		Responses:   responses(http.StatusOK, "Tracks in disc and position order", tracks, http.StatusBadRequest, http.StatusNotFound),
+	})
+	doc.Add(http.MethodPut, "/v1/albums/:id/tracks", openapi.Operation{
+		OperationID: "replaceAlbumTracks",
+		Summary:     "Replace the tracklist",
+		Description: "Tracks default to disc 1, every disc and position pair must be unique.",
+		Tags:        []string{"tracks"},
+		Parameters:  []openapi.Parameter{albumID},
+		RequestBody: jsonBody(tracks),
+		Responses:   responses(http.StatusOK, "Stored tracks", tracks, http.StatusBadRequest, http.StatusNotFound),
+	})
+
+	for _, transition := range []struct {
+		action, summary string
+		editor          bool
+	}{
+		{"submit", "Submit a draft for review", false},
+		{"approve", "Approve and publish an album in review", true},
+		{"publish", "Publish an album", true},
+		{"archive", "Archive an album", true},
+	} {
+		operation := openapi.Operation{
+			OperationID: transition.action + "Album",
+			Summary:     transition.summary,
+			Tags:        []string{"workflow"},
+			Parameters:  []openapi.Parameter{albumID},
+			Responses:   responses(http.StatusOK, "Album in its new status", album, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict),
+		}
+		if transition.editor {
+			operation.Security = editorSecurity
+			addProblems(operation.Responses, http.StatusUnauthorized, http.StatusForbidden)
+		}
+		doc.Add(http.MethodPost, "/v1/albums/:id/"+transition.action, operation)
+	}
+
+	sizes := make([]interface{}, 0, len(domain.CoverSizes))
+	for _, size := range domain.CoverSizes {
+		sizes = append(sizes, size)
+	}
+	cover := &openapi.Schema{Type: openapi.Types{"string"}, ContentMediaType: "application/octet-stream"}
+	coverResponse := &openapi.Response{
+		Description: "Cover image or thumbnail",
+		Headers: map[string]openapi.Header{
+			"ETag":          {Schema: openapi.Type("string")},
+			"Cache-Control": {Schema: openapi.Type("string")},
+		},
+		Content: map[string]openapi.MediaType{"image/jpeg": {Schema: cover}, "image/png": {Schema: cover}, "image/webp": {Schema: cover}},
+	}
+	getCover := responses(0, "", nil, http.StatusBadRequest, http.StatusNotFound, http.StatusNotImplemented)
+	getCover["200"], getCover["304"] = coverResponse, &openapi.Response{Description: "Cover unchanged"}
+	doc.Add(http.MethodGet, "/v1/albums/:id/cover", openapi.Operation{
+		OperationID: "getAlbumCover",
+		Summary:     "Download the cover",
+		Description: "Thumbnails are served in the cover's own format unless format is given. The original image " +
+			"is served until they are generated.",
+		Tags: []string{"covers"},
+		Parameters: []openapi.Parameter{
+			albumID,
+			queryParam("size", "Thumbnail size in pixels", &openapi.Schema{Type: openapi.Types{"integer"}, Enum: sizes}),
+			queryParam("format", "Thumbnail format, requires size", enum("jpeg", "png")),
+			queryParam("v", "Cover version, versioned URLs are cacheable", openapi.Type("string")),
+		},
+		Responses: getCover,
+	})
+	doc.Add(http.MethodPut, "/v1/albums/:id/cover", openapi.Operation{
+		OperationID: "uploadAlbumCover",
+		Summary:     "Upload the cover",
+		Description: "JPEG, PNG or WebP image of up to 10 MiB, 8000 pixels wide or high and 24 million pixels.",
+		Tags:        []string{"covers"},
+		Security:    editorSecurity,
+		Parameters:  []openapi.Parameter{albumID},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content: map[string]openapi.MediaType{"multipart/form-data": {Schema: &openapi.Schema{
+				Type:       openapi.Types{"object"},
+				Properties: map[string]*openapi.Schema{"file": cover},
+				Required:   []string{"file"},
+			}}},
+		},
+		Responses: responses(http.StatusOK, "Album with its new cover", albumResponse,
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType, http.StatusNotImplemented),
+	})
+
+	doc.Add(http.MethodGet, "/v1/albums/:id/prices", openapi.Operation{
+		OperationID: "getAlbumPriceHistory",
+		Summary:     "Get the price history",
+		Description: "Price changes of the range, 90 days before to by default, with aggregates. lowest_30_days is " +
+			"the EU Omnibus reference price.",
+		Tags: []string{"prices"},
+		Parameters: []openapi.Parameter{
+			albumID,
+			queryParam("from", "RFC 3339 timestamp or date", openapi.Type("string")),
+			queryParam("to", "RFC 3339 timestamp or date, a date includes the whole day", openapi.Type("string")),
+			queryParam("bucket", "Aggregation period", doc.Schema(domain.PriceBucketSize(""))),
+		},
+		Responses: responses(http.StatusOK, "Price history", doc.Schema(domain.PriceHistory{}), http.StatusBadRequest, http.StatusNotFound),
+	})
+
+	for _, label := range []struct{ name, param string }{{"Genre", "genre"}, {"Tag", "tag"}} {
+		route := "/v1/albums/:id/" + label.param + "s/:" + label.param
+		params := []openapi.Parameter{albumID, pathParam(label.param, label.name+" name")}
+		doc.Add(http.MethodPost, route, openapi.Operation{
+			OperationID: "addAlbum" + label.name,
+			Summary:     "Add a " + label.param,
+			Tags:        []string{"albums"},
+			Parameters:  params,
+			Responses:   responses(http.StatusOK, "Album", album, http.StatusBadRequest, http.StatusNotFound),
+		})
+		doc.Add(http.MethodDelete, route, openapi.Operation{
+			OperationID: "removeAlbum" + label.name,
+			Summary:     "Remove a " + label.param,
+			Tags:        []string{"albums"},
+			Parameters:  params,
+			Responses:   responses(http.StatusOK, "Album", album, http.StatusBadRequest, http.StatusNotFound),
+		})
+	}
+	doc.Schema(Problem{})
+	return doc
+}
+
+func pathParam(name, description string) openapi.Parameter {
+	schema := openapi.Type("string")
+	if name == "id" {
+		minimum := 1.0
+		schema = &openapi.Schema{Type: openapi.Types{"integer"}, Minimum: &minimum}
+	}
+	return openapi.Parameter{Name: name, In: openapi.InPath, Description: description, Required: true, Schema: schema}
+}
+
+func queryParam(name, description string, schema *openapi.Schema) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: openapi.InQuery, Description: description, Schema: schema}
+}
+
+// listParam is a repeatable query parameter, values may also be comma separated.
+func listParam(name, description string) openapi.Parameter {
+	explode := true
+	return openapi.Parameter{
+		Name: name, In: openapi.InQuery, Description: description, Explode: &explode,
+		Schema: &openapi.Schema{Type: openapi.Types{"array"}, Items: openapi.Type("string")},
+	}
+}
+
+func enum(values ...interface{}) *openapi.Schema {
+	return &openapi.Schema{Type: openapi.Types{"string"}, Enum: values}
+}
+
+func jsonBody(schema *openapi.Schema) *openapi.RequestBody {
+	return &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{"application/json": {Schema: schema}}}
+}
+
+// albumBody accepts every album representation but CSV.
+func albumBody(schema *openapi.Schema) *openapi.RequestBody {
+	body := jsonBody(schema)
+	for _, format := range albumFormats {
+		if format.name == formatCSV {
+			continue
+		}
+		for _, mediaType := range format.mediaTypes() {
+			body.Content[mediaType] = openapi.MediaType{Schema: schema}
+		}
+	}
+	return body
+}
+
+// negotiated documents the album representations of the status response, CSV as plain text,
+// and the problem returned when none is acceptable.
+func negotiated(result map[string]*openapi.Response, status int) map[string]*openapi.Response {
+	response := result[strconv.Itoa(status)]
+	schema := response.Content["application/json"].Schema
+	for _, format := range albumFormats {
+		response.Content[format.mediaType] = openapi.MediaType{Schema: schema}
+		if format.name == formatCSV {
+			response.Content[format.mediaType] = openapi.MediaType{Schema: openapi.Type("string")}
+		}
+	}
+	addProblems(result, http.StatusNotAcceptable)
+	return result
+}
+
+// responses documents the success response and the problem responses of an operation.
+func responses(status int, description string, schema *openapi.Schema, problems ...int) map[string]*openapi.Response {
+	result := map[string]*openapi.Response{}
+	if status != 0 {
+		response := &openapi.Response{Description: description}
+		if schema != nil {
+			response.Content = map[string]openapi.MediaType{"application/json": {Schema: schema}}
+		}
+		result[strconv.Itoa(status)] = response
+	}
+	addProblems(result, append(problems, http.StatusInternalServerError)...)
+	return result
+}
+
+func addProblems(responses map[string]*openapi.Response, statuses ...int) {
+	problem := &openapi.Schema{Ref: "#/components/schemas/Problem"}
+	for _, status := range statuses {
+		responses[strconv.Itoa(status)] = &openapi.Response{
+			Description: http.StatusText(status),
+			Content:     map[string]openapi.MediaType{problemContentType: {Schema: problem}},
+		}
+	}
+}
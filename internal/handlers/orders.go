@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Handles order HTTP requests.
+type OrderHandler struct {
+	service domain.OrderService
+}
+
+func NewOrderHandler(service domain.OrderService) *OrderHandler {
+	return &OrderHandler{service: service}
+}
+
+// Body of POST /orders, prices are taken from the catalog and never from the client.
+type placeOrderRequest struct {
+	CustomerEmail string `json:"customer_email" binding:"required"`
+	Items         []struct {
+		AlbumID  uint `json:"album_id" binding:"required"`
+		Quantity int  `json:"quantity" binding:"required"`
+	} `json:"items" binding:"required"`
+}
+
+func (h *OrderHandler) GetOrders(c *gin.Context) {
+	orders, err := h.service.GetAllOrders()
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, orders)
+}
+
+func (h *OrderHandler) GetOrderByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	order, err := h.service.GetOrderByID(id)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *OrderHandler) PlaceOrder(c *gin.Context) {
+	var request placeOrderRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	items := make([]domain.OrderItem, 0, len(request.Items))
+	for _, item := range request.Items {
+		items = append(items, domain.OrderItem{AlbumID: item.AlbumID, Quantity: item.Quantity})
+	}
+	order, err := h.service.PlaceOrder(request.CustomerEmail, items)
+	if err != nil {
+		abortWithError(c, orderErrorStatus(err), err)
+		return
+	}
+	c.JSON(http.StatusCreated, order)
+}
+
+func (h *OrderHandler) ShipOrder(c *gin.Context) {
+	h.transition(c, h.service.ShipOrder)
+}
+
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	h.transition(c, h.service.CancelOrder)
+}
+
+func (h *OrderHandler) transition(c *gin.Context, transition func(id int) (domain.Order, error)) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	order, err := transition(id)
+	if err != nil {
+		abortWithError(c, orderErrorStatus(err), err)
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+// orderErrorStatus maps order errors to a response status.
+func orderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidOrder), errors.Is(err, domain.ErrInvalidQuantity):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrInvalidOrderTransition), errors.Is(err, domain.ErrInsufficientStock):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
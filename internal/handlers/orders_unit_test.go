@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOrderService is a mock implementation of the OrderService interface (contained in the Order domain)
+type MockOrderService struct {
+	mock.Mock
+}
+
+func (m *MockOrderService) CancelOrder(id int) (domain.Order, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Order), args.Error(1)
+}
+
+func (m *MockOrderService) GetAllOrders() ([]domain.Order, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Order), args.Error(1)
+}
+
+func (m *MockOrderService) GetOrderByID(id int) (domain.Order, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Order), args.Error(1)
+}
+
+func (m *MockOrderService) PlaceOrder(customerEmail string, items []domain.OrderItem) (domain.Order, error) {
+	args := m.Called(customerEmail, items)
+	return args.Get(0).(domain.Order), args.Error(1)
+}
+
+func (m *MockOrderService) ShipOrder(id int) (domain.Order, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Order), args.Error(1)
+}
+
+func setupOrderTestRouter(service *MockOrderService) *gin.Engine {
+	r := gin.Default()
+	r.Use(Problems(logger.NewLogger()), IdentifyEditor("secret"))
+	handler := NewOrderHandler(service)
+	r.GET("/orders", RequireEditor, handler.GetOrders)
+	r.GET("/orders/:id", RequireEditor, handler.GetOrderByID)
+	r.POST("/orders", handler.PlaceOrder)
+	r.POST("/orders/:id/ship", RequireEditor, handler.ShipOrder)
+	r.POST("/orders/:id/cancel", RequireEditor, handler.CancelOrder)
+	return r
+}
+
+func TestOrderHandlers(t *testing.T) {
+	mockService := new(MockOrderService)
+	r := setupOrderTestRouter(mockService)
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	items := []domain.OrderItem{{AlbumID: 1, Quantity: 2}}
+
+	t.Run("POST :: /orders endpoint", func(t *testing.T) {
+		order := domain.Order{ID: 1, CustomerEmail: "buyer@example.com", Status: domain.OrderCreated, Total: 39.98}
+		mockService.On("PlaceOrder", "buyer@example.com", items).Return(order, nil).Once()
+
+		w := post("/orders", `{"customer_email":"buyer@example.com","items":[{"album_id":1,"quantity":2}]}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var response domain.Order
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, order.Total, response.Total)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("POST :: /orders endpoint rejects invalid orders and reports failures", func(t *testing.T) {
+		mockService.On("PlaceOrder", "buyer@example.com", items).Return(domain.Order{}, fmt.Errorf("%w: album 1 does not exist", domain.ErrInvalidOrder)).Once()
+		w := post("/orders", `{"customer_email":"buyer@example.com","items":[{"album_id":1,"quantity":2}]}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		mockService.On("PlaceOrder", "buyer@example.com", items).Return(domain.Order{}, domain.ErrInsufficientStock).Once()
+		w = post("/orders", `{"customer_email":"buyer@example.com","items":[{"album_id":1,"quantity":2}]}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		mockService.On("PlaceOrder", "buyer@example.com", items).Return(domain.Order{}, errors.New("connection refused")).Once()
+		w = post("/orders", `{"customer_email":"buyer@example.com","items":[{"album_id":1,"quantity":2}]}`)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("POST :: /orders/:id/ship and /cancel endpoints", func(t *testing.T) {
+		mockService.On("ShipOrder", 1).Return(domain.Order{ID: 1, Status: domain.OrderShipped}, nil).Once()
+		mockService.On("CancelOrder", 1).Return(domain.Order{}, fmt.Errorf("%w: shipped order cannot become cancelled", domain.ErrInvalidOrderTransition)).Once()
+		mockService.On("CancelOrder", 2).Return(domain.Order{}, domain.ErrNotFound).Once()
+
+		w := post("/orders/1/ship", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"shipped"`)
+
+		w = post("/orders/1/cancel", "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = post("/orders/2/cancel", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("GET :: /orders and /orders/:id endpoints", func(t *testing.T) {
+		order := domain.Order{ID: 1, CustomerEmail: "buyer@example.com", Status: domain.OrderCreated}
+		mockService.On("GetAllOrders").Return([]domain.Order{order}, nil).Once()
+		mockService.On("GetOrderByID", 1).Return(order, nil).Once()
+
+		for _, path := range []string{"/orders", "/orders/1"} {
+			req, _ := http.NewRequest("GET", path, nil)
+			req.Header.Set("Authorization", "Bearer secret")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), "buyer@example.com")
+		}
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("GET, POST :: order reads, /ship and /cancel endpoints require an editor", func(t *testing.T) {
+		for _, route := range [][2]string{{"GET", "/orders"}, {"GET", "/orders/1"}, {"POST", "/orders/1/ship"}, {"POST", "/orders/1/cancel"}} {
+			req, _ := http.NewRequest(route[0], route[1], nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+			req.Header.Set("Authorization", "Bearer wrong")
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusForbidden, w.Code)
+		}
+		mockService.AssertExpectations(t)
+	})
+}
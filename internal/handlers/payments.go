@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Header carrying the gateway webhook signature.
+const webhookSignatureHeader = "Webhook-Signature"
+
+// Handles payment HTTP requests and gateway webhooks.
+type PaymentHandler struct {
+	service domain.PaymentService
+}
+
+func NewPaymentHandler(service domain.PaymentService) *PaymentHandler {
+	return &PaymentHandler{service: service}
+}
+
+// Body of POST /orders/:id/payments, source is a card number or a gateway payment method token.
+type authorizePaymentRequest struct {
+	Source string `json:"source" binding:"required"`
+}
+
+// Body of POST /payments/:id/refund, omitting the amount refunds the remaining balance.
+type refundPaymentRequest struct {
+	Amount float64 `json:"amount"`
+}
+
+func (h *PaymentHandler) GetOrderPayments(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	payments, err := h.service.GetPaymentsByOrder(id)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, payments)
+}
+
+func (h *PaymentHandler) GetPaymentByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	payment, err := h.service.GetPaymentByID(id)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, payment)
+}
+
+func (h *PaymentHandler) AuthorizeOrder(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	var request authorizePaymentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	payment, err := h.service.AuthorizeOrder(id, request.Source)
+	if err != nil {
+		abortWithErrorData(c, paymentErrorStatus(err), err, gin.H{"payment": payment})
+		return
+	}
+	c.JSON(http.StatusCreated, payment)
+}
+
+func (h *PaymentHandler) CapturePayment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	payment, err := h.service.CapturePayment(id)
+	if err != nil {
+		abortWithErrorData(c, paymentErrorStatus(err), err, gin.H{"payment": payment})
+		return
+	}
+	c.JSON(http.StatusOK, payment)
+}
+
+func (h *PaymentHandler) RefundPayment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	var request refundPaymentRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+	payment, err := h.service.RefundPayment(id, request.Amount)
+	if err != nil {
+		abortWithError(c, paymentErrorStatus(err), err)
+		return
+	}
+	c.JSON(http.StatusOK, payment)
+}
+
+// Webhook reads the raw body, the signature covers the exact bytes sent by the gateway.
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	payment, err := h.service.HandleWebhook(payload, c.GetHeader(webhookSignatureHeader))
+	if errors.Is(err, domain.ErrInvalidWebhookSignature) {
+		abortWithError(c, http.StatusUnauthorized, err)
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidWebhookEvent) {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		abortWithError(c, paymentErrorStatus(err), err)
+		return
+	}
+	c.JSON(http.StatusOK, payment)
+}
+
+// paymentErrorStatus maps payment errors to a response status, gateway failures to 502.
+func paymentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPaymentDeclined):
+		return http.StatusPaymentRequired
+	case errors.Is(err, domain.ErrInvalidPaymentState):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrPaymentGateway):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
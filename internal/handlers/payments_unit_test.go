@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPaymentService is a mock implementation of the PaymentService interface (contained in the Payment domain)
+type MockPaymentService struct {
+	mock.Mock
+}
+
+func (m *MockPaymentService) AuthorizeOrder(orderID int, source string) (domain.Payment, error) {
+	args := m.Called(orderID, source)
+	return args.Get(0).(domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentService) CapturePayment(id int) (domain.Payment, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentService) GetPaymentByID(id int) (domain.Payment, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentService) GetPaymentsByOrder(orderID int) ([]domain.Payment, error) {
+	args := m.Called(orderID)
+	return args.Get(0).([]domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentService) HandleWebhook(payload []byte, signature string) (domain.Payment, error) {
+	args := m.Called(payload, signature)
+	return args.Get(0).(domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentService) RefundPayment(id int, amount float64) (domain.Payment, error) {
+	args := m.Called(id, amount)
+	return args.Get(0).(domain.Payment), args.Error(1)
+}
+
+func setupPaymentTestRouter(service *MockPaymentService) *gin.Engine {
+	r := gin.Default()
+	r.Use(Problems(logger.NewLogger()), IdentifyEditor("secret"))
+	handler := NewPaymentHandler(service)
+	r.GET("/payments/:id", RequireEditor, handler.GetPaymentByID)
+	r.POST("/payments/:id/capture", RequireEditor, handler.CapturePayment)
+	r.POST("/payments/:id/refund", RequireEditor, handler.RefundPayment)
+	r.POST("/payments/webhook", handler.Webhook)
+	return r
+}
+
+func TestPaymentHandlers(t *testing.T) {
+	mockService := new(MockPaymentService)
+	r := setupPaymentTestRouter(mockService)
+	post := func(path string, body string, header http.Header) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	editor := http.Header{"Authorization": {"Bearer secret"}}
+
+	t.Run("POST :: /payments/:id/capture endpoint", func(t *testing.T) {
+		mockService.On("CapturePayment", 1).Return(domain.Payment{ID: 1, Status: domain.PaymentCaptured}, nil).Once()
+		mockService.On("CapturePayment", 2).Return(domain.Payment{ID: 2, Status: domain.PaymentFailed}, fmt.Errorf("%w: capture_failed", domain.ErrPaymentDeclined)).Once()
+		mockService.On("CapturePayment", 3).Return(domain.Payment{}, fmt.Errorf("%w: unreachable", domain.ErrPaymentGateway)).Once()
+		mockService.On("CapturePayment", 4).Return(domain.Payment{}, domain.ErrNotFound).Once()
+		mockService.On("CapturePayment", 5).Return(domain.Payment{}, errors.New("Error 1054 (42S22): Unknown column")).Once()
+
+		w := post("/payments/1/capture", "", editor)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = post("/payments/2/capture", "", editor)
+		assert.Equal(t, http.StatusPaymentRequired, w.Code)
+		assert.Contains(t, w.Body.String(), `"payment":{"id":2`)
+		w = post("/payments/3/capture", "", editor)
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+		w = post("/payments/4/capture", "", editor)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = post("/payments/5/capture", "", editor)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "Unknown column")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("POST :: /payments/:id/refund endpoint", func(t *testing.T) {
+		mockService.On("RefundPayment", 1, 5.0).Return(domain.Payment{ID: 1, Refunded: 5}, nil).Once()
+		mockService.On("RefundPayment", 1, 0.0).Return(domain.Payment{}, fmt.Errorf("%w: cannot refund 0.00 of a refunded payment", domain.ErrInvalidPaymentState)).Once()
+
+		w := post("/payments/1/refund", `{"amount":5}`, editor)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = post("/payments/1/refund", "", editor)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("GET, POST :: /payments/:id, /capture and /refund endpoints require an editor", func(t *testing.T) {
+		for _, route := range [][2]string{{"GET", "/payments/1"}, {"POST", "/payments/1/capture"}, {"POST", "/payments/1/refund"}} {
+			req, _ := http.NewRequest(route[0], route[1], nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+			req.Header.Set("Authorization", "Bearer wrong")
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusForbidden, w.Code)
+		}
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("POST :: /payments/webhook endpoint", func(t *testing.T) {
+		signed := http.Header{"Webhook-Signature": {"t=1,v1=good"}}
+		forged := http.Header{"Webhook-Signature": {"t=1,v1=forged"}}
+		mockService.On("HandleWebhook", []byte(`{"id":"evt_1"}`), "t=1,v1=good").Return(domain.Payment{ID: 1, Status: domain.PaymentCaptured}, nil).Once()
+		mockService.On("HandleWebhook", []byte(`{"id":"evt_1"}`), "t=1,v1=forged").Return(domain.Payment{}, domain.ErrInvalidWebhookSignature).Once()
+		mockService.On("HandleWebhook", []byte(`{"id":"evt_2"}`), "t=1,v1=good").Return(domain.Payment{}, fmt.Errorf("%w: unsupported type %q", domain.ErrInvalidWebhookEvent, "charge.dispute")).Once()
+		mockService.On("HandleWebhook", []byte(`{"id":"evt_3"}`), "t=1,v1=good").Return(domain.Payment{}, errors.New("Error 1054 (42S22): Unknown column")).Once()
+
+		w := post("/payments/webhook", `{"id":"evt_1"}`, signed)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = post("/payments/webhook", `{"id":"evt_1"}`, forged)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		w = post("/payments/webhook", `{"id":"evt_2"}`, signed)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = post("/payments/webhook", `{"id":"evt_3"}`, signed)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "Unknown column")
+		mockService.AssertExpectations(t)
+	})
+}
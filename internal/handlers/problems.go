@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/pkg/logger"
+)
+
+const (
+	// Media type of error responses.
+	problemContentType = "application/problem+json"
+	// Problem type of requests rejected with field errors.
+	validationProblemType = "/problems/validation"
+	// Header carrying the request ID, taken from the request when given.
+	requestIDHeader = "X-Request-ID"
+	// Context key holding the request ID.
+	requestIDKey = "request_id"
+)
+
+// Client supplied request IDs are kept when they look like one.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// Problem details (RFC 7807) describing why a request failed.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	// Extension members rendered next to the standard ones
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// FieldError points at the invalid part of the request with a JSON Pointer (RFC 6901) into
+// the body, or into the path, query or header parameters when In says so.
+type FieldError struct {
+	In      string `json:"in"`
+	Pointer string `json:"pointer"`
+	Detail  string `json:"detail"`
+}
+
+// Request rejected by field, see ValidateRequests.
+type fieldsError struct {
+	fields []FieldError
+}
+
+func (e *fieldsError) Error() string {
+	return "request validation failed"
+}
+
+// Error attached to the context by abortWithError.
+type requestError struct {
+	status     int
+	err        error
+	extensions map[string]interface{}
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+func init() {
+	// Report binding errors by JSON field name instead of struct field name
+	if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		engine.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// abortWithError stops the handler chain, Problems renders err as the response. Missing
+// records are reported as not found whatever status the handler falls back to.
+func abortWithError(c *gin.Context, status int, err error) {
+	abortWithErrorData(c, status, err, nil)
+}
+
+// abortWithErrorData is abortWithError adding extension members to the problem.
+func abortWithErrorData(c *gin.Context, status int, err error, extensions gin.H) {
+	if errors.Is(err, domain.ErrNotFound) {
+		status = http.StatusNotFound
+	}
+	c.Status(status)
+	c.Error(&requestError{status: status, err: err, extensions: extensions})
+	c.Abort()
+}
+
+// Problems assigns every request an ID and renders errors attached by the handlers, as well as
+// panics, as application/problem+json. Server errors are logged and their details withheld.
+func Problems(log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+		c.Set(requestIDKey, requestID)
+		c.Header(requestIDHeader, requestID)
+
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				// Handlers abort responses that fail after they started, see ExportAlbums
+				if last := c.Errors.Last(); last != nil {
+					log.Error(fmt.Sprintf("request %s: response aborted: %s", requestID, last.Err))
+				}
+				panic(recovered)
+			}
+			log.Error(fmt.Sprintf("request %s: panic: %v\n%s", requestID, recovered, debug.Stack()))
+			if !c.Writer.Written() {
+				writeProblem(c, newProblem(c, http.StatusInternalServerError, nil))
+			}
+			c.Abort()
+		}()
+
+		c.Next()
+
+		last := c.Errors.Last()
+		if last == nil || c.Writer.Written() {
+			return
+		}
+		status, extensions := http.StatusInternalServerError, map[string]interface{}(nil)
+		var requestErr *requestError
+		if errors.As(last.Err, &requestErr) {
+			status, extensions = requestErr.status, requestErr.extensions
+		}
+		if status >= http.StatusInternalServerError {
+			log.Error(fmt.Sprintf("request %s: %s", requestID, last.Err))
+		}
+		problem := newProblem(c, status, last.Err)
+		problem.Extensions = extensions
+		writeProblem(c, problem)
+	}
+}
+
+// RouteNotFound answers requests no route matches.
+func RouteNotFound(c *gin.Context) {
+	abortWithError(c, http.StatusNotFound, fmt.Errorf("route %w", domain.ErrNotFound))
+}
+
+func newProblem(c *gin.Context, status int, err error) Problem {
+	problem := Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Instance:  c.Request.URL.Path,
+		RequestID: c.GetString(requestIDKey),
+	}
+	if err == nil || status >= http.StatusInternalServerError {
+		problem.Detail = "the server encountered an unexpected error"
+		return problem
+	}
+	problem.Detail = err.Error()
+	if problem.Errors = fieldErrors(err); len(problem.Errors) > 0 {
+		problem.Type = validationProblemType
+		problem.Detail = "request validation failed"
+	}
+	return problem
+}
+
+func writeProblem(c *gin.Context, problem Problem) {
+	c.Header("Content-Type", problemContentType)
+	c.Status(problem.Status)
+	if len(problem.Extensions) == 0 {
+		json.NewEncoder(c.Writer).Encode(problem)
+		return
+	}
+	members := map[string]interface{}{}
+	for name, value := range problem.Extensions {
+		members[name] = value
+	}
+	// Standard members win over extensions of the same name
+	standard, _ := json.Marshal(problem)
+	json.Unmarshal(standard, &members)
+	json.NewEncoder(c.Writer).Encode(members)
+}
+
+// fieldErrors locates binding failures in the request body.
+func fieldErrors(err error) []FieldError {
+	var invalidFields *fieldsError
+	if errors.As(err, &invalidFields) {
+		return invalidFields.fields
+	}
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fields := make([]FieldError, 0, len(validationErrors))
+		for _, fieldErr := range validationErrors {
+			// The namespace starts with the struct name: Album.tracks[0].title
+			_, namespace, _ := strings.Cut(fieldErr.Namespace(), ".")
+			fields = append(fields, FieldError{
+				In:      "body",
+				Pointer: jsonPointer(namespace),
+				Detail:  fmt.Sprintf("failed on the %s rule", fieldErr.Tag()),
+			})
+		}
+		return fields
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return []FieldError{{In: "body", Pointer: jsonPointer(typeErr.Field), Detail: fmt.Sprintf("must be %s", typeErr.Type)}}
+	}
+	return nil
+}
+
+// jsonPointer converts a dotted path with [index] segments to a JSON Pointer.
+func jsonPointer(path string) string {
+	if path == "" {
+		return ""
+	}
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+	segments := strings.Split(path, ".")
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	for i, segment := range segments {
+		segments[i] = escaper.Replace(segment)
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
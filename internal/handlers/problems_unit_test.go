@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) Problem {
+	assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+	var problem Problem
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	return problem
+}
+
+func TestProblems(t *testing.T) {
+	mockService := new(MockAlbumService)
+	r := gin.New()
+	r.Use(Problems(logger.NewLogger()))
+	r.NoRoute(RouteNotFound)
+	handler := NewAlbumHandler(mockService)
+	r.GET("/albums/:id", handler.GetAlbumByID)
+	r.POST("/albums", handler.CreateAlbum)
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	r.GET("/gateway", func(c *gin.Context) {
+		abortWithError(c, http.StatusBadGateway, fmt.Errorf("%w: dial tcp 10.0.4.12:443: connection refused", domain.ErrPaymentGateway))
+	})
+	r.GET("/payment", func(c *gin.Context) {
+		abortWithErrorData(c, http.StatusPaymentRequired, domain.ErrPaymentDeclined, gin.H{"payment": gin.H{"id": 7}, "status": "ignored"})
+	})
+
+	t.Run("GET :: /albums/:id endpoint maps missing records to not found", func(t *testing.T) {
+		mockService.On("GetAlbumByID", 1).Return(domain.Album{}, domain.ErrNotFound)
+
+		req, _ := http.NewRequest("GET", "/albums/1", nil)
+		req.Header.Set(requestIDHeader, "req-1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "req-1", w.Header().Get(requestIDHeader))
+		assert.Equal(t, Problem{
+			Type:      "about:blank",
+			Title:     "Not Found",
+			Status:    http.StatusNotFound,
+			Detail:    "not found",
+			Instance:  "/albums/1",
+			RequestID: "req-1",
+		}, decodeProblem(t, w))
+	})
+
+	t.Run("GET :: /albums/:id endpoint withholds internal error details", func(t *testing.T) {
+		mockService.On("GetAlbumByID", 2).Return(domain.Album{}, errors.New("Error 1040: Too many connections"))
+
+		req, _ := http.NewRequest("GET", "/albums/2", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		problem := decodeProblem(t, w)
+		assert.NotContains(t, problem.Detail, "Too many connections")
+		assert.Len(t, problem.RequestID, 32)
+		assert.Equal(t, problem.RequestID, w.Header().Get(requestIDHeader))
+	})
+
+	t.Run("GET :: upstream failures withhold error details", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/gateway", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+		problem := decodeProblem(t, w)
+		assert.Equal(t, "Bad Gateway", problem.Title)
+		assert.Equal(t, "the server encountered an unexpected error", problem.Detail)
+		assert.NotContains(t, w.Body.String(), "10.0.4.12")
+	})
+
+	t.Run("POST :: /albums endpoint reports field errors as JSON Pointers", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/albums", strings.NewReader(`{"title": "Abbey Road", "tracks": [{"title": 1}]}`))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		problem := decodeProblem(t, w)
+		assert.Equal(t, validationProblemType, problem.Type)
+		assert.Equal(t, []FieldError{{In: "body", Pointer: "/tracks/0/title", Detail: "must be string"}}, problem.Errors)
+	})
+
+	t.Run("GET :: panics are rendered as problems", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/panic", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "Internal Server Error", decodeProblem(t, w).Title)
+	})
+
+	t.Run("GET :: extension members and unknown routes", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/payment", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPaymentRequired, w.Code)
+		var members map[string]interface{}
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &members))
+		assert.Equal(t, map[string]interface{}{"id": float64(7)}, members["payment"])
+		assert.Equal(t, float64(http.StatusPaymentRequired), members["status"])
+
+		req, _ = http.NewRequest("GET", "/missing", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "route not found", decodeProblem(t, w).Detail)
+	})
+
+	t.Run("jsonPointer :: escapes segments", func(t *testing.T) {
+		assert.Equal(t, "/tracks/2/title", jsonPointer("tracks[2].title"))
+		assert.Equal(t, "/a~1b/c~0d", jsonPointer("a/b.c~d"))
+		assert.Equal(t, "", jsonPointer(""))
+	})
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Handles promotion HTTP requests.
+type PromotionHandler struct {
+	service domain.PromotionService
+}
+
+func NewPromotionHandler(service domain.PromotionService) *PromotionHandler {
+	return &PromotionHandler{service: service}
+}
+
+func (h *PromotionHandler) GetPromotions(c *gin.Context) {
+	promotions, err := h.service.GetAllPromotions()
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if !isEditor(c) {
+		// Coupon codes are handed out to customers, listing them would give every code away
+		redacted := make([]domain.Promotion, 0, len(promotions))
+		for _, promotion := range promotions {
+			redacted = append(redacted, promotion.Redacted())
+		}
+		promotions = redacted
+	}
+	c.JSON(http.StatusOK, promotions)
+}
+
+func (h *PromotionHandler) GetPromotionByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	promotion, err := h.service.GetPromotionByID(id)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if !isEditor(c) {
+		promotion = promotion.Redacted()
+	}
+	c.JSON(http.StatusOK, promotion)
+}
+
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	var promotion domain.Promotion
+	if err := c.ShouldBindJSON(&promotion); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	createdPromotion, err := h.service.CreatePromotion(promotion)
+	if errors.Is(err, domain.ErrInvalidPromotion) {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, createdPromotion)
+}
+
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	var promotion domain.Promotion
+	if err := c.ShouldBindJSON(&promotion); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	promotion.ID = uint(id)
+	updatedPromotion, err := h.service.UpdatePromotion(promotion)
+	if errors.Is(err, domain.ErrInvalidPromotion) {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, updatedPromotion)
+}
+
+func (h *PromotionHandler) DeletePromotion(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := h.service.DeletePromotion(id); err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPromotionService is a mock implementation of the PromotionService interface (contained in the Promotion domain)
+type MockPromotionService struct {
+	mock.Mock
+}
+
+func (m *MockPromotionService) CreatePromotion(promotion domain.Promotion) (domain.Promotion, error) {
+	args := m.Called(promotion)
+	return args.Get(0).(domain.Promotion), args.Error(1)
+}
+
+func (m *MockPromotionService) DeletePromotion(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockPromotionService) GetAllPromotions() ([]domain.Promotion, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Promotion), args.Error(1)
+}
+
+func (m *MockPromotionService) GetPromotionByID(id int) (domain.Promotion, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Promotion), args.Error(1)
+}
+
+func (m *MockPromotionService) UpdatePromotion(promotion domain.Promotion) (domain.Promotion, error) {
+	args := m.Called(promotion)
+	return args.Get(0).(domain.Promotion), args.Error(1)
+}
+
+func setupPromotionTestRouter(service *MockPromotionService) *gin.Engine {
+	r := gin.Default()
+	r.Use(Problems(logger.NewLogger()), IdentifyEditor("secret"))
+	handler := NewPromotionHandler(service)
+	r.GET("/promotions", handler.GetPromotions)
+	r.GET("/promotions/:id", handler.GetPromotionByID)
+	r.POST("/promotions", RequireEditor, handler.CreatePromotion)
+	r.PUT("/promotions/:id", RequireEditor, handler.UpdatePromotion)
+	r.DELETE("/promotions/:id", RequireEditor, handler.DeletePromotion)
+	return r
+}
+
+func TestPromotionHandlers(t *testing.T) {
+	mockService := new(MockPromotionService)
+	r := setupPromotionTestRouter(mockService)
+	request := func(method, path, token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	coupon := domain.Promotion{ID: 1, Name: "Spring sale", Kind: domain.PromotionPercent, Value: 10, CouponCode: "SPRING"}
+
+	t.Run("GET :: /promotions and /promotions/:id endpoints hide coupon codes from the public", func(t *testing.T) {
+		mockService.On("GetAllPromotions").Return([]domain.Promotion{coupon}, nil).Twice()
+		mockService.On("GetPromotionByID", 1).Return(coupon, nil).Twice()
+
+		for _, path := range []string{"/promotions", "/promotions/1"} {
+			w := request("GET", path, "")
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), "Spring sale")
+			assert.NotContains(t, w.Body.String(), "SPRING")
+
+			w = request("GET", path, "secret")
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), `"coupon_code":"SPRING"`)
+		}
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("POST, PUT, DELETE :: /promotions endpoints require an editor", func(t *testing.T) {
+		for _, route := range [][2]string{{"POST", "/promotions"}, {"PUT", "/promotions/1"}, {"DELETE", "/promotions/1"}} {
+			assert.Equal(t, http.StatusUnauthorized, request(route[0], route[1], "").Code)
+			assert.Equal(t, http.StatusForbidden, request(route[0], route[1], "wrong").Code)
+		}
+		mockService.AssertNotCalled(t, "CreatePromotion", mock.Anything)
+		mockService.AssertNotCalled(t, "UpdatePromotion", mock.Anything)
+		mockService.AssertNotCalled(t, "DeletePromotion", mock.Anything)
+	})
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Hidden reviews are reported as missing to anyone but editors.
+var errReviewNotFound = fmt.Errorf("review %w", domain.ErrNotFound)
+
+// Handles album review HTTP requests.
+type ReviewHandler struct {
+	service domain.ReviewService
+}
+
+func NewReviewHandler(service domain.ReviewService) *ReviewHandler {
+	return &ReviewHandler{service: service}
+}
+
+// Body of POST /albums/:id/reviews, the author email is kept private.
+type createReviewRequest struct {
+	Author string `json:"author" binding:"required"`
+	Rating int    `json:"rating"`
+	Text   string `json:"text"`
+}
+
+// GetAlbumReviews lists visible reviews of a public album, editors also see hidden ones.
+func (h *ReviewHandler) GetAlbumReviews(c *gin.Context) {
+	albumID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	reviews, err := h.service.GetAlbumReviews(albumID, isEditor(c))
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, reviews)
+}
+
+func (h *ReviewHandler) GetReview(c *gin.Context) {
+	albumID, id, ok := reviewParams(c)
+	if !ok {
+		return
+	}
+	review, err := h.service.GetReview(albumID, id)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if review.Hidden && !isEditor(c) {
+		abortWithError(c, http.StatusNotFound, errReviewNotFound)
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+func (h *ReviewHandler) CreateReview(c *gin.Context) {
+	albumID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	var request createReviewRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	review := domain.Review{Author: request.Author, Rating: request.Rating, Text: request.Text}
+	createdReview, err := h.service.CreateReview(albumID, review)
+	if err != nil {
+		abortWithError(c, reviewErrorStatus(err), err)
+		return
+	}
+	c.JSON(http.StatusCreated, createdReview)
+}
+
+// UpdateReview changes rating and text, it takes the author token of the review or an editor.
+func (h *ReviewHandler) UpdateReview(c *gin.Context) {
+	albumID, id, ok := reviewParams(c)
+	if !ok || !h.authorized(c, albumID, id) {
+		return
+	}
+	var review domain.Review
+	if err := c.ShouldBindJSON(&review); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	review.ID = uint(id)
+	updatedReview, err := h.service.UpdateReview(albumID, review)
+	if err != nil {
+		abortWithError(c, reviewErrorStatus(err), err)
+		return
+	}
+	c.JSON(http.StatusOK, updatedReview)
+}
+
+// DeleteReview takes the author token of the review or an editor.
+func (h *ReviewHandler) DeleteReview(c *gin.Context) {
+	albumID, id, ok := reviewParams(c)
+	if !ok || !h.authorized(c, albumID, id) {
+		return
+	}
+	if err := h.service.DeleteReview(albumID, id); err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// FlagReview reports a review to the moderators, each client flags a review once.
+func (h *ReviewHandler) FlagReview(c *gin.Context) {
+	h.moderate(c, func(albumID, id int) (domain.Review, error) {
+		return h.service.FlagReview(albumID, id, c.ClientIP())
+	})
+}
+
+func (h *ReviewHandler) HideReview(c *gin.Context) {
+	h.moderate(c, func(albumID, id int) (domain.Review, error) {
+		return h.service.ModerateReview(albumID, id, true)
+	})
+}
+
+func (h *ReviewHandler) UnhideReview(c *gin.Context) {
+	h.moderate(c, func(albumID, id int) (domain.Review, error) {
+		return h.service.ModerateReview(albumID, id, false)
+	})
+}
+
+func (h *ReviewHandler) moderate(c *gin.Context, action func(albumID, id int) (domain.Review, error)) {
+	albumID, id, ok := reviewParams(c)
+	if !ok {
+		return
+	}
+	review, err := action(albumID, id)
+	if err != nil {
+		abortWithError(c, reviewErrorStatus(err), err)
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+// authorized lets editors and requests carrying "Authorization: Bearer <token>" with the author
+// token of the review through, responding with 401 or 403 otherwise.
+func (h *ReviewHandler) authorized(c *gin.Context, albumID, id int) bool {
+	if isEditor(c) {
+		return true
+	}
+	token, ok := bearerToken(c)
+	if !ok {
+		c.Header("WWW-Authenticate", "Bearer")
+		abortWithError(c, http.StatusUnauthorized, errors.New("review token required"))
+		return false
+	}
+	review, err := h.service.GetReview(albumID, id)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return false
+	}
+	if !review.AuthoredWith(token) {
+		abortWithError(c, http.StatusForbidden, domain.ErrReviewToken)
+		return false
+	}
+	return true
+}
+
+// reviewParams reads the album and review IDs, responding with 400 when either is invalid.
+func reviewParams(c *gin.Context) (int, int, bool) {
+	albumID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return 0, 0, false
+	}
+	id, err := strconv.Atoi(c.Param("review"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return 0, 0, false
+	}
+	return albumID, id, true
+}
+
+// reviewErrorStatus maps review write errors to a response status.
+func reviewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidReview):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrDuplicateReview), errors.Is(err, domain.ErrDuplicateFlag):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReviewService is a mock implementation of the ReviewService interface (contained in the Review domain)
+type MockReviewService struct {
+	mock.Mock
+}
+
+func (m *MockReviewService) GetAlbumReviews(albumID int, includeHidden bool) ([]domain.Review, error) {
+	args := m.Called(albumID, includeHidden)
+	return args.Get(0).([]domain.Review), args.Error(1)
+}
+
+func (m *MockReviewService) GetReview(albumID int, id int) (domain.Review, error) {
+	args := m.Called(albumID, id)
+	return args.Get(0).(domain.Review), args.Error(1)
+}
+
+func (m *MockReviewService) CreateReview(albumID int, review domain.Review) (domain.Review, error) {
+	args := m.Called(albumID, review)
+	return args.Get(0).(domain.Review), args.Error(1)
+}
+
+func (m *MockReviewService) UpdateReview(albumID int, review domain.Review) (domain.Review, error) {
+	args := m.Called(albumID, review)
+	return args.Get(0).(domain.Review), args.Error(1)
+}
+
+func (m *MockReviewService) DeleteReview(albumID int, id int) error {
+	args := m.Called(albumID, id)
+	return args.Error(0)
+}
+
+func (m *MockReviewService) FlagReview(albumID int, id int, client string) (domain.Review, error) {
+	args := m.Called(albumID, id, client)
+	return args.Get(0).(domain.Review), args.Error(1)
+}
+
+func (m *MockReviewService) ModerateReview(albumID int, id int, hidden bool) (domain.Review, error) {
+	args := m.Called(albumID, id, hidden)
+	return args.Get(0).(domain.Review), args.Error(1)
+}
+
+func TestReviewHandlers(t *testing.T) {
+	mockService := new(MockReviewService)
+	r := gin.Default()
+	r.Use(Problems(logger.NewLogger()))
+	r.Use(IdentifyEditor("secret"))
+	handler := NewReviewHandler(mockService)
+	r.GET("/albums/:id/reviews", handler.GetAlbumReviews)
+	r.GET("/albums/:id/reviews/:review", handler.GetReview)
+	r.POST("/albums/:id/reviews", handler.CreateReview)
+	r.PUT("/albums/:id/reviews/:review", handler.UpdateReview)
+	r.DELETE("/albums/:id/reviews/:review", handler.DeleteReview)
+	r.POST("/albums/:id/reviews/:review/flag", handler.FlagReview)
+	r.POST("/albums/:id/reviews/:review/hide", RequireEditor, handler.HideReview)
+
+	t.Run("GET :: /albums/:id/reviews endpoint", func(t *testing.T) {
+		reviews := []domain.Review{{ID: 1, AlbumID: 1, Author: "fan@example.com", Rating: 5}}
+		mockService.On("GetAlbumReviews", 1, false).Return(reviews, nil)
+
+		req, _ := http.NewRequest("GET", "/albums/1/reviews", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "fan@example.com")
+		var response []domain.Review
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Nil(t, err)
+		assert.Equal(t, 5, response[0].Rating)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("GET :: /albums/:id/reviews endpoint reports albums that are not public as missing", func(t *testing.T) {
+		mockService.On("GetAlbumReviews", 2, false).Return([]domain.Review(nil), fmt.Errorf("%w: album 2 is not published", domain.ErrNotFound))
+
+		req, _ := http.NewRequest("GET", "/albums/2/reviews", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("GET :: /albums/:id/reviews/:review endpoint hides moderated reviews", func(t *testing.T) {
+		mockService.On("GetReview", 1, 2).Return(domain.Review{ID: 2, AlbumID: 1, Hidden: true}, nil)
+
+		req, _ := http.NewRequest("GET", "/albums/1/reviews/2", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		req, _ = http.NewRequest("GET", "/albums/1/reviews/2", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("POST :: /albums/:id/reviews endpoint", func(t *testing.T) {
+		review := domain.Review{Author: "fan@example.com", Rating: 4, Text: "Great"}
+		mockService.On("CreateReview", 1, review).Return(domain.Review{ID: 3, AlbumID: 1, Author: "fan@example.com", Rating: 4, Text: "Great"}, nil)
+		duplicate := domain.Review{Author: "twice@example.com", Rating: 4}
+		mockService.On("CreateReview", 1, duplicate).Return(domain.Review{}, domain.ErrDuplicateReview)
+
+		req, _ := http.NewRequest("POST", "/albums/1/reviews", bytes.NewBufferString(`{"author":"fan@example.com","rating":4,"text":"Great"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.NotContains(t, w.Body.String(), "fan@example.com")
+
+		req, _ = http.NewRequest("POST", "/albums/1/reviews", bytes.NewBufferString(`{"author":"twice@example.com","rating":4}`))
+		req.Header.Set("Content-Type", "application/json")
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("PUT :: /albums/:id/reviews/:review endpoint rejects invalid rating", func(t *testing.T) {
+		mockService.On("UpdateReview", 1, domain.Review{ID: 3, Rating: 9}).Return(domain.Review{}, domain.ErrInvalidReview)
+
+		req, _ := http.NewRequest("PUT", "/albums/1/reviews/3", bytes.NewBufferString(`{"rating":9}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	authored := domain.Review{ID: 4, AlbumID: 1, Author: "fan@example.com", Rating: 3}
+	authored.SetToken("author-token")
+	authored.Token = ""
+
+	t.Run("PUT :: /albums/:id/reviews/:review endpoint requires the author token", func(t *testing.T) {
+		mockService.On("GetReview", 1, 4).Return(authored, nil)
+		mockService.On("UpdateReview", 1, domain.Review{ID: 4, Rating: 4}).Return(domain.Review{ID: 4, AlbumID: 1, Rating: 4}, nil).Once()
+
+		send := func(authorization string) int {
+			req, _ := http.NewRequest("PUT", "/albums/1/reviews/4", bytes.NewBufferString(`{"rating":4}`))
+			req.Header.Set("Content-Type", "application/json")
+			if authorization != "" {
+				req.Header.Set("Authorization", authorization)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			return w.Code
+		}
+		assert.Equal(t, http.StatusUnauthorized, send(""))
+		assert.Equal(t, http.StatusForbidden, send("Bearer stolen-token"))
+		assert.Equal(t, http.StatusOK, send("Bearer author-token"))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("DELETE :: /albums/:id/reviews/:review endpoint takes the author token or an editor", func(t *testing.T) {
+		mockService.On("DeleteReview", 1, 4).Return(nil).Twice()
+
+		for _, authorization := range []string{"Bearer author-token", "Bearer secret"} {
+			req, _ := http.NewRequest("DELETE", "/albums/1/reviews/4", nil)
+			req.Header.Set("Authorization", authorization)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusNoContent, w.Code)
+		}
+
+		req, _ := http.NewRequest("DELETE", "/albums/1/reviews/4", nil)
+		req.Header.Set("Authorization", "Bearer stolen-token")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("POST :: /albums/:id/reviews/:review/flag endpoint counts a client once", func(t *testing.T) {
+		mockService.On("FlagReview", 1, 4, "192.0.2.1").Return(domain.Review{ID: 4, FlagCount: 1}, nil).Once()
+		mockService.On("FlagReview", 1, 4, "192.0.2.1").Return(domain.Review{}, domain.ErrDuplicateFlag).Once()
+
+		flag := func() int {
+			req, _ := http.NewRequest("POST", "/albums/1/reviews/4/flag", nil)
+			req.RemoteAddr = "192.0.2.1:5300"
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			return w.Code
+		}
+		assert.Equal(t, http.StatusOK, flag())
+		assert.Equal(t, http.StatusConflict, flag())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("POST :: /albums/:id/reviews/:review/hide endpoint requires an editor", func(t *testing.T) {
+		mockService.On("ModerateReview", 1, 3, true).Return(domain.Review{ID: 3, Hidden: true}, nil)
+
+		req, _ := http.NewRequest("POST", "/albums/1/reviews/3/hide", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		req, _ = http.NewRequest("POST", "/albums/1/reviews/3/hide", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
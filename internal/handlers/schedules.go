@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Handles scheduled album change HTTP requests.
+type ScheduleHandler struct {
+	service domain.ScheduleService
+}
+
+func NewScheduleHandler(service domain.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{service: service}
+}
+
+// GetSchedules lists pending changes, ?status= selects another status or all of them.
+func (h *ScheduleHandler) GetSchedules(c *gin.Context) {
+	filter, err := scheduleFilterFromQuery(c)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if albumID := c.Query("album_id"); albumID != "" {
+		id, err := strconv.Atoi(albumID)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+		filter.AlbumID = uint(id)
+	}
+	h.listSchedules(c, filter)
+}
+
+func (h *ScheduleHandler) GetAlbumSchedules(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	filter, err := scheduleFilterFromQuery(c)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	filter.AlbumID = uint(id)
+	h.listSchedules(c, filter)
+}
+
+func (h *ScheduleHandler) listSchedules(c *gin.Context, filter domain.ScheduleFilter) {
+	changes, err := h.service.GetSchedules(filter)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, changes)
+}
+
+func (h *ScheduleHandler) GetScheduleByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	change, err := h.service.GetScheduleByID(id)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, change)
+}
+
+func (h *ScheduleHandler) ScheduleAlbumChange(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	var change domain.ScheduledChange
+	if err := c.ShouldBindJSON(&change); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	scheduled, err := h.service.ScheduleChange(id, change)
+	if errors.Is(err, domain.ErrInvalidSchedule) {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, scheduled)
+}
+
+func (h *ScheduleHandler) CancelSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	change, err := h.service.CancelSchedule(id)
+	if errors.Is(err, domain.ErrScheduleNotPending) {
+		abortWithError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, change)
+}
+
+func scheduleFilterFromQuery(c *gin.Context) (domain.ScheduleFilter, error) {
+	switch status := domain.ScheduleStatus(c.Query("status")); status {
+	case "":
+		return domain.ScheduleFilter{Status: domain.SchedulePending}, nil
+	case "all":
+		return domain.ScheduleFilter{}, nil
+	case domain.SchedulePending, domain.ScheduleApplied, domain.ScheduleFailed, domain.ScheduleCancelled:
+		return domain.ScheduleFilter{Status: status}, nil
+	default:
+		return domain.ScheduleFilter{}, errors.New("status must be pending, applied, failed, cancelled or all")
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockScheduleService is a mock implementation of the ScheduleService interface (contained in the Schedule domain)
+type MockScheduleService struct {
+	mock.Mock
+}
+
+func (m *MockScheduleService) ScheduleChange(albumID int, change domain.ScheduledChange) (domain.ScheduledChange, error) {
+	args := m.Called(albumID, change)
+	return args.Get(0).(domain.ScheduledChange), args.Error(1)
+}
+
+func (m *MockScheduleService) GetSchedules(filter domain.ScheduleFilter) ([]domain.ScheduledChange, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]domain.ScheduledChange), args.Error(1)
+}
+
+func (m *MockScheduleService) GetScheduleByID(id int) (domain.ScheduledChange, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.ScheduledChange), args.Error(1)
+}
+
+func (m *MockScheduleService) CancelSchedule(id int) (domain.ScheduledChange, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.ScheduledChange), args.Error(1)
+}
+
+func (m *MockScheduleService) ApplyDueChanges(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func TestScheduleHandlers(t *testing.T) {
+	mockService := new(MockScheduleService)
+	r := gin.Default()
+	r.Use(Problems(logger.NewLogger()))
+	handler := NewScheduleHandler(mockService)
+	r.GET("/schedules", handler.GetSchedules)
+	r.POST("/schedules/:id/cancel", handler.CancelSchedule)
+	r.GET("/albums/:id/schedules", handler.GetAlbumSchedules)
+	r.POST("/albums/:id/schedules", handler.ScheduleAlbumChange)
+
+	runAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	price := 7.99
+
+	t.Run("POST :: /albums/:id/schedules endpoint", func(t *testing.T) {
+		change := domain.ScheduledChange{Action: domain.SchedulePrice, Price: &price, RunAt: runAt}
+		scheduled := domain.ScheduledChange{ID: 1, AlbumID: 3, Action: domain.SchedulePrice, Price: &price, RunAt: runAt, Status: domain.SchedulePending}
+		mockService.On("ScheduleChange", 3, change).Return(scheduled, nil)
+
+		body := `{"action":"price","price":7.99,"run_at":"2030-01-01T00:00:00Z"}`
+		req, _ := http.NewRequest("POST", "/albums/3/schedules", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var response domain.ScheduledChange
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Nil(t, err)
+		assert.Equal(t, scheduled, response)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("POST :: /albums/:id/schedules endpoint rejects invalid change", func(t *testing.T) {
+		change := domain.ScheduledChange{Action: "delete", RunAt: runAt}
+		mockService.On("ScheduleChange", 4, change).Return(domain.ScheduledChange{}, domain.ErrInvalidSchedule)
+
+		body := `{"action":"delete","run_at":"2030-01-01T00:00:00Z"}`
+		req, _ := http.NewRequest("POST", "/albums/4/schedules", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("GET :: /schedules endpoint lists pending changes", func(t *testing.T) {
+		changes := []domain.ScheduledChange{{ID: 1, AlbumID: 3, Action: domain.SchedulePublish, RunAt: runAt, Status: domain.SchedulePending}}
+		mockService.On("GetSchedules", domain.ScheduleFilter{Status: domain.SchedulePending}).Return(changes, nil)
+		mockService.On("GetSchedules", domain.ScheduleFilter{AlbumID: 3}).Return(changes, nil)
+
+		req, _ := http.NewRequest("GET", "/schedules", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		req, _ = http.NewRequest("GET", "/albums/3/schedules?status=all", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		req, _ = http.NewRequest("GET", "/schedules?status=done", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("POST :: /schedules/:id/cancel endpoint", func(t *testing.T) {
+		mockService.On("CancelSchedule", 1).Return(domain.ScheduledChange{ID: 1, Status: domain.ScheduleCancelled}, nil)
+		mockService.On("CancelSchedule", 2).Return(domain.ScheduledChange{}, domain.ErrScheduleNotPending)
+
+		req, _ := http.NewRequest("POST", "/schedules/1/cancel", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		req, _ = http.NewRequest("POST", "/schedules/2/cancel", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
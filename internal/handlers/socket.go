@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+const (
+	// Subprotocol spoken on the album socket, offered by clients next to the token subprotocol.
+	socketProtocol = "albums.v1"
+	// How long writing a message to a client may take.
+	socketWriteTimeout = 10 * time.Second
+	// Largest message accepted from a client.
+	maxSocketMessage = 64 << 10
+	// Subscriptions a connection may hold at once.
+	maxSocketSubscriptions = 100
+)
+
+// Types of the messages exchanged on the album socket.
+const (
+	socketSubscribe    = "subscribe"
+	socketUnsubscribe  = "unsubscribe"
+	socketSubscribed   = "subscribed"
+	socketUnsubscribed = "unsubscribed"
+	socketEvent        = "event"
+	socketError        = "error"
+)
+
+// Handles the album WebSocket.
+type SocketHandler struct {
+	events    domain.AlbumEventSource
+	keepalive time.Duration
+	queueSize int
+}
+
+// NewSocketHandler serves album events from events, pinging clients every keepalive. Up to
+// queueSize messages wait for a client before it is disconnected as too slow.
+func NewSocketHandler(events domain.AlbumEventSource, keepalive time.Duration, queueSize int) *SocketHandler {
+	return &SocketHandler{events: events, keepalive: keepalive, queueSize: queueSize}
+}
+
+// Message of a client. Subscribe takes album or artist IDs and a filter, an album change is
+// sent when it matches all of them. Subscribing again with the same ID replaces the subscription.
+type socketRequest struct {
+	Type      string        `json:"type"`
+	ID        string        `json:"id"`
+	AlbumIDs  []uint        `json:"album_ids,omitempty"`
+	ArtistIDs []uint        `json:"artist_ids,omitempty"`
+	Filter    *socketFilter `json:"filter,omitempty"`
+}
+
+// Filters of the album list, except in_stock.
+type socketFilter struct {
+	Genres        []string    `json:"genre,omitempty"`
+	GenreMatch    string      `json:"genre_match,omitempty"`
+	Tags          []string    `json:"tag,omitempty"`
+	TagMatch      string      `json:"tag_match,omitempty"`
+	ReleasedAfter domain.Date `json:"released_after"`
+	Status        string      `json:"status,omitempty"`
+}
+
+func (f socketFilter) albumFilter() (domain.AlbumFilter, error) {
+	var err error
+	filter := domain.AlbumFilter{ReleasedAfter: f.ReleasedAfter}
+	for _, genre := range f.Genres {
+		filter.Genres = append(filter.Genres, domain.NormalizeGenreName(genre))
+	}
+	for _, tag := range f.Tags {
+		filter.Tags = append(filter.Tags, domain.NormalizeTagName(tag))
+	}
+	if filter.GenreMatch, err = matchMode(f.GenreMatch); err != nil {
+		return filter, err
+	}
+	if filter.TagMatch, err = matchMode(f.TagMatch); err != nil {
+		return filter, err
+	}
+	if f.Status != "" {
+		filter.Status, err = domain.ParseAlbumStatus(f.Status)
+	}
+	return filter, err
+}
+
+// Message to a client. Events list the IDs of the subscriptions they match.
+type socketMessage struct {
+	Type          string          `json:"type"`
+	ID            string          `json:"id,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	Subscriptions []string        `json:"subscriptions,omitempty"`
+	Event         *albumEventData `json:"event,omitempty"`
+}
+
+type socketSubscription struct {
+	albums domain.AlbumEventFilter
+	filter domain.AlbumFilter
+}
+
+func (s socketSubscription) matches(event domain.AlbumEvent) bool {
+	return s.albums.Matches(event) && s.filter.Matches(event.Album)
+}
+
+// ServeSocket upgrades an editor request to a WebSocket sending the album changes matching the
+// subscriptions of the client. Clients are pinged every keepalive and disconnected when they
+// miss the pong. A client whose queue of unsent messages is full is disconnected with close
+// code 1013 and should subscribe again, reloading the albums it shows. Like the event stream,
+// a socket only sees the changes made through its own replica.
+func (h *SocketHandler) ServeSocket(c *gin.Context) {
+	upgrader := websocket.Upgrader{
+		Subprotocols: []string{socketProtocol},
+		// The editor token is sent explicitly rather than by the browser, any page may connect
+		CheckOrigin: func(r *http.Request) bool { return true },
+		Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+			abortWithError(c, status, reason)
+		},
+	}
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	subscription := h.events.Subscribe(domain.AlbumEventFilter{}, "")
+	defer subscription.Close()
+
+	socket := &albumSocket{
+		conn:          conn,
+		keepalive:     h.keepalive,
+		send:          make(chan socketMessage, h.queueSize),
+		done:          make(chan struct{}),
+		failed:        make(chan struct{}),
+		subscriptions: map[string]socketSubscription{},
+	}
+	requests := make(chan socketRequest)
+	readErr := make(chan error, 1)
+	go socket.read(requests, readErr)
+	go socket.write()
+	defer close(socket.done)
+
+	for {
+		select {
+		case request := <-requests:
+			if !socket.enqueue(socket.handle(request)) {
+				socket.close(websocket.CloseTryAgainLater, "too slow to keep up")
+				return
+			}
+		case event, ok := <-subscription.Events:
+			if !ok {
+				socket.close(websocket.CloseTryAgainLater, "album events stopped, subscribe again")
+				return
+			}
+			if !socket.notify(event) {
+				socket.close(websocket.CloseTryAgainLater, "too slow to keep up")
+				return
+			}
+		case <-readErr:
+			// The client left or missed a pong
+			return
+		case <-socket.failed:
+			return
+		}
+	}
+}
+
+// albumSocket is a connection of ServeSocket. The handler owns the subscriptions, a reader and
+// a writer goroutine talk to the client.
+type albumSocket struct {
+	conn          *websocket.Conn
+	keepalive     time.Duration
+	send          chan socketMessage
+	done          chan struct{}
+	subscriptions map[string]socketSubscription
+
+	// Closed once the client can no longer be written to
+	failed   chan struct{}
+	failOnce sync.Once
+}
+
+func (s *albumSocket) handle(request socketRequest) socketMessage {
+	reply := socketMessage{ID: request.ID}
+	switch {
+	case request.ID == "":
+		reply.Type, reply.Error = socketError, "id is required"
+	case request.Type == socketSubscribe:
+		subscription := socketSubscription{
+			albums: domain.AlbumEventFilter{AlbumIDs: request.AlbumIDs, ArtistIDs: request.ArtistIDs},
+		}
+		var err error
+		if request.Filter != nil {
+			subscription.filter, err = request.Filter.albumFilter()
+		}
+		_, replaced := s.subscriptions[request.ID]
+		if err == nil && !replaced && len(s.subscriptions) >= maxSocketSubscriptions {
+			err = fmt.Errorf("at most %d subscriptions are allowed", maxSocketSubscriptions)
+		}
+		if err != nil {
+			reply.Type, reply.Error = socketError, err.Error()
+			break
+		}
+		s.subscriptions[request.ID] = subscription
+		reply.Type = socketSubscribed
+	case request.Type == socketUnsubscribe:
+		if _, ok := s.subscriptions[request.ID]; !ok {
+			reply.Type, reply.Error = socketError, fmt.Sprintf("no subscription %q", request.ID)
+			break
+		}
+		delete(s.subscriptions, request.ID)
+		reply.Type = socketUnsubscribed
+	default:
+		reply.Type, reply.Error = socketError, fmt.Sprintf("unknown message type %q, expected %s or %s", request.Type, socketSubscribe, socketUnsubscribe)
+	}
+	return reply
+}
+
+// notify queues the event for the subscriptions it matches, false when the queue is full.
+func (s *albumSocket) notify(event domain.AlbumEvent) bool {
+	var matched []string
+	for id, subscription := range s.subscriptions {
+		if subscription.matches(event) {
+			matched = append(matched, id)
+		}
+	}
+	if len(matched) == 0 {
+		return true
+	}
+	sort.Strings(matched)
+	data := newAlbumEventData(event)
+	return s.enqueue(socketMessage{Type: socketEvent, Subscriptions: matched, Event: &data})
+}
+
+// enqueue hands the message to the writer without waiting, false when the queue is full.
+func (s *albumSocket) enqueue(message socketMessage) bool {
+	select {
+	case s.send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// read passes the messages of the client on until reading fails. Malformed messages are
+// answered right away.
+func (s *albumSocket) read(requests chan<- socketRequest, readErr chan<- error) {
+	s.conn.SetReadLimit(maxSocketMessage)
+	s.conn.SetReadDeadline(time.Now().Add(2 * s.keepalive))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(2 * s.keepalive))
+	})
+	for {
+		messageType, data, err := s.conn.ReadMessage()
+		if err != nil {
+			readErr <- err
+			return
+		}
+		s.conn.SetReadDeadline(time.Now().Add(2 * s.keepalive))
+		var request socketRequest
+		if err = json.Unmarshal(data, &request); err != nil || messageType != websocket.TextMessage {
+			if err == nil {
+				err = errors.New("messages must be JSON text")
+			}
+			if !s.enqueue(socketMessage{Type: socketError, Error: err.Error()}) {
+				s.fail()
+			}
+			continue
+		}
+		select {
+		case requests <- request:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// write sends the queued messages and the pings until the connection is done or fails.
+func (s *albumSocket) write() {
+	ticker := time.NewTicker(s.keepalive)
+	defer ticker.Stop()
+	for {
+		var err error
+		select {
+		case <-s.done:
+			return
+		case message := <-s.send:
+			s.conn.SetWriteDeadline(time.Now().Add(socketWriteTimeout))
+			err = s.conn.WriteJSON(message)
+		case <-ticker.C:
+			err = s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(socketWriteTimeout))
+		}
+		if err != nil {
+			s.fail()
+			return
+		}
+	}
+}
+
+func (s *albumSocket) fail() {
+	s.failOnce.Do(func() { close(s.failed) })
+}
+
+// close tells the client why the connection ends, without waiting for a client that does not read.
+func (s *albumSocket) close(code int, reason string) {
+	message := websocket.FormatCloseMessage(code, reason)
+	s.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/services"
+	"github.com/ssitko/hex-domain/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeSocket(t *testing.T) {
+	broker := services.NewAlbumEventBroker(10, 10000)
+	r := gin.New()
+	r.Use(Problems(logger.NewLogger()), IdentifyEditor("secret"))
+	r.GET("/v1/ws", RequireEditor, NewSocketHandler(broker, 20*time.Millisecond, 4).ServeSocket)
+	server := httptest.NewServer(r)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/ws"
+
+	dial := func(t *testing.T) *websocket.Conn {
+		conn, res, err := websocket.DefaultDialer.Dial(url, http.Header{"Authorization": {"Bearer secret"}})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+	exchange := func(t *testing.T, conn *websocket.Conn, request socketRequest) socketMessage {
+		assert.NoError(t, conn.WriteJSON(request))
+		var reply socketMessage
+		assert.NoError(t, conn.ReadJSON(&reply))
+		return reply
+	}
+	rock := domain.Album{ID: 1, ArtistID: 7, Title: "Abbey Road", Genres: []domain.Genre{{Name: "Rock"}}, Status: domain.AlbumPublished}
+	jazz := domain.Album{ID: 2, ArtistID: 8, Title: "Kind of Blue", Genres: []domain.Genre{{Name: "Jazz"}}, Status: domain.AlbumDraft}
+
+	t.Run("GET :: /v1/ws endpoint requires the editor token on upgrade", func(t *testing.T) {
+		_, res, err := websocket.DefaultDialer.Dial(url, nil)
+		assert.ErrorIs(t, err, websocket.ErrBadHandshake)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+		_, res, err = websocket.DefaultDialer.Dial(url, http.Header{"Authorization": {"Bearer wrong"}})
+		assert.ErrorIs(t, err, websocket.ErrBadHandshake)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("GET :: /v1/ws endpoint takes the token as a subprotocol", func(t *testing.T) {
+		dialer := websocket.Dialer{Subprotocols: []string{socketProtocol, "bearer.secret"}}
+		conn, _, err := dialer.Dial(url, nil)
+		if assert.NoError(t, err) {
+			defer conn.Close()
+			assert.Equal(t, socketProtocol, conn.Subprotocol())
+		}
+	})
+
+	t.Run("GET :: /v1/ws endpoint sends changes of subscribed albums and filters", func(t *testing.T) {
+		conn := dial(t)
+		assert.Equal(t, socketMessage{Type: socketSubscribed, ID: "abbey"}, exchange(t, conn, socketRequest{Type: socketSubscribe, ID: "abbey", AlbumIDs: []uint{1}}))
+		assert.Equal(t, socketMessage{Type: socketSubscribed, ID: "rock"}, exchange(t, conn, socketRequest{Type: socketSubscribe, ID: "rock", Filter: &socketFilter{Genres: []string{" rock "}}}))
+		assert.Equal(t, socketMessage{Type: socketSubscribed, ID: "drafts"}, exchange(t, conn, socketRequest{Type: socketSubscribe, ID: "drafts", Filter: &socketFilter{Status: "draft"}}))
+
+		broker.Publish(domain.AlbumUpdated, rock)
+		broker.Publish(domain.AlbumDeleted, jazz)
+
+		var message socketMessage
+		assert.NoError(t, conn.ReadJSON(&message))
+		assert.Equal(t, socketEvent, message.Type)
+		assert.Equal(t, []string{"abbey", "rock"}, message.Subscriptions)
+		assert.Equal(t, domain.AlbumUpdated, message.Event.Type)
+		assert.Equal(t, "Abbey Road", message.Event.Album.Title)
+
+		message = socketMessage{}
+		assert.NoError(t, conn.ReadJSON(&message))
+		assert.Equal(t, []string{"drafts"}, message.Subscriptions)
+		assert.Equal(t, domain.AlbumDeleted, message.Event.Type)
+		assert.Equal(t, uint(2), message.Event.AlbumID)
+		assert.Nil(t, message.Event.Album)
+	})
+
+	t.Run("GET :: /v1/ws endpoint stops sending after unsubscribing", func(t *testing.T) {
+		conn := dial(t)
+		exchange(t, conn, socketRequest{Type: socketSubscribe, ID: "abbey", AlbumIDs: []uint{1}})
+		exchange(t, conn, socketRequest{Type: socketSubscribe, ID: "artist", ArtistIDs: []uint{8}})
+		assert.Equal(t, socketMessage{Type: socketUnsubscribed, ID: "abbey"}, exchange(t, conn, socketRequest{Type: socketUnsubscribe, ID: "abbey"}))
+
+		broker.Publish(domain.AlbumUpdated, rock)
+		broker.Publish(domain.AlbumUpdated, jazz)
+
+		var message socketMessage
+		assert.NoError(t, conn.ReadJSON(&message))
+		assert.Equal(t, []string{"artist"}, message.Subscriptions)
+		assert.Equal(t, uint(2), message.Event.AlbumID)
+	})
+
+	t.Run("GET :: /v1/ws endpoint answers invalid messages with errors", func(t *testing.T) {
+		conn := dial(t)
+		reply := exchange(t, conn, socketRequest{Type: socketSubscribe, ID: "bad", Filter: &socketFilter{Status: "gone"}})
+		assert.Equal(t, socketError, reply.Type)
+		assert.Equal(t, "bad", reply.ID)
+		assert.Contains(t, reply.Error, "status must be")
+
+		assert.Equal(t, socketError, exchange(t, conn, socketRequest{Type: socketUnsubscribe, ID: "missing"}).Type)
+		assert.Equal(t, "id is required", exchange(t, conn, socketRequest{Type: socketSubscribe}).Error)
+		assert.Contains(t, exchange(t, conn, socketRequest{Type: "publish", ID: "x"}).Error, "unknown message type")
+
+		assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("{")))
+		var message socketMessage
+		assert.NoError(t, conn.ReadJSON(&message))
+		assert.Equal(t, socketError, message.Type)
+	})
+
+	t.Run("GET :: /v1/ws endpoint pings clients and drops those missing the pong", func(t *testing.T) {
+		conn := dial(t)
+		pings := make(chan struct{}, 10)
+		conn.SetPingHandler(func(string) error {
+			pings <- struct{}{}
+			return conn.WriteControl(websocket.PongMessage, nil, time.Now().Add(time.Second))
+		})
+		go conn.ReadMessage()
+		select {
+		case <-pings:
+		case <-time.After(time.Second):
+			t.Fatal("no ping received")
+		}
+
+		silent := dial(t)
+		silent.SetPingHandler(func(string) error { return nil })
+		start := time.Now()
+		silent.SetReadDeadline(start.Add(5 * time.Second))
+		_, _, err := silent.ReadMessage()
+		assert.Error(t, err)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("GET :: /v1/ws endpoint disconnects clients too slow to keep up", func(t *testing.T) {
+		conn := dial(t)
+		conn.SetPingHandler(nil)
+		exchange(t, conn, socketRequest{Type: socketSubscribe, ID: "all"})
+		large := rock
+		large.Title = strings.Repeat("a", 64<<10)
+		const published = 1000
+		for i := 0; i < published; i++ {
+			broker.Publish(domain.AlbumUpdated, large)
+		}
+
+		var received int
+		conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				assert.False(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+				break
+			}
+			received++
+		}
+		assert.Less(t, received, published)
+	})
+
+	t.Run("GET :: /v1/ws endpoint closes sockets on shutdown", func(t *testing.T) {
+		conn := dial(t)
+		exchange(t, conn, socketRequest{Type: socketSubscribe, ID: "all"})
+		broker.Close()
+		_, _, err := conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseTryAgainLater))
+	})
+}
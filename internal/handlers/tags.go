@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Handles tag HTTP requests.
+type TagHandler struct {
+	service domain.TagService
+}
+
+func NewTagHandler(service domain.TagService) *TagHandler {
+	return &TagHandler{service: service}
+}
+
+func (h *TagHandler) GetTags(c *gin.Context) {
+	tags, err := h.service.GetAllTags()
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *TagHandler) GetTagByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	tag, err := h.service.GetTagByID(id)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, tag)
+}
+
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	var tag domain.Tag
+	if err := c.ShouldBindJSON(&tag); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	createdTag, err := h.service.CreateTag(tag)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, createdTag)
+}
+
+func (h *TagHandler) UpdateTag(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	var tag domain.Tag
+	if err := c.ShouldBindJSON(&tag); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	tag.ID = uint(id)
+	updatedTag, err := h.service.UpdateTag(tag)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, updatedTag)
+}
+
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := h.service.DeleteTag(id); err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/openapi"
+)
+
+// ValidateRequests checks path, query and header parameters as well as JSON bodies of requests
+// to documented operations against spec, violations are answered with 400 (415 for a body of
+// an undocumented media type) before the handler runs. With validateResponses JSON responses
+// are checked too and replaced by a server error when they do not match, meant for development.
+func ValidateRequests(spec *openapi.Document, validateResponses bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		operation := spec.Operation(c.Request.Method, c.FullPath())
+		if operation == nil {
+			c.Next()
+			return
+		}
+		fields := validateParameters(c, spec, operation)
+		bodyFields, err := validateBody(c, spec, operation)
+		if err != nil {
+			abortWithError(c, http.StatusUnsupportedMediaType, err)
+			return
+		}
+		if fields = append(fields, bodyFields...); len(fields) > 0 {
+			abortWithError(c, http.StatusBadRequest, &fieldsError{fields: fields})
+			return
+		}
+		if !validateResponses {
+			c.Next()
+			return
+		}
+
+		writer := &bufferedWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+		if violations := validateResponse(spec, operation, writer); len(violations) > 0 {
+			c.Error(fmt.Errorf("%s %s response does not match the API description: %s", c.Request.Method, c.FullPath(), strings.Join(violations, "; ")))
+			return
+		}
+		writer.flush()
+	}
+}
+
+func validateParameters(c *gin.Context, spec *openapi.Document, operation *openapi.Operation) []FieldError {
+	var fields []FieldError
+	for _, param := range operation.Parameters {
+		var values []string
+		switch param.In {
+		case openapi.InPath:
+			if value := c.Param(param.Name); value != "" {
+				values = []string{value}
+			}
+		case openapi.InQuery:
+			values = c.QueryArray(param.Name)
+		case openapi.InHeader:
+			values = c.Request.Header.Values(param.Name)
+		}
+		pointer := "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(param.Name)
+		if len(values) == 0 {
+			if param.Required {
+				fields = append(fields, FieldError{In: param.In, Pointer: pointer, Detail: "is required"})
+			}
+			continue
+		}
+		schema := spec.Resolve(param.Schema)
+		value, ok := openapi.ParseParameter(schema, values)
+		if !ok {
+			fields = append(fields, FieldError{In: param.In, Pointer: pointer, Detail: "must be " + strings.Join(schema.Type, " or ")})
+			continue
+		}
+		for _, violation := range spec.ValidateRequest(schema, value) {
+			fields = append(fields, FieldError{In: param.In, Pointer: pointer + violation.Pointer, Detail: violation.Message})
+		}
+	}
+	return fields
+}
+
+// validateBody checks JSON bodies, the media type of other bodies is only checked to be documented.
+func validateBody(c *gin.Context, spec *openapi.Document, operation *openapi.Operation) ([]FieldError, error) {
+	body := operation.RequestBody
+	if body == nil {
+		return nil, nil
+	}
+	if c.Request.ContentLength == 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+		if body.Required {
+			return []FieldError{{In: "body", Pointer: "", Detail: "request body is required"}}, nil
+		}
+		return nil, nil
+	}
+	mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if err != nil {
+		// Clients commonly leave out the header for JSON
+		mediaType = "application/json"
+	}
+	content, ok := body.Content[mediaType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported media type %q", mediaType)
+	}
+	if mediaType != "application/json" {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return []FieldError{{In: "body", Pointer: "", Detail: err.Error()}}, nil
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(data))
+	value, err := openapi.DecodeJSON(data)
+	if err != nil {
+		return []FieldError{{In: "body", Pointer: "", Detail: "invalid JSON: " + err.Error()}}, nil
+	}
+	var fields []FieldError
+	for _, violation := range spec.ValidateRequest(content.Schema, value) {
+		fields = append(fields, FieldError{In: "body", Pointer: violation.Pointer, Detail: violation.Message})
+	}
+	return fields, nil
+}
+
+// validateResponse checks the status is documented and a JSON body matches its schema.
+func validateResponse(spec *openapi.Document, operation *openapi.Operation, writer *bufferedWriter) []string {
+	if writer.passthrough {
+		return nil
+	}
+	response, ok := operation.Responses[strconv.Itoa(writer.Status())]
+	if !ok {
+		return []string{fmt.Sprintf("status %d is not documented", writer.Status())}
+	}
+	if writer.body.Len() == 0 {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(writer.Header().Get("Content-Type"))
+	content, ok := response.Content[mediaType]
+	if !ok {
+		return []string{fmt.Sprintf("media type %q is not documented for status %d", mediaType, writer.Status())}
+	}
+	value, err := openapi.DecodeJSON(writer.body.Bytes())
+	if err != nil {
+		return []string{"invalid JSON: " + err.Error()}
+	}
+	var violations []string
+	for _, violation := range spec.ValidateResponse(content.Schema, value) {
+		violations = append(violations, violation.String())
+	}
+	return violations
+}
+
+// bufferedWriter holds back JSON responses until they are validated, other content such as
+// images and JSON streams is written through.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body        bytes.Buffer
+	passthrough bool
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	if !w.passthrough && w.body.Len() == 0 {
+		mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+		w.passthrough = mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")
+	}
+	if w.passthrough {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(data string) (int, error) {
+	return w.Write([]byte(data))
+}
+
+// Flush is deferred to flush while the body is held back.
+func (w *bufferedWriter) Flush() {
+	if w.passthrough {
+		w.ResponseWriter.Flush()
+	}
+}
+
+func (w *bufferedWriter) flush() {
+	if !w.passthrough && w.body.Len() > 0 {
+		w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
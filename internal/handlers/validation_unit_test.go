@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRequests(t *testing.T) {
+	mockService := new(MockAlbumService)
+	r := gin.New()
+	r.Use(Problems(logger.NewLogger()), ValidateRequests(AlbumAPISpec(), true))
+	handler := NewAlbumHandler(mockService)
+	r.GET("/v1/albums", handler.GetAlbums)
+	r.GET("/v1/albums/:id", handler.GetAlbumByID)
+	r.POST("/v1/albums", handler.CreateAlbum)
+	r.GET("/v1/albums/:id/tracks", func(c *gin.Context) {
+		c.JSON(http.StatusOK, []gin.H{{"title": 7, "position": 1}})
+	})
+	r.DELETE("/v1/albums/:id", func(c *gin.Context) {
+		c.Status(http.StatusAccepted)
+	})
+
+	album := domain.Album{ID: 1, Title: "Abbey Road", Price: 9.99, Status: domain.AlbumPublished}
+
+	t.Run("GET :: /v1/albums/:id endpoint passes valid requests and responses", func(t *testing.T) {
+		mockService.On("GetAlbumByID", 1).Return(album, nil)
+
+		req, _ := http.NewRequest("GET", "/v1/albums/1?quantity=2&currency=", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"title":"Abbey Road"`)
+	})
+
+	t.Run("GET :: /v1/albums/:id endpoint rejects invalid parameters", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/albums/abc?quantity=0", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, []FieldError{
+			{In: "path", Pointer: "/id", Detail: "must be integer"},
+			{In: "query", Pointer: "/quantity", Detail: "must be at least 1"},
+		}, decodeProblem(t, w).Errors)
+
+		req, _ = http.NewRequest("GET", "/v1/albums?in_stock=maybe&genre_match=some", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, []FieldError{
+			{In: "query", Pointer: "/genre_match", Detail: "must be one of any, all"},
+			{In: "query", Pointer: "/in_stock", Detail: "must be boolean"},
+		}, decodeProblem(t, w).Errors)
+	})
+
+	t.Run("POST :: /v1/albums endpoint rejects bodies not matching the schema", func(t *testing.T) {
+		body := `{"title": 5, "price": "free", "status": "bogus", "release_date": "2024-13", "tracks": [{"position": 1.5}]}`
+		req, _ := http.NewRequest("POST", "/v1/albums", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, []FieldError{
+			{In: "body", Pointer: "/price", Detail: "must be number"},
+			{In: "body", Pointer: "/release_date", Detail: `must match ^(\d{4}-\d{2}-\d{2})?$`},
+			{In: "body", Pointer: "/title", Detail: "must be string"},
+			{In: "body", Pointer: "/tracks/0/position", Detail: "must be integer"},
+		}, decodeProblem(t, w).Errors)
+		mockService.AssertNotCalled(t, "CreateAlbum")
+	})
+
+	t.Run("POST :: /v1/albums endpoint rejects other media types", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v1/albums", strings.NewReader("title=Abbey Road"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+		req, _ = http.NewRequest("POST", "/v1/albums", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "request body is required", decodeProblem(t, w).Errors[0].Detail)
+	})
+
+	t.Run("GET :: responses not matching the spec are replaced in development", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/albums/1/tracks", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "position")
+
+		req, _ = http.NewRequest("DELETE", "/v1/albums/1", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
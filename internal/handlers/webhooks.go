@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Handles webhook HTTP requests. Secrets are only returned when a webhook is created.
+type WebhookHandler struct {
+	service domain.WebhookService
+}
+
+func NewWebhookHandler(service domain.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	webhooks, err := h.service.GetAllWebhooks()
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	redacted := make([]domain.Webhook, len(webhooks))
+	for i, webhook := range webhooks {
+		redacted[i] = webhook.Redacted()
+	}
+	c.JSON(http.StatusOK, redacted)
+}
+
+func (h *WebhookHandler) GetWebhookByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	webhook, err := h.service.GetWebhookByID(id)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, webhook.Redacted())
+}
+
+// CreateWebhook registers a webhook, the response carries the secret, generated unless given.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var webhook domain.Webhook
+	if err := c.ShouldBindJSON(&webhook); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	createdWebhook, err := h.service.CreateWebhook(webhook)
+	if errors.Is(err, domain.ErrInvalidWebhook) {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, createdWebhook)
+}
+
+// UpdateWebhook replaces the URL and events, a secret given replaces the current one.
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	var webhook domain.Webhook
+	if err := c.ShouldBindJSON(&webhook); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	webhook.ID = uint(id)
+	updatedWebhook, err := h.service.UpdateWebhook(webhook)
+	if errors.Is(err, domain.ErrInvalidWebhook) {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, updatedWebhook.Redacted())
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := h.service.DeleteWebhook(id); err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetWebhookDeliveries lists the latest deliveries of a webhook, newest first.
+func (h *WebhookHandler) GetWebhookDeliveries(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	deliveries, err := h.service.GetWebhookDeliveries(id)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// RedeliverWebhook queues a dead delivery again.
+func (h *WebhookHandler) RedeliverWebhook(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	deliveryID, err := strconv.Atoi(c.Param("delivery_id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	delivery, err := h.service.RedeliverWebhook(id, deliveryID)
+	if errors.Is(err, domain.ErrDeliveryNotDead) {
+		abortWithError(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// TestWebhook pings the webhook and reports the outcome, a failed ping still answers 200 with
+// the delivery telling what went wrong.
+func (h *WebhookHandler) TestWebhook(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	delivery, err := h.service.TestWebhook(id)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockWebhookService is a mock implementation of the WebhookService interface (contained in the Webhook domain)
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) CreateWebhook(webhook domain.Webhook) (domain.Webhook, error) {
+	args := m.Called(webhook)
+	return args.Get(0).(domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) DeleteWebhook(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) GetAllWebhooks() ([]domain.Webhook, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) GetWebhookByID(id int) (domain.Webhook, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) GetWebhookDeliveries(id int) ([]domain.WebhookDelivery, error) {
+	args := m.Called(id)
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) RedeliverWebhook(id int, deliveryID int) (domain.WebhookDelivery, error) {
+	args := m.Called(id, deliveryID)
+	return args.Get(0).(domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) TestWebhook(id int) (domain.WebhookDelivery, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) UpdateWebhook(webhook domain.Webhook) (domain.Webhook, error) {
+	args := m.Called(webhook)
+	return args.Get(0).(domain.Webhook), args.Error(1)
+}
+
+func TestWebhookHandlers(t *testing.T) {
+	mockService := new(MockWebhookService)
+	r := gin.New()
+	r.Use(Problems(logger.NewLogger()))
+	handler := NewWebhookHandler(mockService)
+	r.GET("/webhooks", handler.GetWebhooks)
+	r.GET("/webhooks/:id", handler.GetWebhookByID)
+	r.POST("/webhooks", handler.CreateWebhook)
+	r.PUT("/webhooks/:id", handler.UpdateWebhook)
+	r.DELETE("/webhooks/:id", handler.DeleteWebhook)
+	r.POST("/webhooks/:id/test", handler.TestWebhook)
+	r.GET("/webhooks/:id/deliveries", handler.GetWebhookDeliveries)
+	r.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", handler.RedeliverWebhook)
+
+	events := []domain.AlbumEventType{domain.AlbumCreated, domain.AlbumDeleted}
+	stored := domain.Webhook{ID: 1, URL: "https://example.com/hooks", Events: events, Secret: "whsec_0123456789abcdef"}
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("POST :: /webhooks endpoint returns the secret once", func(t *testing.T) {
+		mockService.On("CreateWebhook", domain.Webhook{URL: stored.URL, Events: events}).Return(stored, nil).Once()
+
+		w := send("POST", "/webhooks", `{"url":"https://example.com/hooks","events":["created","deleted"]}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var response domain.Webhook
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, stored.Secret, response.Secret)
+
+		mockService.On("GetAllWebhooks").Return([]domain.Webhook{stored}, nil).Once()
+		w = send("GET", "/webhooks", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "secret")
+
+		mockService.On("GetWebhookByID", 1).Return(stored, nil).Once()
+		w = send("GET", "/webhooks/1", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "secret")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("POST :: /webhooks endpoint rejects invalid webhooks", func(t *testing.T) {
+		webhook := domain.Webhook{URL: "ftp://example.com", Events: events}
+		mockService.On("CreateWebhook", webhook).Return(domain.Webhook{}, fmt.Errorf("%w: url must be an absolute http or https URL", domain.ErrInvalidWebhook)).Once()
+
+		w := send("POST", "/webhooks", `{"url":"ftp://example.com","events":["created","deleted"]}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = send("POST", "/webhooks", `{"events":["created"]}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("PUT :: /webhooks/:id endpoint keeps the secret hidden", func(t *testing.T) {
+		webhook := domain.Webhook{ID: 1, URL: stored.URL, Events: []domain.AlbumEventType{domain.AlbumUpdated}}
+		updated := stored
+		updated.Events = webhook.Events
+		mockService.On("UpdateWebhook", webhook).Return(updated, nil).Once()
+
+		w := send("PUT", "/webhooks/1", `{"url":"https://example.com/hooks","events":["updated"]}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "secret")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("DELETE :: /webhooks/:id endpoint", func(t *testing.T) {
+		mockService.On("DeleteWebhook", 1).Return(nil).Once()
+
+		w := send("DELETE", "/webhooks/1", "")
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("POST :: /webhooks/:id/test endpoint reports the ping", func(t *testing.T) {
+		ping := domain.WebhookDelivery{ID: 9, WebhookID: 1, EventType: domain.WebhookPing, Status: domain.DeliveryDead, Attempts: 1, ResponseStatus: 404, Error: "webhook answered 404"}
+		mockService.On("TestWebhook", 1).Return(ping, nil).Once()
+		mockService.On("TestWebhook", 2).Return(domain.WebhookDelivery{}, fmt.Errorf("webhook %w", domain.ErrNotFound)).Once()
+
+		w := send("POST", "/webhooks/1/test", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response domain.WebhookDelivery
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, ping, response)
+
+		w = send("POST", "/webhooks/2/test", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("GET :: /webhooks/:id/deliveries endpoint and redelivery", func(t *testing.T) {
+		dead := domain.WebhookDelivery{ID: 3, WebhookID: 1, EventType: "created", Status: domain.DeliveryDead, Attempts: domain.MaxWebhookAttempts}
+		mockService.On("GetWebhookDeliveries", 1).Return([]domain.WebhookDelivery{dead}, nil).Once()
+		requeued := dead
+		requeued.Status, requeued.Attempts = domain.DeliveryPending, 0
+		mockService.On("RedeliverWebhook", 1, 3).Return(requeued, nil).Once()
+		mockService.On("RedeliverWebhook", 1, 4).Return(domain.WebhookDelivery{}, domain.ErrDeliveryNotDead).Once()
+
+		w := send("GET", "/webhooks/1/deliveries", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		var deliveries []domain.WebhookDelivery
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &deliveries))
+		assert.Equal(t, []domain.WebhookDelivery{dead}, deliveries)
+
+		w = send("POST", "/webhooks/1/deliveries/3/redeliver", "")
+		assert.Equal(t, http.StatusAccepted, w.Code)
+
+		w = send("POST", "/webhooks/1/deliveries/4/redeliver", "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,21 @@
+package alerts
+
+import (
+	"fmt"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/pkg/logger"
+)
+
+// Stock alerter writing low stock warnings to the service log.
+type LogStockAlerter struct {
+	logger logger.Logger
+}
+
+func NewLogStockAlerter(logger logger.Logger) *LogStockAlerter {
+	return &LogStockAlerter{logger: logger}
+}
+
+func (a *LogStockAlerter) LowStock(level domain.StockLevel) {
+	a.logger.Warn(fmt.Sprintf("Low stock: album %d in warehouse %s has %d available (threshold %d)", level.AlbumID, level.Warehouse, level.Available(), level.LowStockThreshold))
+}
@@ -0,0 +1,87 @@
+package images
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"golang.org/x/image/draw"
+
+	// WebP covers are decoded only, thumbnails are JPEG or PNG
+	_ "golang.org/x/image/webp"
+)
+
+// JPEG quality of thumbnails.
+const jpegQuality = 85
+
+// Resizer scales images with the Catmull-Rom kernel, in pure Go.
+type Resizer struct{}
+
+func NewResizer() *Resizer {
+	return &Resizer{}
+}
+
+// Thumbnails fits source into a square of each size keeping its aspect ratio. Images are never
+// enlarged, a source smaller than size is only re-encoded.
+func (r *Resizer) Thumbnails(source []byte, sizes []int, contentTypes []string) ([]domain.Thumbnail, error) {
+	// Covers stored before the upload limits may still be too large to decode
+	if err := domain.CheckCoverDimensions(source); err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(source))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidCover, err)
+	}
+	var thumbnails []domain.Thumbnail
+	for _, size := range sizes {
+		scaled := scale(img, size)
+		for _, contentType := range contentTypes {
+			content, err := encode(scaled, contentType)
+			if err != nil {
+				return nil, err
+			}
+			thumbnails = append(thumbnails, domain.Thumbnail{Size: size, ContentType: contentType, Content: content})
+		}
+	}
+	return thumbnails, nil
+}
+
+func scale(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > size || height > size {
+		if width >= height {
+			width, height = size, max(1, height*size/width)
+		} else {
+			width, height = max(1, width*size/height), size
+		}
+	}
+	scaled := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, bounds, draw.Src, nil)
+	return scaled
+}
+
+func encode(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	switch contentType {
+	case "image/jpeg":
+		// JPEG has no alpha channel, transparent areas turn white
+		opaque := image.NewRGBA(img.Bounds())
+		draw.Draw(opaque, opaque.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(opaque, opaque.Bounds(), img, img.Bounds().Min, draw.Over)
+		if err := jpeg.Encode(&buf, opaque, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+	case "image/png":
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported thumbnail type %s", domain.ErrInvalidCover, contentType)
+	}
+	return buf.Bytes(), nil
+}
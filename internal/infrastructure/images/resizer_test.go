@@ -0,0 +1,73 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func testPNG(width, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+// pngHeader is the start of a PNG declaring the dimensions, without any image data.
+func pngHeader(width, height uint32) []byte {
+	chunk := binary.BigEndian.AppendUint32([]byte("IHDR"), width)
+	chunk = binary.BigEndian.AppendUint32(chunk, height)
+	chunk = append(chunk, 8, 6, 0, 0, 0)
+	header := binary.BigEndian.AppendUint32([]byte("\x89PNG\r\n\x1a\n"), uint32(len(chunk)-4))
+	header = append(header, chunk...)
+	return binary.BigEndian.AppendUint32(header, crc32.ChecksumIEEE(chunk))
+}
+
+func TestResizer(t *testing.T) {
+	resizer := NewResizer()
+
+	t.Run("Thumbnails :: keeps aspect ratio and never enlarges", func(t *testing.T) {
+		thumbnails, err := resizer.Thumbnails(testPNG(400, 200), []int{64, 1024}, domain.ThumbnailTypes)
+		assert.Nil(t, err)
+		assert.Len(t, thumbnails, 4)
+
+		expected := map[int]image.Point{64: {64, 32}, 1024: {400, 200}}
+		for _, thumbnail := range thumbnails {
+			config, format, err := image.DecodeConfig(bytes.NewReader(thumbnail.Content))
+			assert.Nil(t, err)
+			assert.Equal(t, "image/"+format, thumbnail.ContentType)
+			assert.Equal(t, expected[thumbnail.Size], image.Point{config.Width, config.Height})
+		}
+	})
+
+	t.Run("Thumbnails :: portrait images fit the height", func(t *testing.T) {
+		thumbnails, err := resizer.Thumbnails(testPNG(100, 300), []int{64}, []string{"image/jpeg"})
+		assert.Nil(t, err)
+		config, _, err := image.DecodeConfig(bytes.NewReader(thumbnails[0].Content))
+		assert.Nil(t, err)
+		assert.Equal(t, 21, config.Width)
+		assert.Equal(t, 64, config.Height)
+	})
+
+	t.Run("Thumbnails :: rejects oversized images before decoding", func(t *testing.T) {
+		_, err := resizer.Thumbnails(pngHeader(30000, 30000), []int{64}, domain.ThumbnailTypes)
+		assert.ErrorIs(t, err, domain.ErrCoverTooLarge)
+	})
+
+	t.Run("Thumbnails :: undecodable image", func(t *testing.T) {
+		_, err := resizer.Thumbnails([]byte("\x89PNG\r\n\x1a\nbroken"), []int{64}, domain.ThumbnailTypes)
+		assert.ErrorIs(t, err, domain.ErrInvalidCover)
+	})
+}
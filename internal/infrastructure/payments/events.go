@@ -0,0 +1,42 @@
+package payments
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Webhook body shared by the gateway adapters, e.g.
+// {"id": "evt_1", "type": "payment_intent.succeeded", "data": {"object": {"id": "pi_1", "status": "succeeded"}}}
+type webhookEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object struct {
+			ID     string `json:"id"`
+			Status string `json:"status"`
+		} `json:"object"`
+	} `json:"data"`
+}
+
+func decodeWebhookEvent(payload []byte) (domain.PaymentEvent, error) {
+	var event webhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return domain.PaymentEvent{}, fmt.Errorf("%w: %s", domain.ErrInvalidWebhookEvent, err)
+	}
+	status, ok := gatewayStatuses[event.Data.Object.Status]
+	if !ok || event.Data.Object.ID == "" {
+		return domain.PaymentEvent{}, fmt.Errorf("%w: unsupported type %q", domain.ErrInvalidWebhookEvent, event.Type)
+	}
+	return domain.PaymentEvent{ID: event.ID, GatewayRef: event.Data.Object.ID, Status: status}, nil
+}
+
+// Stripe-like payment intent statuses mapped to domain payment statuses.
+var gatewayStatuses = map[string]domain.PaymentStatus{
+	"requires_capture": domain.PaymentAuthorized,
+	"succeeded":        domain.PaymentCaptured,
+	"refunded":         domain.PaymentRefunded,
+	"canceled":         domain.PaymentFailed,
+	"failed":           domain.PaymentFailed,
+}
@@ -0,0 +1,107 @@
+package payments
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Magic card numbers understood by the fake gateway.
+const (
+	CardSuccess           = "4242424242424242"
+	CardDeclined          = "4000000000000002"
+	CardInsufficientFunds = "4000000000009995"
+	CardCaptureFails      = "4000000000000341"
+)
+
+// Deterministic in-memory payment gateway for tests and local runs.
+// Outcomes depend on the card number only and references are sequential (fake_pi_1, fake_pi_2, ...).
+type FakeGateway struct {
+	secret string
+	now    func() time.Time
+
+	mu       sync.Mutex
+	sequence int
+	intents  map[string]*fakeIntent
+}
+
+type fakeIntent struct {
+	card     string
+	amount   int64
+	captured int64
+	refunded int64
+	status   domain.PaymentStatus
+}
+
+func NewFakeGateway(webhookSecret string) *FakeGateway {
+	return &FakeGateway{secret: webhookSecret, now: time.Now, intents: map[string]*fakeIntent{}}
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (g *FakeGateway) Authorize(authorization domain.PaymentAuthorization) (domain.PaymentResult, error) {
+	card := strings.ReplaceAll(authorization.Source, " ", "")
+	switch card {
+	case CardDeclined:
+		return domain.PaymentResult{Status: domain.PaymentFailed, Reason: "card_declined"}, fmt.Errorf("%w: card_declined", domain.ErrPaymentDeclined)
+	case CardInsufficientFunds:
+		return domain.PaymentResult{Status: domain.PaymentFailed, Reason: "insufficient_funds"}, fmt.Errorf("%w: insufficient_funds", domain.ErrPaymentDeclined)
+	case CardSuccess, CardCaptureFails:
+	default:
+		return domain.PaymentResult{Status: domain.PaymentFailed, Reason: "invalid_number"}, fmt.Errorf("%w: invalid_number", domain.ErrPaymentDeclined)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.sequence++
+	ref := fmt.Sprintf("fake_pi_%d", g.sequence)
+	g.intents[ref] = &fakeIntent{card: card, amount: minorUnits(authorization.Amount), status: domain.PaymentAuthorized}
+	return domain.PaymentResult{GatewayRef: ref, Status: domain.PaymentAuthorized}, nil
+}
+
+func (g *FakeGateway) Capture(gatewayRef string, amount float64, currency string) (domain.PaymentResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	intent, ok := g.intents[gatewayRef]
+	if !ok {
+		return domain.PaymentResult{}, fmt.Errorf("%w: unknown payment %s", domain.ErrPaymentGateway, gatewayRef)
+	}
+	if intent.status != domain.PaymentAuthorized || minorUnits(amount) > intent.amount {
+		return domain.PaymentResult{}, domain.ErrInvalidPaymentState
+	}
+	if intent.card == CardCaptureFails {
+		intent.status = domain.PaymentFailed
+		return domain.PaymentResult{GatewayRef: gatewayRef, Status: domain.PaymentFailed, Reason: "capture_failed"}, fmt.Errorf("%w: capture_failed", domain.ErrPaymentDeclined)
+	}
+	intent.captured, intent.status = minorUnits(amount), domain.PaymentCaptured
+	return domain.PaymentResult{GatewayRef: gatewayRef, Status: domain.PaymentCaptured}, nil
+}
+
+func (g *FakeGateway) Refund(gatewayRef string, amount float64, currency string) (domain.PaymentResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	intent, ok := g.intents[gatewayRef]
+	if !ok {
+		return domain.PaymentResult{}, fmt.Errorf("%w: unknown payment %s", domain.ErrPaymentGateway, gatewayRef)
+	}
+	if intent.status != domain.PaymentCaptured || intent.refunded+minorUnits(amount) > intent.captured {
+		return domain.PaymentResult{}, domain.ErrInvalidPaymentState
+	}
+	intent.refunded += minorUnits(amount)
+	if intent.refunded == intent.captured {
+		intent.status = domain.PaymentRefunded
+	}
+	return domain.PaymentResult{GatewayRef: gatewayRef, Status: intent.status}, nil
+}
+
+func (g *FakeGateway) VerifyWebhook(payload []byte, signature string) (domain.PaymentEvent, error) {
+	if err := verifySignature(g.secret, payload, signature, g.now()); err != nil {
+		return domain.PaymentEvent{}, err
+	}
+	return decodeWebhookEvent(payload)
+}
@@ -0,0 +1,153 @@
+package payments
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Payment gateway adapter for a Stripe-like REST API using manual capture payment intents.
+type HTTPGateway struct {
+	baseURL       string
+	apiKey        string
+	webhookSecret string
+	client        *http.Client
+	now           func() time.Time
+}
+
+func NewHTTPGateway(baseURL, apiKey, webhookSecret string) *HTTPGateway {
+	return &HTTPGateway{
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		apiKey:        apiKey,
+		webhookSecret: webhookSecret,
+		client:        &http.Client{Timeout: 10 * time.Second},
+		now:           time.Now,
+	}
+}
+
+// Payment intent or refund object returned by the API.
+type apiObject struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+type apiError struct {
+	Error struct {
+		Type    string `json:"type"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (g *HTTPGateway) Name() string {
+	return "http"
+}
+
+func (g *HTTPGateway) Authorize(authorization domain.PaymentAuthorization) (domain.PaymentResult, error) {
+	form := url.Values{
+		"amount":              {strconv.FormatInt(minorUnits(authorization.Amount), 10)},
+		"currency":            {strings.ToLower(authorization.Currency)},
+		"payment_method":      {authorization.Source},
+		"capture_method":      {"manual"},
+		"confirm":             {"true"},
+		"metadata[reference]": {authorization.Reference},
+	}
+	object, err := g.post("/v1/payment_intents", form, "authorize-"+authorization.Reference)
+	if err != nil {
+		return domain.PaymentResult{Status: domain.PaymentFailed, Reason: declineReason(err)}, err
+	}
+	return g.result(object)
+}
+
+func (g *HTTPGateway) Capture(gatewayRef string, amount float64, currency string) (domain.PaymentResult, error) {
+	form := url.Values{"amount_to_capture": {strconv.FormatInt(minorUnits(amount), 10)}}
+	object, err := g.post("/v1/payment_intents/"+url.PathEscape(gatewayRef)+"/capture", form, "capture-"+gatewayRef)
+	if err != nil {
+		return domain.PaymentResult{GatewayRef: gatewayRef, Status: domain.PaymentFailed, Reason: declineReason(err)}, err
+	}
+	return g.result(object)
+}
+
+func (g *HTTPGateway) Refund(gatewayRef string, amount float64, currency string) (domain.PaymentResult, error) {
+	form := url.Values{
+		"payment_intent": {gatewayRef},
+		"amount":         {strconv.FormatInt(minorUnits(amount), 10)},
+	}
+	if _, err := g.post("/v1/refunds", form, ""); err != nil {
+		return domain.PaymentResult{}, err
+	}
+	return domain.PaymentResult{GatewayRef: gatewayRef, Status: domain.PaymentRefunded}, nil
+}
+
+func (g *HTTPGateway) VerifyWebhook(payload []byte, signature string) (domain.PaymentEvent, error) {
+	if err := verifySignature(g.webhookSecret, payload, signature, g.now()); err != nil {
+		return domain.PaymentEvent{}, err
+	}
+	return decodeWebhookEvent(payload)
+}
+
+func (g *HTTPGateway) result(object apiObject) (domain.PaymentResult, error) {
+	status, ok := gatewayStatuses[object.Status]
+	if !ok {
+		return domain.PaymentResult{}, fmt.Errorf("%w: unexpected payment status %q", domain.ErrPaymentGateway, object.Status)
+	}
+	return domain.PaymentResult{GatewayRef: object.ID, Status: status}, nil
+}
+
+func (g *HTTPGateway) post(path string, form url.Values, idempotencyKey string) (apiObject, error) {
+	req, err := http.NewRequest(http.MethodPost, g.baseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return apiObject{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+g.apiKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return apiObject{}, fmt.Errorf("%w: unreachable: %s", domain.ErrPaymentGateway, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var body apiError
+		json.NewDecoder(resp.Body).Decode(&body)
+		if resp.StatusCode == http.StatusPaymentRequired || body.Error.Type == "card_error" {
+			return apiObject{}, &declineError{code: body.Error.Code}
+		}
+		return apiObject{}, fmt.Errorf("%w: status %d: %s", domain.ErrPaymentGateway, resp.StatusCode, body.Error.Message)
+	}
+	var object apiObject
+	if err := json.NewDecoder(resp.Body).Decode(&object); err != nil {
+		return apiObject{}, fmt.Errorf("%w: invalid response: %s", domain.ErrPaymentGateway, err)
+	}
+	return object, nil
+}
+
+// Card error reported by the API, matches domain.ErrPaymentDeclined.
+type declineError struct {
+	code string
+}
+
+func (e *declineError) Error() string {
+	return domain.ErrPaymentDeclined.Error() + ": " + e.code
+}
+
+func (e *declineError) Unwrap() error {
+	return domain.ErrPaymentDeclined
+}
+
+func declineReason(err error) string {
+	if decline, ok := err.(*declineError); ok {
+		return decline.code
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package payments
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "whsec_test"
+
+func TestFakeGateway(t *testing.T) {
+	gateway := NewFakeGateway(testSecret)
+
+	t.Run("Authorize :: success card can be captured and refunded", func(t *testing.T) {
+		result, err := gateway.Authorize(domain.PaymentAuthorization{Reference: "order-1", Amount: 19.98, Currency: "USD", Source: "4242 4242 4242 4242"})
+		assert.Nil(t, err)
+		assert.Equal(t, "fake_pi_1", result.GatewayRef)
+		assert.Equal(t, domain.PaymentAuthorized, result.Status)
+
+		result, err = gateway.Capture(result.GatewayRef, 19.98, "USD")
+		assert.Nil(t, err)
+		assert.Equal(t, domain.PaymentCaptured, result.Status)
+
+		result, err = gateway.Refund(result.GatewayRef, 9.99, "USD")
+		assert.Nil(t, err)
+		assert.Equal(t, domain.PaymentCaptured, result.Status)
+
+		result, err = gateway.Refund(result.GatewayRef, 9.99, "USD")
+		assert.Nil(t, err)
+		assert.Equal(t, domain.PaymentRefunded, result.Status)
+
+		_, err = gateway.Refund(result.GatewayRef, 0.01, "USD")
+		assert.ErrorIs(t, err, domain.ErrInvalidPaymentState)
+	})
+
+	t.Run("Authorize :: magic cards are declined", func(t *testing.T) {
+		for card, reason := range map[string]string{CardDeclined: "card_declined", CardInsufficientFunds: "insufficient_funds", "1234": "invalid_number"} {
+			result, err := gateway.Authorize(domain.PaymentAuthorization{Amount: 1, Source: card})
+			assert.ErrorIs(t, err, domain.ErrPaymentDeclined)
+			assert.Equal(t, reason, result.Reason)
+		}
+	})
+
+	t.Run("Capture :: capture failure card", func(t *testing.T) {
+		result, err := gateway.Authorize(domain.PaymentAuthorization{Amount: 5, Source: CardCaptureFails})
+		assert.Nil(t, err)
+		result, err = gateway.Capture(result.GatewayRef, 5, "USD")
+		assert.ErrorIs(t, err, domain.ErrPaymentDeclined)
+		assert.Equal(t, domain.PaymentFailed, result.Status)
+	})
+
+	t.Run("VerifyWebhook :: signed payload", func(t *testing.T) {
+		payload := []byte(`{"id":"evt_1","type":"payment_intent.succeeded","data":{"object":{"id":"fake_pi_1","status":"succeeded"}}}`)
+		event, err := gateway.VerifyWebhook(payload, SignWebhook(testSecret, payload, time.Now()))
+		assert.Nil(t, err)
+		assert.Equal(t, domain.PaymentEvent{ID: "evt_1", GatewayRef: "fake_pi_1", Status: domain.PaymentCaptured}, event)
+
+		_, err = gateway.VerifyWebhook(payload, SignWebhook("other", payload, time.Now()))
+		assert.ErrorIs(t, err, domain.ErrInvalidWebhookSignature)
+
+		_, err = gateway.VerifyWebhook(payload, SignWebhook(testSecret, payload, time.Now().Add(-time.Hour)))
+		assert.ErrorIs(t, err, domain.ErrInvalidWebhookSignature)
+	})
+
+	t.Run("VerifyWebhook :: rejects everything without a secret", func(t *testing.T) {
+		payload := []byte(`{"id":"evt_1","type":"payment_intent.succeeded","data":{"object":{"id":"fake_pi_1","status":"succeeded"}}}`)
+		_, err := NewFakeGateway("").VerifyWebhook(payload, SignWebhook("", payload, time.Now()))
+		assert.ErrorIs(t, err, domain.ErrInvalidWebhookSignature)
+	})
+}
+
+func TestHTTPGateway(t *testing.T) {
+	// Local stub of the Stripe-like API
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/payment_intents", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer sk_test", r.Header.Get("Authorization"))
+		assert.Equal(t, "manual", r.FormValue("capture_method"))
+		if r.FormValue("payment_method") == "pm_card_chargeDeclined" {
+			w.WriteHeader(http.StatusPaymentRequired)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]string{"type": "card_error", "code": "card_declined"}})
+			return
+		}
+		assert.Equal(t, "1998", r.FormValue("amount"))
+		assert.Equal(t, "usd", r.FormValue("currency"))
+		json.NewEncoder(w).Encode(map[string]string{"id": "pi_123", "status": "requires_capture"})
+	})
+	mux.HandleFunc("POST /v1/payment_intents/{id}/capture", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"id": r.PathValue("id"), "status": "succeeded"})
+	})
+	mux.HandleFunc("POST /v1/refunds", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "pi_123", r.FormValue("payment_intent"))
+		json.NewEncoder(w).Encode(map[string]string{"id": "re_1", "status": "succeeded"})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	gateway := NewHTTPGateway(server.URL, "sk_test", testSecret)
+
+	t.Run("Authorize :: capture and refund", func(t *testing.T) {
+		result, err := gateway.Authorize(domain.PaymentAuthorization{Reference: "order-1", Amount: 19.98, Currency: "USD", Source: "pm_card_visa"})
+		assert.Nil(t, err)
+		assert.Equal(t, domain.PaymentResult{GatewayRef: "pi_123", Status: domain.PaymentAuthorized}, result)
+
+		result, err = gateway.Capture("pi_123", 19.98, "USD")
+		assert.Nil(t, err)
+		assert.Equal(t, domain.PaymentCaptured, result.Status)
+
+		result, err = gateway.Refund("pi_123", 19.98, "USD")
+		assert.Nil(t, err)
+		assert.Equal(t, domain.PaymentRefunded, result.Status)
+	})
+
+	t.Run("Authorize :: card error", func(t *testing.T) {
+		result, err := gateway.Authorize(domain.PaymentAuthorization{Reference: "order-2", Amount: 1, Currency: "USD", Source: "pm_card_chargeDeclined"})
+		assert.ErrorIs(t, err, domain.ErrPaymentDeclined)
+		assert.Equal(t, "card_declined", result.Reason)
+	})
+}
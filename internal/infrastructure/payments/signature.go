@@ -0,0 +1,30 @@
+package payments
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/infrastructure/signature"
+)
+
+// Maximum age of a signed webhook, protects against replayed notifications.
+const webhookTolerance = 5 * time.Minute
+
+// SignWebhook produces the signature header of a gateway notification, see signature.Sign.
+func SignWebhook(secret string, payload []byte, at time.Time) string {
+	return signature.Sign(secret, payload, at)
+}
+
+func verifySignature(secret string, payload []byte, header string, now time.Time) error {
+	if err := signature.Verify(secret, payload, header, now, webhookTolerance); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidWebhookSignature, err)
+	}
+	return nil
+}
+
+// Amounts travel as integer minor units (cents).
+func minorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
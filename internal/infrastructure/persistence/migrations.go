@@ -0,0 +1,212 @@
+package persistence
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"gorm.io/gorm"
+)
+
+// Applied migrations are recorded here so each one runs once.
+type schemaMigration struct {
+	ID        string `gorm:"primaryKey;size:191"`
+	AppliedAt time.Time
+}
+
+// Data migrations that AutoMigrate cannot express, applied in order before AutoMigrate.
+type migration struct {
+	ID      string
+	Migrate func(db *gorm.DB) error
+}
+
+var migrations = []migration{
+	{ID: "0001_artists_from_album_strings", Migrate: migrateAlbumArtists},
+	{ID: "0002_album_release_metadata", Migrate: migrateAlbumReleaseMetadata},
+	// Also replaces the published flag of the former 0003_album_published, where it ran
+	{ID: "0004_album_status", Migrate: migrateAlbumStatus},
+	{ID: "0005_album_barcode_unique", Migrate: migrateAlbumBarcodeUnique},
+}
+
+// Migrate applies pending data migrations and brings the schema in line with domain entities.
+func Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		var applied int64
+		if err := db.Model(&schemaMigration{}).Where("id = ?", m.ID).Count(&applied).Error; err != nil {
+			return err
+		}
+		if applied > 0 {
+			continue
+		}
+		if err := m.Migrate(db); err != nil {
+			return fmt.Errorf("migration %s: %s", m.ID, err)
+		}
+		if err := db.Create(&schemaMigration{ID: m.ID, AppliedAt: time.Now().UTC()}).Error; err != nil {
+			return err
+		}
+	}
+	return db.AutoMigrate(
+		&domain.Artist{}, &domain.Genre{}, &domain.Tag{}, &domain.Album{}, &domain.Track{},
+		&domain.StockLevel{}, &domain.Reservation{},
+		&domain.Order{}, &domain.OrderItem{}, &domain.Payment{},
+		&domain.Promotion{}, &domain.PriceChange{},
+		&domain.ScheduledChange{}, &domain.Lease{}, &domain.Job{},
+		&domain.Review{}, &domain.ReviewFlag{},
+		&domain.Webhook{}, &domain.WebhookDelivery{},
+	)
+}
+
+// Replaces the free-text albums.artist column with artist rows, merging spelling
+// variants by their normalized name. Albums without an artist get the "Unknown Artist".
+func migrateAlbumArtists(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable("albums") || !migrator.HasColumn("albums", "artist") {
+		return nil
+	}
+	if err := db.AutoMigrate(&domain.Artist{}); err != nil {
+		return err
+	}
+	if !migrator.HasColumn(&domain.Album{}, "ArtistID") {
+		if err := migrator.AddColumn(&domain.Album{}, "ArtistID"); err != nil {
+			return err
+		}
+	}
+
+	type artistSpelling struct {
+		Artist string
+		Albums int
+	}
+	var spellings []artistSpelling
+	// NULL counts as an empty name, so those albums get an artist too
+	err := db.Table("albums").
+		Select("COALESCE(artist, '') AS artist, COUNT(*) AS albums").
+		Group("COALESCE(artist, '')").
+		Order("albums DESC, artist").
+		Scan(&spellings).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		artists := map[string]uint{}
+		for _, spelling := range spellings {
+			name := spelling.Artist
+			if strings.TrimSpace(name) == "" {
+				name = "Unknown Artist"
+			}
+			key := domain.NormalizeArtistName(name)
+			id, ok := artists[key]
+			if !ok {
+				// The most used spelling becomes the artist name
+				artist := domain.Artist{Name: name, NormalizedName: key}
+				if err := tx.Create(&artist).Error; err != nil {
+					return err
+				}
+				id = artist.ID
+				artists[key] = id
+			}
+			albums := tx.Table("albums").Where("artist = ?", spelling.Artist)
+			if spelling.Artist == "" {
+				albums = tx.Table("albums").Where("artist = '' OR artist IS NULL")
+			}
+			if err := albums.Update("artist_id", id).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return migrator.DropColumn("albums", "artist")
+}
+
+// Adds the release metadata columns and the label/barcode lookup index to an existing albums table.
+func migrateAlbumReleaseMetadata(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable("albums") {
+		return nil
+	}
+	for _, field := range []string{"ReleaseDate", "Label", "CatalogNumber", "Format", "Barcode"} {
+		if migrator.HasColumn(&domain.Album{}, field) {
+			continue
+		}
+		if err := migrator.AddColumn(&domain.Album{}, field); err != nil {
+			return err
+		}
+	}
+	for _, index := range []string{"ReleaseDate", "idx_albums_label_barcode"} {
+		if migrator.HasIndex(&domain.Album{}, index) {
+			continue
+		}
+		if err := migrator.CreateIndex(&domain.Album{}, index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Adds the editorial workflow status. Existing albums stay visible as published, unless a
+// published flag left by an earlier release hid them.
+func migrateAlbumStatus(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable("albums") || migrator.HasColumn(&domain.Album{}, "Status") {
+		return nil
+	}
+	for _, field := range []string{"Status", "PublishedAt"} {
+		if err := migrator.AddColumn(&domain.Album{}, field); err != nil {
+			return err
+		}
+	}
+	status := "'published'"
+	if migrator.HasColumn("albums", "published") {
+		status = "CASE WHEN published THEN 'published' ELSE 'draft' END"
+	}
+	if err := db.Exec("UPDATE albums SET status = " + status).Error; err != nil {
+		return err
+	}
+	if err := db.Exec("UPDATE albums SET published_at = ? WHERE status = 'published'", time.Now().UTC()).Error; err != nil {
+		return err
+	}
+	if migrator.HasColumn("albums", "published") {
+		return migrator.DropColumn("albums", "published")
+	}
+	return nil
+}
+
+// Makes the label/barcode index unique, AutoMigrate creates it again. Albums without a barcode
+// store NULL instead of an empty string so they do not collide.
+func migrateAlbumBarcodeUnique(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable("albums") {
+		return nil
+	}
+	type duplicate struct {
+		Label   string
+		Barcode string
+	}
+	var duplicates []duplicate
+	err := db.Table("albums").
+		Select("label, barcode").
+		Where("barcode <> ''").
+		Group("label, barcode").
+		Having("COUNT(*) > 1").
+		Scan(&duplicates).Error
+	if err != nil {
+		return err
+	}
+	if len(duplicates) > 0 {
+		return fmt.Errorf("barcode %s is used by several albums of label %q, resolve duplicates before upgrading", duplicates[0].Barcode, duplicates[0].Label)
+	}
+	if err := db.Exec("UPDATE albums SET barcode = NULL WHERE barcode = ''").Error; err != nil {
+		return err
+	}
+	if migrator.HasIndex("albums", "idx_albums_label_barcode") {
+		return migrator.DropIndex("albums", "idx_albums_label_barcode")
+	}
+	return nil
+}
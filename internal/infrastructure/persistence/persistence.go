@@ -1,12 +1,15 @@
 package persistence
 
 import (
+	"errors"
+	"fmt"
 	"log"
 
 	"github.com/ssitko/hex-domain/config"
-	album "github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/domain"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func NewPersistenceLayer() DB {
@@ -18,7 +21,9 @@ func NewPersistenceLayer() DB {
 	}
 
 	// Perform DB migrations
-	mysqlDB.AutoMigrate(&album.Album{})
+	if err := Migrate(mysqlDB); err != nil {
+		log.Fatalf("failed to migrate database: %s", err)
+	}
 
 	// Return gorm wrapper that implements DB interface type
 	return NewGormDBWrapper(mysqlDB)
@@ -30,6 +35,23 @@ type DB interface {
 	First(dest interface{}, conds ...interface{}) error
 	Save(value interface{}) error
 	Delete(value interface{}, conds ...interface{}) error
+	FirstOrCreate(dest interface{}, conds ...interface{}) error
+
+	// Many-to-many association management, model must have its primary key set
+	AppendAssociation(model interface{}, association string, values ...interface{}) error
+	DeleteAssociation(model interface{}, association string, values ...interface{}) error
+
+	// Scoping methods return a new DB limited to the given conditions
+	Where(query interface{}, args ...interface{}) DB
+	Preload(query string, args ...interface{}) DB
+	Omit(columns ...string) DB
+	OmitAssociations() DB
+	LockForUpdate() DB
+	Order(value interface{}) DB
+	Limit(limit int) DB
+
+	// Transaction runs fn against a DB bound to a single transaction
+	Transaction(fn func(tx DB) error) error
 }
 
 type GormDBWrapper struct {
@@ -42,7 +64,7 @@ func NewGormDBWrapper(db *gorm.DB) *GormDBWrapper {
 
 // Implement the interface methods
 func (g *GormDBWrapper) Create(value interface{}) error {
-	return g.db.Create(value).Error
+	return g.translate(g.db.Create(value).Error)
 }
 
 func (g *GormDBWrapper) Find(dest interface{}, conds ...interface{}) error {
@@ -50,13 +72,80 @@ func (g *GormDBWrapper) Find(dest interface{}, conds ...interface{}) error {
 }
 
 func (g *GormDBWrapper) First(dest interface{}, conds ...interface{}) error {
-	return g.db.First(dest, conds...).Error
+	err := g.db.First(dest, conds...).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrNotFound
+	}
+	return err
 }
 
 func (g *GormDBWrapper) Save(value interface{}) error {
-	return g.db.Save(value).Error
+	return g.translate(g.db.Save(value).Error)
 }
 
 func (g *GormDBWrapper) Delete(value interface{}, conds ...interface{}) error {
 	return g.db.Delete(value, conds...).Error
 }
+
+func (g *GormDBWrapper) FirstOrCreate(dest interface{}, conds ...interface{}) error {
+	return g.translate(g.db.FirstOrCreate(dest, conds...).Error)
+}
+
+func (g *GormDBWrapper) AppendAssociation(model interface{}, association string, values ...interface{}) error {
+	return g.db.Model(model).Association(association).Append(values...)
+}
+
+func (g *GormDBWrapper) DeleteAssociation(model interface{}, association string, values ...interface{}) error {
+	return g.db.Model(model).Association(association).Delete(values...)
+}
+
+func (g *GormDBWrapper) Where(query interface{}, args ...interface{}) DB {
+	return NewGormDBWrapper(g.db.Where(query, args...))
+}
+
+func (g *GormDBWrapper) Preload(query string, args ...interface{}) DB {
+	return NewGormDBWrapper(g.db.Preload(query, args...))
+}
+
+func (g *GormDBWrapper) Omit(columns ...string) DB {
+	return NewGormDBWrapper(g.db.Omit(columns...))
+}
+
+// OmitAssociations skips saving any associated entities, only the model's own columns are written.
+func (g *GormDBWrapper) OmitAssociations() DB {
+	return NewGormDBWrapper(g.db.Omit(clause.Associations))
+}
+
+// LockForUpdate adds a SELECT ... FOR UPDATE row lock, meaningful inside a transaction.
+func (g *GormDBWrapper) LockForUpdate() DB {
+	return NewGormDBWrapper(g.db.Clauses(clause.Locking{Strength: "UPDATE"}))
+}
+
+func (g *GormDBWrapper) Order(value interface{}) DB {
+	return NewGormDBWrapper(g.db.Order(value))
+}
+
+func (g *GormDBWrapper) Limit(limit int) DB {
+	return NewGormDBWrapper(g.db.Limit(limit))
+}
+
+// translate reports unique index violations as domain.ErrDuplicate.
+func (g *GormDBWrapper) translate(err error) error {
+	if err == nil {
+		return nil
+	}
+	translated := err
+	if translator, ok := g.db.Dialector.(gorm.ErrorTranslator); ok {
+		translated = translator.Translate(err)
+	}
+	if errors.Is(translated, gorm.ErrDuplicatedKey) {
+		return fmt.Errorf("%w: %s", domain.ErrDuplicate, err)
+	}
+	return err
+}
+
+func (g *GormDBWrapper) Transaction(fn func(tx DB) error) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewGormDBWrapper(tx))
+	})
+}
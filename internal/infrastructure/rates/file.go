@@ -0,0 +1,81 @@
+package rates
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// File based exchange rate provider.
+// Reads a JSON or CSV rates file and reloads it whenever the file changes on disk.
+type FileProvider struct {
+	path string
+
+	mu      sync.Mutex
+	table   domain.RateTable
+	modTime time.Time
+	size    int64
+}
+
+func NewFileProvider(path string) (*FileProvider, error) {
+	p := &FileProvider{path: path}
+	if _, err := p.current(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *FileProvider) Rate(from, to string) (domain.ExchangeRate, error) {
+	table, err := p.current()
+	if err != nil {
+		return domain.ExchangeRate{}, err
+	}
+	return table.Rate(from, to)
+}
+
+// current returns the loaded table, reloading the file first if it was modified.
+func (p *FileProvider) current() (domain.RateTable, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return domain.RateTable{}, fmt.Errorf("error reading rates file, %s", err)
+	}
+	if p.table.Rates != nil && info.ModTime().Equal(p.modTime) && info.Size() == p.size {
+		return p.table, nil
+	}
+
+	table, err := p.load()
+	if err != nil {
+		// Keep serving the last good table if a reload fails.
+		if p.table.Rates != nil {
+			return p.table, nil
+		}
+		return domain.RateTable{}, err
+	}
+	p.table, p.modTime, p.size = table, info.ModTime(), info.Size()
+	return p.table, nil
+}
+
+func (p *FileProvider) load() (domain.RateTable, error) {
+	f, err := os.Open(p.path)
+	if err != nil {
+		return domain.RateTable{}, fmt.Errorf("error reading rates file, %s", err)
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(p.path)) {
+	case ".json":
+		return decodeJSON(f)
+	case ".csv":
+		return decodeCSV(f)
+	default:
+		return domain.RateTable{}, fmt.Errorf("unsupported rates file format %s", p.path)
+	}
+}
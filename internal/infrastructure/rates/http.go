@@ -0,0 +1,81 @@
+package rates
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// HTTP exchange rate provider.
+// Fetches a JSON rates document (same format as the rates file) from a remote API
+// and caches it for the configured TTL.
+type HTTPProvider struct {
+	endpoint string
+	base     string
+	ttl      time.Duration
+	client   *http.Client
+
+	mu        sync.Mutex
+	table     domain.RateTable
+	fetchedAt time.Time
+}
+
+func NewHTTPProvider(endpoint string, ttl time.Duration) *HTTPProvider {
+	return &HTTPProvider{
+		endpoint: endpoint,
+		base:     domain.BaseCurrency,
+		ttl:      ttl,
+		client:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (p *HTTPProvider) Rate(from, to string) (domain.ExchangeRate, error) {
+	table, err := p.current()
+	if err != nil {
+		return domain.ExchangeRate{}, err
+	}
+	return table.Rate(from, to)
+}
+
+func (p *HTTPProvider) current() (domain.RateTable, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.table.Rates != nil && time.Since(p.fetchedAt) < p.ttl {
+		return p.table, nil
+	}
+	table, err := p.fetch()
+	if err != nil {
+		// Fall back to stale rates rather than failing every request.
+		if p.table.Rates != nil {
+			return p.table, nil
+		}
+		return domain.RateTable{}, err
+	}
+	p.table, p.fetchedAt = table, time.Now()
+	return p.table, nil
+}
+
+func (p *HTTPProvider) fetch() (domain.RateTable, error) {
+	u, err := url.Parse(p.endpoint)
+	if err != nil {
+		return domain.RateTable{}, fmt.Errorf("invalid rates endpoint, %s", err)
+	}
+	query := u.Query()
+	query.Set("base", p.base)
+	u.RawQuery = query.Encode()
+
+	resp, err := p.client.Get(u.String())
+	if err != nil {
+		return domain.RateTable{}, fmt.Errorf("error fetching rates, %s", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return domain.RateTable{}, fmt.Errorf("error fetching rates, unexpected status %d", resp.StatusCode)
+	}
+	return decodeJSON(resp.Body)
+}
@@ -0,0 +1,86 @@
+package rates
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Date layout used by rates files and the rates HTTP API.
+const dateLayout = "2006-01-02"
+
+// JSON representation of a rates table, e.g.
+// {"base": "USD", "date": "2025-01-31", "rates": {"EUR": 0.96, "GBP": 0.81}}
+type ratesDocument struct {
+	Base  string             `json:"base"`
+	Date  string             `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+func decodeJSON(r io.Reader) (domain.RateTable, error) {
+	var doc ratesDocument
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return domain.RateTable{}, fmt.Errorf("invalid rates document: %s", err)
+	}
+	date, err := time.Parse(dateLayout, doc.Date)
+	if err != nil {
+		return domain.RateTable{}, fmt.Errorf("invalid rates date %q", doc.Date)
+	}
+	table := domain.RateTable{Base: strings.ToUpper(doc.Base), Date: date, Rates: map[string]float64{}}
+	for currency, rate := range doc.Rates {
+		table.Rates[strings.ToUpper(currency)] = rate
+	}
+	return table, validate(table)
+}
+
+// CSV rates are expected with a header row:
+// date,base,currency,rate
+// 2025-01-31,USD,EUR,0.96
+func decodeCSV(r io.Reader) (domain.RateTable, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return domain.RateTable{}, fmt.Errorf("invalid rates csv: %s", err)
+	}
+	if len(records) < 2 {
+		return domain.RateTable{}, fmt.Errorf("rates csv has no rows")
+	}
+	table := domain.RateTable{Rates: map[string]float64{}}
+	for i, record := range records[1:] {
+		if len(record) != 4 {
+			return domain.RateTable{}, fmt.Errorf("rates csv line %d: expected 4 columns", i+2)
+		}
+		date, err := time.Parse(dateLayout, record[0])
+		if err != nil {
+			return domain.RateTable{}, fmt.Errorf("rates csv line %d: invalid date %q", i+2, record[0])
+		}
+		rate, err := strconv.ParseFloat(record[3], 64)
+		if err != nil {
+			return domain.RateTable{}, fmt.Errorf("rates csv line %d: invalid rate %q", i+2, record[3])
+		}
+		base := strings.ToUpper(record[1])
+		if table.Base != "" && (table.Base != base || !table.Date.Equal(date)) {
+			return domain.RateTable{}, fmt.Errorf("rates csv line %d: mixed base currency or date", i+2)
+		}
+		table.Base, table.Date = base, date
+		table.Rates[strings.ToUpper(record[2])] = rate
+	}
+	return table, validate(table)
+}
+
+func validate(table domain.RateTable) error {
+	if table.Base == "" {
+		return fmt.Errorf("rates table has no base currency")
+	}
+	for currency, rate := range table.Rates {
+		if rate <= 0 {
+			return fmt.Errorf("rate for %s must be positive", currency)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package rates
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("JSON :: converts and reloads on change", func(t *testing.T) {
+		path := filepath.Join(dir, "rates.json")
+		assert.Nil(t, os.WriteFile(path, []byte(`{"base":"USD","date":"2025-01-31","rates":{"EUR":0.5,"GBP":0.25}}`), 0o644))
+
+		provider, err := NewFileProvider(path)
+		assert.Nil(t, err)
+
+		rate, err := provider.Rate("USD", "eur")
+		assert.Nil(t, err)
+		assert.Equal(t, "EUR", rate.To)
+		assert.Equal(t, 5.0, rate.Convert(10))
+		assert.Equal(t, "2025-01-31", rate.Date.Format(time.DateOnly))
+
+		cross, err := provider.Rate("EUR", "GBP")
+		assert.Nil(t, err)
+		assert.Equal(t, 0.5, cross.Rate)
+
+		assert.Nil(t, os.WriteFile(path, []byte(`{"base":"USD","date":"2025-02-01","rates":{"EUR":0.75,"GBP":0.25}}`), 0o644))
+		later := time.Now().Add(time.Second)
+		assert.Nil(t, os.Chtimes(path, later, later))
+
+		rate, err = provider.Rate("USD", "EUR")
+		assert.Nil(t, err)
+		assert.Equal(t, 0.75, rate.Rate)
+		assert.Equal(t, "2025-02-01", rate.Date.Format(time.DateOnly))
+	})
+
+	t.Run("CSV :: converts", func(t *testing.T) {
+		path := filepath.Join(dir, "rates.csv")
+		assert.Nil(t, os.WriteFile(path, []byte("date,base,currency,rate\n2025-01-31,USD,EUR,0.5\n2025-01-31,USD,GBP,0.25\n"), 0o644))
+
+		provider, err := NewFileProvider(path)
+		assert.Nil(t, err)
+
+		rate, err := provider.Rate("USD", "GBP")
+		assert.Nil(t, err)
+		assert.Equal(t, 0.25, rate.Rate)
+	})
+
+	t.Run("CSV :: unsupported currency", func(t *testing.T) {
+		provider, err := NewFileProvider(filepath.Join(dir, "rates.csv"))
+		assert.Nil(t, err)
+
+		_, err = provider.Rate("USD", "JPY")
+		assert.ErrorIs(t, err, domain.ErrUnsupportedCurrency)
+	})
+}
+
+func TestHTTPProvider(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "USD", r.URL.Query().Get("base"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"base":"USD","date":"2025-01-31","rates":{"EUR":0.5}}`))
+	}))
+	defer server.Close()
+
+	t.Run("GET :: rates are fetched and cached", func(t *testing.T) {
+		provider := NewHTTPProvider(server.URL, time.Hour)
+
+		rate, err := provider.Rate("USD", "EUR")
+		assert.Nil(t, err)
+		assert.Equal(t, 0.5, rate.Rate)
+
+		_, err = provider.Rate("USD", "EUR")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("GET :: upstream failure", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer failing.Close()
+
+		_, err := NewHTTPProvider(failing.URL, time.Hour).Rate("USD", "EUR")
+		assert.NotNil(t, err)
+	})
+}
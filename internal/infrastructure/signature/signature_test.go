@@ -0,0 +1,31 @@
+package signature
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "whsec_0123456789abcdef"
+
+func TestSignature(t *testing.T) {
+	payload := []byte(`{"id":"evt_1"}`)
+	now := time.Now()
+
+	t.Run("Verify :: accepts fresh signatures of the secret", func(t *testing.T) {
+		assert.Nil(t, Verify(testSecret, payload, Sign(testSecret, payload, now), now, time.Minute))
+		assert.Nil(t, Verify(testSecret, payload, "v1=abc,"+Sign(testSecret, payload, now), now, time.Minute))
+		assert.ErrorIs(t, Verify("other", payload, Sign(testSecret, payload, now), now, time.Minute), ErrMismatch)
+		assert.ErrorIs(t, Verify(testSecret, []byte(`{}`), Sign(testSecret, payload, now), now, time.Minute), ErrMismatch)
+	})
+
+	t.Run("Verify :: rejects stale and malformed signatures", func(t *testing.T) {
+		assert.ErrorIs(t, Verify(testSecret, payload, Sign(testSecret, payload, now.Add(-time.Hour)), now, time.Minute), ErrStale)
+		assert.ErrorIs(t, Verify(testSecret, payload, "v1=abc", now, time.Minute), ErrMalformedHeader)
+	})
+
+	t.Run("Verify :: rejects everything without a secret", func(t *testing.T) {
+		assert.ErrorIs(t, Verify("", payload, Sign("", payload, now), now, time.Minute), ErrNoSecret)
+	})
+}
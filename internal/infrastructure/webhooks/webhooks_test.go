@@ -0,0 +1,73 @@
+package webhooks
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/infrastructure/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "whsec_0123456789abcdef"
+
+func TestHTTPSender(t *testing.T) {
+	// Local receiver checking deliveries the way downstream services would
+	received := make(chan *http.Request, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /hooks", func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		if err := signature.Verify(testSecret, payload, r.Header.Get(SignatureHeader), time.Now(), 5*time.Minute); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		received <- r
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /failing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("POST /moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/hooks", http.StatusTemporaryRedirect)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	sender := NewHTTPSender(time.Second)
+	delivery := domain.WebhookDelivery{ID: 42, EventType: "updated", Payload: `{"id":"evt_1","type":"updated"}`}
+
+	t.Run("Send :: posts the signed payload", func(t *testing.T) {
+		status, err := sender.Send(domain.Webhook{URL: server.URL + "/hooks", Secret: testSecret}, delivery)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNoContent, status)
+		r := <-received
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "updated", r.Header.Get(EventHeader))
+		assert.Equal(t, "42", r.Header.Get(DeliveryHeader))
+	})
+
+	t.Run("Send :: reports rejected signatures, failures and redirects", func(t *testing.T) {
+		status, err := sender.Send(domain.Webhook{URL: server.URL + "/hooks", Secret: "whsec_other_secret"}, delivery)
+		assert.EqualError(t, err, "webhook answered 401")
+		assert.Equal(t, http.StatusUnauthorized, status)
+
+		status, err = sender.Send(domain.Webhook{URL: server.URL + "/failing", Secret: testSecret}, delivery)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+
+		status, err = sender.Send(domain.Webhook{URL: server.URL + "/moved", Secret: testSecret}, delivery)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusTemporaryRedirect, status)
+	})
+
+	t.Run("Send :: reports unreachable webhooks", func(t *testing.T) {
+		unreachable := httptest.NewServer(http.NotFoundHandler())
+		unreachable.Close()
+		status, err := sender.Send(domain.Webhook{URL: unreachable.URL, Secret: testSecret}, delivery)
+		assert.ErrorContains(t, err, "webhook unreachable")
+		assert.Equal(t, 0, status)
+	})
+}
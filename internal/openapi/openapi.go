@@ -0,0 +1,283 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// OpenAPI version of generated documents.
+const Version = "3.1.0"
+
+// Document is an OpenAPI 3.1 description of an HTTP API.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of a path by lower case method.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter locations.
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+)
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+	// Repeated query parameters are allowed when Explode is set together with an array schema
+	Explode *bool `json:"explode,omitempty"`
+	// Serialization of the value, deepObject sends the members of an object schema as name[member]
+	Style string `json:"style,omitempty"`
+}
+
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Schema is the JSON Schema 2020-12 subset used by the documents.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	ReadOnly             bool               `json:"readOnly,omitempty"`
+	ContentMediaType     string             `json:"contentMediaType,omitempty"`
+}
+
+// Types lists the JSON types a schema allows, a single type is written as a plain string.
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// Type returns a schema of the given JSON type.
+func Type(name string) *Schema {
+	return &Schema{Type: Types{name}}
+}
+
+// Nullable also allows null.
+func (s *Schema) Nullable() *Schema {
+	if len(s.Type) > 0 && !slices.Contains(s.Type, "null") {
+		s.Type = append(s.Type, "null")
+	}
+	return s
+}
+
+// New returns an empty document.
+func New(info Info) *Document {
+	return &Document{
+		OpenAPI:    Version,
+		Info:       info,
+		Paths:      map[string]*PathItem{},
+		Components: Components{Schemas: map[string]*Schema{}},
+	}
+}
+
+// Gin route parameters, :name and *name.
+var routeParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// Path converts a gin route path to an OpenAPI path template.
+func Path(route string) string {
+	return routeParam.ReplaceAllString(route, "{$1}")
+}
+
+// Add documents the operation of a gin route.
+func (d *Document) Add(method, route string, operation Operation) {
+	path := Path(route)
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+	(*item)[strings.ToLower(method)] = &operation
+}
+
+// Operation looks up the operation of a gin route, nil when it is not documented.
+func (d *Document) Operation(method, route string) *Operation {
+	item, ok := d.Paths[Path(route)]
+	if !ok {
+		return nil
+	}
+	return (*item)[strings.ToLower(method)]
+}
+
+// Resolve follows a component reference, other schemas are returned as they are.
+func (d *Document) Resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = d.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+// Define sets the schema used for values of v's type instead of reflecting it.
+func (d *Document) Define(v interface{}, schema *Schema) {
+	d.Components.Schemas[schemaName(reflect.TypeOf(v))] = schema
+}
+
+// Schema reflects the JSON encoding of v's type. Named structs become components and are
+// referenced, fields follow their json tags and embedded structs are inlined.
+func (d *Document) Schema(v interface{}) *Schema {
+	return d.reflect(reflect.TypeOf(v))
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (d *Document) reflect(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		schema := d.reflect(t.Elem())
+		if schema.Ref != "" {
+			return schema
+		}
+		return schema.Nullable()
+	}
+	if t == timeType {
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	}
+	if t.Name() != "" && t.PkgPath() != "" {
+		if _, ok := d.Components.Schemas[schemaName(t)]; ok {
+			return &Schema{Ref: "#/components/schemas/" + schemaName(t)}
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Type("boolean")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Type("integer")
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		minimum := 0.0
+		return &Schema{Type: Types{"integer"}, Minimum: &minimum}
+	case reflect.Float32, reflect.Float64:
+		return Type("number")
+	case reflect.String:
+		return Type("string")
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: Types{"string"}, Format: "byte"}
+		}
+		return &Schema{Type: Types{"array"}, Items: d.reflect(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: Types{"object"}, AdditionalProperties: d.reflect(t.Elem())}
+	case reflect.Struct:
+		name := schemaName(t)
+		schema := &Schema{Type: Types{"object"}, Properties: map[string]*Schema{}}
+		// Registered before the fields are reflected so recursive types terminate
+		d.Components.Schemas[name] = schema
+		d.addFields(schema, t)
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+func (d *Document) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				d.addFields(schema, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property := d.reflect(field.Type)
+		// Nil slices and maps are encoded as null unless omitted
+		if kind := field.Type.Kind(); (kind == reflect.Slice || kind == reflect.Map) && !strings.Contains(options, "omitempty") {
+			property.Nullable()
+		}
+		schema.Properties[name] = property
+	}
+}
+
+// schemaName is the exported form of the type name.
+func schemaName(t reflect.Type) string {
+	name := []rune(t.Name())
+	name[0] = unicode.ToUpper(name[0])
+	return string(name)
+}
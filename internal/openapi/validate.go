@@ -0,0 +1,245 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Violation locates a value that does not match its schema with a JSON Pointer (RFC 6901).
+type Violation struct {
+	Pointer string
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Pointer, v.Message)
+}
+
+// DecodeJSON decodes a document for validation, keeping numbers exact.
+func DecodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	return value, nil
+}
+
+// ValidateRequest checks a decoded request value, read only properties are not checked
+// as servers ignore them in requests.
+func (d *Document) ValidateRequest(schema *Schema, value interface{}) []Violation {
+	v := validation{doc: d, request: true}
+	v.validate(schema, value, "")
+	return v.violations
+}
+
+// ValidateResponse checks a decoded response value.
+func (d *Document) ValidateResponse(schema *Schema, value interface{}) []Violation {
+	v := validation{doc: d}
+	v.validate(schema, value, "")
+	return v.violations
+}
+
+// ParseParameter converts a path, query or header value to the JSON value its schema
+// describes, values of array schemas are given by repetition or separated by commas.
+func ParseParameter(schema *Schema, values []string) (interface{}, bool) {
+	if slices.Contains(schema.Type, "array") {
+		var items []interface{}
+		itemSchema := &Schema{}
+		if schema.Items != nil {
+			itemSchema = schema.Items
+		}
+		for _, value := range values {
+			for _, item := range strings.Split(value, ",") {
+				parsed, ok := ParseParameter(itemSchema, []string{strings.TrimSpace(item)})
+				if !ok {
+					return nil, false
+				}
+				items = append(items, parsed)
+			}
+		}
+		return items, true
+	}
+	value := values[0]
+	switch {
+	case slices.Contains(schema.Type, "integer"), slices.Contains(schema.Type, "number"):
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return nil, false
+		}
+		return json.Number(value), true
+	case slices.Contains(schema.Type, "boolean"):
+		parsed, err := strconv.ParseBool(value)
+		return parsed, err == nil
+	default:
+		return value, true
+	}
+}
+
+type validation struct {
+	doc        *Document
+	request    bool
+	violations []Violation
+}
+
+func (v *validation) fail(pointer, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validation) validate(schema *Schema, value interface{}, pointer string) {
+	if schema == nil {
+		return
+	}
+	if schema.Ref != "" {
+		target := v.doc.Resolve(schema)
+		if target == nil {
+			v.fail(pointer, "unknown schema %s", schema.Ref)
+			return
+		}
+		v.validate(target, value, pointer)
+		return
+	}
+
+	if len(schema.Type) > 0 && !slices.ContainsFunc(schema.Type, func(name string) bool { return hasType(value, name) }) {
+		v.fail(pointer, "must be %s", strings.Join(schema.Type, " or "))
+		return
+	}
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		allowed := make([]string, 0, len(schema.Enum))
+		for _, item := range schema.Enum {
+			allowed = append(allowed, fmt.Sprint(item))
+		}
+		v.fail(pointer, "must be one of %s", strings.Join(allowed, ", "))
+		return
+	}
+
+	switch value := value.(type) {
+	case string:
+		v.validateString(schema, value, pointer)
+	case json.Number:
+		number, _ := strconv.ParseFloat(string(value), 64)
+		if schema.Minimum != nil && number < *schema.Minimum {
+			v.fail(pointer, "must be at least %v", *schema.Minimum)
+		}
+		if schema.Maximum != nil && number > *schema.Maximum {
+			v.fail(pointer, "must be at most %v", *schema.Maximum)
+		}
+	case []interface{}:
+		for i, item := range value {
+			v.validate(schema.Items, item, fmt.Sprintf("%s/%d", pointer, i))
+		}
+	case map[string]interface{}:
+		v.validateObject(schema, value, pointer)
+	}
+}
+
+func (v *validation) validateString(schema *Schema, value string, pointer string) {
+	length := utf8.RuneCountInString(value)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		v.fail(pointer, "must have at least %d characters", *schema.MinLength)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		v.fail(pointer, "must have at most %d characters", *schema.MaxLength)
+	}
+	if schema.Pattern != "" {
+		if pattern, err := regexp.Compile(schema.Pattern); err == nil && !pattern.MatchString(value) {
+			v.fail(pointer, "must match %s", schema.Pattern)
+		}
+	}
+	switch schema.Format {
+	case "date":
+		if _, err := time.Parse(time.DateOnly, value); err != nil {
+			v.fail(pointer, "must be a date (YYYY-MM-DD)")
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			v.fail(pointer, "must be an RFC 3339 date-time")
+		}
+	}
+}
+
+func (v *validation) validateObject(schema *Schema, value map[string]interface{}, pointer string) {
+	for _, name := range schema.Required {
+		if _, ok := value[name]; !ok {
+			v.fail(pointer+"/"+escape(name), "is required")
+		}
+	}
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	// Report violations in a stable order
+	sort.Strings(names)
+	for _, name := range names {
+		property, ok := schema.Properties[name]
+		if !ok {
+			property = schema.AdditionalProperties
+		}
+		if property == nil || (v.request && v.readOnly(property)) {
+			continue
+		}
+		v.validate(property, value[name], pointer+"/"+escape(name))
+	}
+}
+
+func (v *validation) readOnly(schema *Schema) bool {
+	target := v.doc.Resolve(schema)
+	return schema.ReadOnly || (target != nil && target.ReadOnly)
+}
+
+func hasType(value interface{}, name string) bool {
+	switch name {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		rat, ok := new(big.Rat).SetString(string(number))
+		return ok && rat.IsInt()
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return false
+}
+
+// inEnum compares the JSON encodings, so typed constants match decoded values.
+func inEnum(enum []interface{}, value interface{}) bool {
+	encoded, _ := json.Marshal(value)
+	for _, item := range enum {
+		if itemEncoded, _ := json.Marshal(item); string(itemEncoded) == string(encoded) {
+			return true
+		}
+	}
+	return false
+}
+
+func escape(segment string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(segment)
+}
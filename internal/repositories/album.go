@@ -1,16 +1,38 @@
 package repositories
 
 import (
+	"errors"
+	"time"
+
 	album "github.com/ssitko/hex-domain/internal/domain"
 	"github.com/ssitko/hex-domain/internal/infrastructure/persistence"
 )
 
 // TODO: add comments
 type AlbumRepository interface {
-	GetAll() ([]album.Album, error)
+	GetAll(filter album.AlbumFilter) ([]album.Album, error)
+	// EachBatch passes the albums matching filter with an ID above afterID to fn in ID order,
+	// size albums at a time, stopping at the first error
+	EachBatch(filter album.AlbumFilter, afterID uint, size int, fn func(albums []album.Album) error) error
 	GetByID(id int) (album.Album, error)
+	GetByArtistID(artistID int) ([]album.Album, error)
+	GetByBarcode(code string) ([]album.Album, error)
+	GetTracks(albumID int) ([]album.Track, error)
+	GetPriceChanges(albumID int, from, to time.Time) ([]album.PriceChange, error)
+	ReplaceTracks(albumID int, tracks []album.Track) ([]album.Track, error)
+	AddGenre(albumID int, genre string) error
+	RemoveGenre(albumID int, genre string) error
+	AddTag(albumID int, tag string) error
+	RemoveTag(albumID int, tag string) error
 	Create(album album.Album) (album.Album, error)
 	Update(album album.Album) (album.Album, error)
+	// SaveAll creates or updates every album in one transaction
+	SaveAll(albums []album.Album) ([]album.Album, error)
+	// Modify applies change to the locked album and saves it, for the fields Update keeps
+	Modify(id int, change func(album *album.Album) error) (album.Album, error)
+	// GetStaleThumbnails lists albums whose cover thumbnails do not match the current cover and
+	// are due at now, see CoverImage.ThumbnailsDue
+	GetStaleThumbnails(now time.Time, limit int) ([]album.Album, error)
 	Delete(id int) error
 }
 
@@ -22,39 +44,377 @@ func NewGormAlbumRepository(db persistence.DB) *GormAlbumRepository {
 	return &GormAlbumRepository{db: db}
 }
 
-func (r *GormAlbumRepository) GetAll() ([]album.Album, error) {
+// withRelations preloads album associations, one query per association regardless of the number of albums.
+func (r *GormAlbumRepository) withRelations() persistence.DB {
+	return r.db.Preload("Artist").Preload("Tracks").Preload("Genres").Preload("Tags")
+}
+
+func (r *GormAlbumRepository) GetAll(filter album.AlbumFilter) ([]album.Album, error) {
 	var albums []album.Album
-	if err := r.db.Find(&albums); err != nil {
+	if err := applyAlbumFilter(r.withRelations(), filter).Find(&albums); err != nil {
 		return nil, err
 	}
+	for i := range albums {
+		albums[i].RefreshTracklist()
+	}
 	return albums, nil
 }
 
+// EachBatch pages through the albums by primary key, so only one batch is held in memory and
+// every batch is a cheap range scan however far the export has come.
+func (r *GormAlbumRepository) EachBatch(filter album.AlbumFilter, afterID uint, size int, fn func(albums []album.Album) error) error {
+	for {
+		var albums []album.Album
+		err := applyAlbumFilter(r.withRelations(), filter).
+			Where("albums.id > ?", afterID).Order("albums.id").Limit(size).Find(&albums)
+		if err != nil {
+			return err
+		}
+		if len(albums) == 0 {
+			return nil
+		}
+		for i := range albums {
+			albums[i].RefreshTracklist()
+		}
+		if err := fn(albums); err != nil {
+			return err
+		}
+		if len(albums) < size {
+			return nil
+		}
+		afterID = albums[len(albums)-1].ID
+	}
+}
+
 func (r *GormAlbumRepository) GetByID(id int) (album.Album, error) {
 	var album album.Album
-	if err := r.db.First(&album, id); err != nil {
+	if err := r.withRelations().First(&album, id); err != nil {
 		return album, err
 	}
+	album.RefreshTracklist()
 	return album, nil
 }
 
+func (r *GormAlbumRepository) GetByArtistID(artistID int) ([]album.Album, error) {
+	var albums []album.Album
+	if err := r.withRelations().Where("artist_id = ?", artistID).Find(&albums); err != nil {
+		return nil, err
+	}
+	for i := range albums {
+		albums[i].RefreshTracklist()
+	}
+	return albums, nil
+}
+
+func (r *GormAlbumRepository) GetByBarcode(code string) ([]album.Album, error) {
+	var albums []album.Album
+	if err := r.withRelations().Where("barcode = ?", code).Find(&albums); err != nil {
+		return nil, err
+	}
+	for i := range albums {
+		albums[i].RefreshTracklist()
+	}
+	return albums, nil
+}
+
+func (r *GormAlbumRepository) GetTracks(albumID int) ([]album.Track, error) {
+	albumEntity, err := r.GetByID(albumID)
+	if err != nil {
+		return nil, err
+	}
+	return albumEntity.Tracks, nil
+}
+
+// ReplaceTracks swaps the whole tracklist in one transaction, inserting the new tracks in a single batch.
+func (r *GormAlbumRepository) ReplaceTracks(albumID int, tracks []album.Track) ([]album.Track, error) {
+	err := r.db.Transaction(func(tx persistence.DB) error {
+		// Lock the album row so concurrent replacements are serialized
+		var albumEntity album.Album
+		if err := tx.LockForUpdate().First(&albumEntity, albumID); err != nil {
+			return err
+		}
+		if err := tx.Where("album_id = ?", albumID).Delete(&album.Track{}); err != nil {
+			return err
+		}
+		if len(tracks) == 0 {
+			return nil
+		}
+		for i := range tracks {
+			tracks[i].ID = 0
+			tracks[i].AlbumID = uint(albumID)
+		}
+		return tx.Create(&tracks)
+	})
+	if err != nil {
+		return nil, err
+	}
+	albumEntity := album.Album{Tracks: tracks}
+	albumEntity.RefreshTracklist()
+	return albumEntity.Tracks, nil
+}
+
+// GetPriceChanges returns the changes within [from, to] preceded by the last change before from,
+// which holds the price in effect at from, ordered by time.
+func (r *GormAlbumRepository) GetPriceChanges(albumID int, from, to time.Time) ([]album.PriceChange, error) {
+	var before []album.PriceChange
+	err := r.db.Where("album_id = ? AND changed_at < ?", albumID, from).
+		Order("changed_at DESC, id DESC").Limit(1).Find(&before)
+	if err != nil {
+		return nil, err
+	}
+	var changes []album.PriceChange
+	err = r.db.Where("album_id = ? AND changed_at >= ? AND changed_at <= ?", albumID, from, to).
+		Order("changed_at, id").Find(&changes)
+	if err != nil {
+		return nil, err
+	}
+	return append(before, changes...), nil
+}
+
 func (r *GormAlbumRepository) Create(albumEntity album.Album) (album.Album, error) {
-	if err := r.db.Create(&albumEntity); err != nil {
+	err := r.db.Transaction(func(tx persistence.DB) error {
+		return createAlbum(tx, &albumEntity)
+	})
+	if err != nil {
 		return album.Album{}, err
 	}
 	return albumEntity, nil
 }
 
+// Update saves the album and records a price history entry when its price changed.
 func (r *GormAlbumRepository) Update(albumEntity album.Album) (album.Album, error) {
-	if err := r.db.Save(&albumEntity); err != nil {
+	if albumEntity.ID == 0 {
+		return r.Create(albumEntity)
+	}
+	err := r.db.Transaction(func(tx persistence.DB) error {
+		return updateAlbum(tx, &albumEntity)
+	})
+	if err != nil {
 		return album.Album{}, err
 	}
 	return albumEntity, nil
 }
 
+// SaveAll creates the albums without an ID and updates the others in one transaction, nothing
+// is saved when one fails. An Artist without ID is looked up by its normalized name and
+// created when missing.
+func (r *GormAlbumRepository) SaveAll(albums []album.Album) ([]album.Album, error) {
+	saved := make([]album.Album, len(albums))
+	copy(saved, albums)
+	err := r.db.Transaction(func(tx persistence.DB) error {
+		for i := range saved {
+			if err := resolveArtist(tx, &saved[i]); err != nil {
+				return err
+			}
+			save := updateAlbum
+			if saved[i].ID == 0 {
+				save = createAlbum
+			}
+			if err := save(tx, &saved[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+func createAlbum(tx persistence.DB, albumEntity *album.Album) error {
+	if err := saveError(tx.OmitAssociations().Create(albumEntity)); err != nil {
+		return err
+	}
+	return recordPriceChange(tx, *albumEntity, nil, album.PriceReasonInitial)
+}
+
+func updateAlbum(tx persistence.DB, albumEntity *album.Album) error {
+	var current album.Album
+	if err := tx.LockForUpdate().First(&current, albumEntity.ID); err != nil {
+		return err
+	}
+	albumEntity.KeepManagedFields(current)
+	if err := saveError(tx.OmitAssociations().Save(albumEntity)); err != nil {
+		return err
+	}
+	if current.Price == albumEntity.Price {
+		return nil
+	}
+	return recordPriceChange(tx, *albumEntity, &current.Price, album.PriceReasonUpdate)
+}
+
+// saveError reports a write rejected by the unique label and barcode index as a duplicate
+// barcode, checked before saving but possibly taken by a concurrent write in the meantime.
+func saveError(err error) error {
+	if errors.Is(err, album.ErrDuplicate) {
+		return album.ErrDuplicateBarcode
+	}
+	return err
+}
+
+// resolveArtist sets ArtistID from Artist, finding or creating the artist by normalized name.
+func resolveArtist(tx persistence.DB, albumEntity *album.Album) error {
+	if albumEntity.Artist == nil {
+		return nil
+	}
+	artist := *albumEntity.Artist
+	if artist.ID == 0 {
+		artist.NormalizedName = album.NormalizeArtistName(artist.Name)
+		if err := tx.FirstOrCreate(&artist, album.Artist{NormalizedName: artist.NormalizedName}); err != nil {
+			return err
+		}
+	}
+	albumEntity.Artist, albumEntity.ArtistID = &artist, artist.ID
+	return nil
+}
+
+func (r *GormAlbumRepository) Modify(id int, change func(album *album.Album) error) (album.Album, error) {
+	err := r.db.Transaction(func(tx persistence.DB) error {
+		var albumEntity album.Album
+		if err := tx.LockForUpdate().First(&albumEntity, id); err != nil {
+			return err
+		}
+		if err := change(&albumEntity); err != nil {
+			return err
+		}
+		return tx.OmitAssociations().Save(&albumEntity)
+	})
+	if err != nil {
+		return album.Album{}, err
+	}
+	return r.GetByID(id)
+}
+
+func (r *GormAlbumRepository) GetStaleThumbnails(now time.Time, limit int) ([]album.Album, error) {
+	var albums []album.Album
+	err := r.db.Where("cover_key <> '' AND cover_checksum <> cover_thumbnail_checksum").
+		Where("cover_thumbnail_attempts < ?", album.MaxThumbnailAttempts).
+		Where("cover_thumbnail_retry_at IS NULL OR cover_thumbnail_retry_at <= ?", now).
+		Order("id").Limit(limit).Find(&albums)
+	if err != nil {
+		return nil, err
+	}
+	return albums, nil
+}
+
+func recordPriceChange(tx persistence.DB, albumEntity album.Album, previous *float64, defaultReason string) error {
+	reason := albumEntity.PriceChangeReason
+	if reason == "" {
+		reason = defaultReason
+	}
+	return tx.Create(&album.PriceChange{
+		AlbumID:       albumEntity.ID,
+		Price:         albumEntity.Price,
+		PreviousPrice: previous,
+		Reason:        reason,
+		ChangedAt:     time.Now().UTC(),
+	})
+}
+
 func (r *GormAlbumRepository) Delete(id int) error {
 	if err := r.db.Delete(&album.Album{}, id); err != nil {
 		return err
 	}
 	return nil
 }
+
+func (r *GormAlbumRepository) AddGenre(albumID int, genre string) error {
+	return r.db.Transaction(func(tx persistence.DB) error {
+		var albumEntity album.Album
+		if err := tx.First(&albumEntity, albumID); err != nil {
+			return err
+		}
+		genreEntity := album.Genre{Name: genre}
+		if err := tx.FirstOrCreate(&genreEntity, album.Genre{Name: genre}); err != nil {
+			return err
+		}
+		return tx.AppendAssociation(&albumEntity, "Genres", &genreEntity)
+	})
+}
+
+func (r *GormAlbumRepository) RemoveGenre(albumID int, genre string) error {
+	var albumEntity album.Album
+	if err := r.db.First(&albumEntity, albumID); err != nil {
+		return err
+	}
+	var genres []album.Genre
+	if err := r.db.Where("name = ?", genre).Find(&genres); err != nil {
+		return err
+	}
+	if len(genres) == 0 {
+		return nil
+	}
+	return r.db.DeleteAssociation(&albumEntity, "Genres", &genres[0])
+}
+
+func (r *GormAlbumRepository) AddTag(albumID int, tag string) error {
+	return r.db.Transaction(func(tx persistence.DB) error {
+		var albumEntity album.Album
+		if err := tx.First(&albumEntity, albumID); err != nil {
+			return err
+		}
+		tagEntity := album.Tag{Name: tag}
+		if err := tx.FirstOrCreate(&tagEntity, album.Tag{Name: tag}); err != nil {
+			return err
+		}
+		return tx.AppendAssociation(&albumEntity, "Tags", &tagEntity)
+	})
+}
+
+func (r *GormAlbumRepository) RemoveTag(albumID int, tag string) error {
+	var albumEntity album.Album
+	if err := r.db.First(&albumEntity, albumID); err != nil {
+		return err
+	}
+	var tags []album.Tag
+	if err := r.db.Where("name = ?", tag).Find(&tags); err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	return r.db.DeleteAssociation(&albumEntity, "Tags", &tags[0])
+}
+
+// applyAlbumFilter narrows an album query down to the filter criteria.
+func applyAlbumFilter(db persistence.DB, filter album.AlbumFilter) persistence.DB {
+	if len(filter.Genres) > 0 {
+		db = whereLinkedTo(db, "album_genres", "genres", "genre_id", filter.Genres, filter.GenreMatch)
+	}
+	if len(filter.Tags) > 0 {
+		db = whereLinkedTo(db, "album_tags", "tags", "tag_id", filter.Tags, filter.TagMatch)
+	}
+	if !filter.ReleasedAfter.IsZero() {
+		db = db.Where("albums.release_date > ?", filter.ReleasedAfter)
+	}
+	if filter.Status != "" {
+		db = db.Where("albums.status = ?", filter.Status)
+	}
+	if filter.InStock != nil {
+		inStock := "albums.id IN (SELECT album_id FROM stock_levels GROUP BY album_id HAVING SUM(on_hand - reserved) > 0)"
+		if *filter.InStock {
+			db = db.Where(inStock)
+		} else {
+			db = db.Where("NOT " + inStock)
+		}
+	}
+	return db
+}
+
+// whereLinkedTo keeps albums linked through joinTable to any, or all, of the named rows.
+func whereLinkedTo(db persistence.DB, joinTable, table, foreignKey string, names []string, mode album.MatchMode) persistence.DB {
+	subquery := "SELECT " + joinTable + ".album_id FROM " + joinTable +
+		" JOIN " + table + " ON " + table + ".id = " + joinTable + "." + foreignKey +
+		" WHERE " + table + ".name IN ?"
+	if mode != album.MatchAll {
+		return db.Where("albums.id IN ("+subquery+")", names)
+	}
+
+	distinct := map[string]bool{}
+	for _, name := range names {
+		distinct[name] = true
+	}
+	subquery += " GROUP BY " + joinTable + ".album_id HAVING COUNT(DISTINCT " + table + ".id) = ?"
+	return db.Where("albums.id IN ("+subquery+")", names, len(distinct))
+}
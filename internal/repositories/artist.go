@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/infrastructure/persistence"
+)
+
+type ArtistRepository interface {
+	GetAll() ([]domain.Artist, error)
+	GetByID(id int) (domain.Artist, error)
+	FindByNormalizedName(name string) ([]domain.Artist, error)
+	Create(artist domain.Artist) (domain.Artist, error)
+	Update(artist domain.Artist) (domain.Artist, error)
+	Delete(id int) error
+}
+
+type GormArtistRepository struct {
+	db persistence.DB
+}
+
+func NewGormArtistRepository(db persistence.DB) *GormArtistRepository {
+	return &GormArtistRepository{db: db}
+}
+
+func (r *GormArtistRepository) GetAll() ([]domain.Artist, error) {
+	var artists []domain.Artist
+	if err := r.db.Find(&artists); err != nil {
+		return nil, err
+	}
+	return artists, nil
+}
+
+func (r *GormArtistRepository) GetByID(id int) (domain.Artist, error) {
+	var artist domain.Artist
+	if err := r.db.First(&artist, id); err != nil {
+		return artist, err
+	}
+	return artist, nil
+}
+
+func (r *GormArtistRepository) FindByNormalizedName(name string) ([]domain.Artist, error) {
+	var artists []domain.Artist
+	if err := r.db.Where("normalized_name = ?", name).Find(&artists); err != nil {
+		return nil, err
+	}
+	return artists, nil
+}
+
+func (r *GormArtistRepository) Create(artist domain.Artist) (domain.Artist, error) {
+	if err := r.db.Create(&artist); err != nil {
+		return domain.Artist{}, err
+	}
+	return artist, nil
+}
+
+func (r *GormArtistRepository) Update(artist domain.Artist) (domain.Artist, error) {
+	if err := r.db.Save(&artist); err != nil {
+		return domain.Artist{}, err
+	}
+	return artist, nil
+}
+
+func (r *GormArtistRepository) Delete(id int) error {
+	return r.db.Delete(&domain.Artist{}, id)
+}
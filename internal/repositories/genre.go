@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/infrastructure/persistence"
+)
+
+type GenreRepository interface {
+	GetAll() ([]domain.Genre, error)
+	GetByID(id int) (domain.Genre, error)
+	Create(genre domain.Genre) (domain.Genre, error)
+	Update(genre domain.Genre) (domain.Genre, error)
+	Delete(id int) error
+}
+
+type GormGenreRepository struct {
+	db persistence.DB
+}
+
+func NewGormGenreRepository(db persistence.DB) *GormGenreRepository {
+	return &GormGenreRepository{db: db}
+}
+
+func (r *GormGenreRepository) GetAll() ([]domain.Genre, error) {
+	var genres []domain.Genre
+	if err := r.db.Find(&genres); err != nil {
+		return nil, err
+	}
+	return genres, nil
+}
+
+func (r *GormGenreRepository) GetByID(id int) (domain.Genre, error) {
+	var genre domain.Genre
+	if err := r.db.First(&genre, id); err != nil {
+		return genre, err
+	}
+	return genre, nil
+}
+
+func (r *GormGenreRepository) Create(genre domain.Genre) (domain.Genre, error) {
+	if err := r.db.Create(&genre); err != nil {
+		return domain.Genre{}, err
+	}
+	return genre, nil
+}
+
+func (r *GormGenreRepository) Update(genre domain.Genre) (domain.Genre, error) {
+	if err := r.db.Save(&genre); err != nil {
+		return domain.Genre{}, err
+	}
+	return genre, nil
+}
+
+func (r *GormGenreRepository) Delete(id int) error {
+	return r.db.Delete(&domain.Genre{}, id)
+}
@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/infrastructure/persistence"
+)
+
+// Stock levels and reservations. Every change locks the affected stock rows,
+// so concurrent reservations can never oversell.
+type InventoryRepository interface {
+	GetStock(albumID int) ([]domain.StockLevel, error)
+	// SetStock returns the level as it was before, the zero level when it is new, and after
+	SetStock(level domain.StockLevel) (domain.StockLevel, domain.StockLevel, error)
+	Reserve(albumID int, warehouse string, quantity int) (domain.Reservation, domain.StockLevel, error)
+	Release(albumID int, reservationID int) (domain.Reservation, error)
+	Commit(albumID int, reservationID int) (domain.Reservation, domain.StockLevel, error)
+}
+
+type GormInventoryRepository struct {
+	db persistence.DB
+}
+
+func NewGormInventoryRepository(db persistence.DB) *GormInventoryRepository {
+	return &GormInventoryRepository{db: db}
+}
+
+func (r *GormInventoryRepository) GetStock(albumID int) ([]domain.StockLevel, error) {
+	var levels []domain.StockLevel
+	if err := r.db.Where("album_id = ?", albumID).Find(&levels); err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+func (r *GormInventoryRepository) SetStock(level domain.StockLevel) (domain.StockLevel, domain.StockLevel, error) {
+	var previous domain.StockLevel
+	err := r.db.Transaction(func(tx persistence.DB) error {
+		var albumEntity domain.Album
+		if err := tx.First(&albumEntity, level.AlbumID); err != nil {
+			return err
+		}
+		var existing []domain.StockLevel
+		if err := tx.LockForUpdate().Where("album_id = ? AND warehouse = ?", level.AlbumID, level.Warehouse).Find(&existing); err != nil {
+			return err
+		}
+		if len(existing) == 0 {
+			if err := level.SetOnHand(level.OnHand); err != nil {
+				return err
+			}
+			return tx.Create(&level)
+		}
+		current := existing[0]
+		previous = current
+		if err := current.SetOnHand(level.OnHand); err != nil {
+			return err
+		}
+		current.LowStockThreshold = level.LowStockThreshold
+		level = current
+		return tx.Save(&level)
+	})
+	return previous, level, err
+}
+
+func (r *GormInventoryRepository) Reserve(albumID int, warehouse string, quantity int) (domain.Reservation, domain.StockLevel, error) {
+	var reservation domain.Reservation
+	var level domain.StockLevel
+	err := r.db.Transaction(func(tx persistence.DB) error {
+		var err error
+		reservation, level, err = reserveStock(tx, albumID, warehouse, quantity)
+		return err
+	})
+	return reservation, level, err
+}
+
+func (r *GormInventoryRepository) Release(albumID int, reservationID int) (domain.Reservation, error) {
+	var reservation domain.Reservation
+	err := r.db.Transaction(func(tx persistence.DB) error {
+		var err error
+		reservation, _, err = settleReservation(tx, albumID, reservationID, (*domain.Reservation).Release)
+		return err
+	})
+	return reservation, err
+}
+
+func (r *GormInventoryRepository) Commit(albumID int, reservationID int) (domain.Reservation, domain.StockLevel, error) {
+	var reservation domain.Reservation
+	var level domain.StockLevel
+	err := r.db.Transaction(func(tx persistence.DB) error {
+		var err error
+		reservation, level, err = settleReservation(tx, albumID, reservationID, (*domain.Reservation).Commit)
+		return err
+	})
+	return reservation, level, err
+}
+
+// reserveStock holds quantity within tx. Without a warehouse the one with the most available stock is used.
+func reserveStock(tx persistence.DB, albumID int, warehouse string, quantity int) (domain.Reservation, domain.StockLevel, error) {
+	query := tx.LockForUpdate().Where("album_id = ?", albumID)
+	if warehouse != "" {
+		query = query.Where("warehouse = ?", warehouse)
+	}
+	var levels []domain.StockLevel
+	if err := query.Find(&levels); err != nil {
+		return domain.Reservation{}, domain.StockLevel{}, err
+	}
+	if len(levels) == 0 {
+		return domain.Reservation{}, domain.StockLevel{}, domain.ErrInsufficientStock
+	}
+
+	level := levels[0]
+	for _, candidate := range levels[1:] {
+		if candidate.Available() > level.Available() {
+			level = candidate
+		}
+	}
+	reservation, err := level.Reserve(quantity)
+	if err != nil {
+		return domain.Reservation{}, domain.StockLevel{}, err
+	}
+	if err := tx.Save(&level); err != nil {
+		return domain.Reservation{}, domain.StockLevel{}, err
+	}
+	if err := tx.Create(&reservation); err != nil {
+		return domain.Reservation{}, domain.StockLevel{}, err
+	}
+	return reservation, level, nil
+}
+
+// settleReservation applies a release or commit to an active reservation within tx.
+func settleReservation(tx persistence.DB, albumID int, reservationID int, settle func(*domain.Reservation, *domain.StockLevel) error) (domain.Reservation, domain.StockLevel, error) {
+	var reservation domain.Reservation
+	if err := tx.LockForUpdate().Where("album_id = ?", albumID).First(&reservation, reservationID); err != nil {
+		return domain.Reservation{}, domain.StockLevel{}, err
+	}
+	var level domain.StockLevel
+	if err := tx.LockForUpdate().Where("album_id = ? AND warehouse = ?", reservation.AlbumID, reservation.Warehouse).First(&level); err != nil {
+		return domain.Reservation{}, domain.StockLevel{}, err
+	}
+	if err := settle(&reservation, &level); err != nil {
+		return domain.Reservation{}, domain.StockLevel{}, err
+	}
+	if err := tx.Save(&level); err != nil {
+		return domain.Reservation{}, domain.StockLevel{}, err
+	}
+	if err := tx.Save(&reservation); err != nil {
+		return domain.Reservation{}, domain.StockLevel{}, err
+	}
+	return reservation, level, nil
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/infrastructure/persistence"
+)
+
+type JobRepository interface {
+	GetByID(id int) (domain.Job, error)
+	// GetClaimable lists queued jobs and running jobs whose lease expired, oldest first
+	GetClaimable(now time.Time, limit int) ([]domain.Job, error)
+	Create(job domain.Job) (domain.Job, error)
+	// Update applies change to the locked job and saves it, nothing is saved if change fails
+	Update(id int, change func(job *domain.Job) error) (domain.Job, error)
+}
+
+type GormJobRepository struct {
+	db persistence.DB
+}
+
+func NewGormJobRepository(db persistence.DB) *GormJobRepository {
+	return &GormJobRepository{db: db}
+}
+
+func (r *GormJobRepository) GetByID(id int) (domain.Job, error) {
+	var job domain.Job
+	if err := r.db.First(&job, id); err != nil {
+		return job, err
+	}
+	return job, nil
+}
+
+func (r *GormJobRepository) GetClaimable(now time.Time, limit int) ([]domain.Job, error) {
+	var jobs []domain.Job
+	err := r.db.Where("status = ? OR (status = ? AND lease_expires_at < ?)", domain.JobQueued, domain.JobRunning, now).
+		Order("id").Limit(limit).Find(&jobs)
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *GormJobRepository) Create(job domain.Job) (domain.Job, error) {
+	if err := r.db.Create(&job); err != nil {
+		return domain.Job{}, err
+	}
+	return job, nil
+}
+
+func (r *GormJobRepository) Update(id int, change func(job *domain.Job) error) (domain.Job, error) {
+	var job domain.Job
+	err := r.db.Transaction(func(tx persistence.DB) error {
+		if err := tx.LockForUpdate().First(&job, id); err != nil {
+			return err
+		}
+		if err := change(&job); err != nil {
+			return err
+		}
+		return tx.Save(&job)
+	})
+	if err != nil {
+		return domain.Job{}, err
+	}
+	return job, nil
+}
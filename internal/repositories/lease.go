@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/infrastructure/persistence"
+)
+
+// Leases elect a single holder across replicas sharing the database.
+type LeaseRepository interface {
+	// Acquire takes or renews the lease for ttl, reports false while another holder's lease is unexpired
+	Acquire(name, holder string, now time.Time, ttl time.Duration) (bool, error)
+	Release(name, holder string) error
+}
+
+type GormLeaseRepository struct {
+	db persistence.DB
+}
+
+func NewGormLeaseRepository(db persistence.DB) *GormLeaseRepository {
+	return &GormLeaseRepository{db: db}
+}
+
+func (r *GormLeaseRepository) Acquire(name, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	// A concurrent first insert fails on the primary key, the caller simply tries again later
+	if err := r.db.FirstOrCreate(&domain.Lease{}, domain.Lease{Name: name}); err != nil {
+		return false, err
+	}
+	acquired := false
+	err := r.db.Transaction(func(tx persistence.DB) error {
+		var lease domain.Lease
+		if err := tx.LockForUpdate().Where("name = ?", name).First(&lease); err != nil {
+			return err
+		}
+		if lease.Holder != holder && lease.ExpiresAt.After(now) {
+			return nil
+		}
+		lease.Holder, lease.ExpiresAt = holder, now.Add(ttl)
+		acquired = true
+		return tx.Save(&lease)
+	})
+	return acquired, err
+}
+
+func (r *GormLeaseRepository) Release(name, holder string) error {
+	return r.db.Transaction(func(tx persistence.DB) error {
+		var lease domain.Lease
+		if err := tx.LockForUpdate().Where("name = ?", name).First(&lease); err != nil {
+			return err
+		}
+		if lease.Holder != holder {
+			return nil
+		}
+		lease.ExpiresAt = time.Time{}
+		return tx.Save(&lease)
+	})
+}
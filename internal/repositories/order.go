@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/infrastructure/persistence"
+)
+
+type OrderRepository interface {
+	GetAll() ([]domain.Order, error)
+	GetByID(id int) (domain.Order, error)
+	Place(order domain.Order) (domain.Order, error)
+	Update(id int, change func(order *domain.Order) error) (domain.Order, error)
+}
+
+type GormOrderRepository struct {
+	db persistence.DB
+}
+
+func NewGormOrderRepository(db persistence.DB) *GormOrderRepository {
+	return &GormOrderRepository{db: db}
+}
+
+func (r *GormOrderRepository) GetAll() ([]domain.Order, error) {
+	var orders []domain.Order
+	if err := r.db.Preload("Items").Find(&orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (r *GormOrderRepository) GetByID(id int) (domain.Order, error) {
+	var order domain.Order
+	if err := r.db.Preload("Items").First(&order, id); err != nil {
+		return order, err
+	}
+	return order, nil
+}
+
+// Place stores the order and reserves stock for its physical items in the same transaction,
+// so either the whole order is placed with its stock held or nothing is.
+func (r *GormOrderRepository) Place(order domain.Order) (domain.Order, error) {
+	err := r.db.Transaction(func(tx persistence.DB) error {
+		for i, item := range order.Items {
+			if !item.RequiresStock {
+				continue
+			}
+			reservation, _, err := reserveStock(tx, int(item.AlbumID), "", item.Quantity)
+			if err != nil {
+				return fmt.Errorf("album %d: %w", item.AlbumID, err)
+			}
+			order.Items[i].ReservationID = &reservation.ID
+		}
+		return tx.Create(&order)
+	})
+	if err != nil {
+		return domain.Order{}, err
+	}
+	return order, nil
+}
+
+// Update applies a status change to a locked order. Shipping commits the held stock
+// and cancelling releases it, within the same transaction.
+func (r *GormOrderRepository) Update(id int, change func(order *domain.Order) error) (domain.Order, error) {
+	var order domain.Order
+	err := r.db.Transaction(func(tx persistence.DB) error {
+		if err := tx.LockForUpdate().Preload("Items").First(&order, id); err != nil {
+			return err
+		}
+		previous := order.Status
+		if err := change(&order); err != nil {
+			return err
+		}
+		if err := tx.OmitAssociations().Save(&order); err != nil {
+			return err
+		}
+		if order.Status == previous {
+			return nil
+		}
+
+		var settle func(*domain.Reservation, *domain.StockLevel) error
+		switch order.Status {
+		case domain.OrderShipped:
+			settle = (*domain.Reservation).Commit
+		case domain.OrderCancelled:
+			settle = (*domain.Reservation).Release
+		default:
+			return nil
+		}
+		for _, item := range order.Items {
+			if item.ReservationID == nil {
+				continue
+			}
+			if _, _, err := settleReservation(tx, int(item.AlbumID), int(*item.ReservationID), settle); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Order{}, err
+	}
+	return order, nil
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/infrastructure/persistence"
+)
+
+type PaymentRepository interface {
+	GetByID(id int) (domain.Payment, error)
+	GetByOrderID(orderID int) ([]domain.Payment, error)
+	GetByGatewayRef(gateway, ref string) (domain.Payment, error)
+	Create(payment domain.Payment) (domain.Payment, error)
+	// Update applies change to the locked payment and saves it, so status checks hold
+	Update(id int, change func(payment *domain.Payment) error) (domain.Payment, error)
+}
+
+type GormPaymentRepository struct {
+	db persistence.DB
+}
+
+func NewGormPaymentRepository(db persistence.DB) *GormPaymentRepository {
+	return &GormPaymentRepository{db: db}
+}
+
+func (r *GormPaymentRepository) GetByID(id int) (domain.Payment, error) {
+	var payment domain.Payment
+	if err := r.db.First(&payment, id); err != nil {
+		return payment, err
+	}
+	return payment, nil
+}
+
+func (r *GormPaymentRepository) GetByOrderID(orderID int) ([]domain.Payment, error) {
+	var payments []domain.Payment
+	if err := r.db.Where("order_id = ?", orderID).Find(&payments); err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (r *GormPaymentRepository) GetByGatewayRef(gateway, ref string) (domain.Payment, error) {
+	var payment domain.Payment
+	if err := r.db.Where("gateway = ? AND gateway_ref = ?", gateway, ref).First(&payment); err != nil {
+		return payment, err
+	}
+	return payment, nil
+}
+
+func (r *GormPaymentRepository) Create(payment domain.Payment) (domain.Payment, error) {
+	if err := r.db.Create(&payment); err != nil {
+		return domain.Payment{}, err
+	}
+	return payment, nil
+}
+
+func (r *GormPaymentRepository) Update(id int, change func(payment *domain.Payment) error) (domain.Payment, error) {
+	var payment domain.Payment
+	err := r.db.Transaction(func(tx persistence.DB) error {
+		if err := tx.LockForUpdate().First(&payment, id); err != nil {
+			return err
+		}
+		if err := change(&payment); err != nil {
+			return err
+		}
+		return tx.Save(&payment)
+	})
+	if err != nil {
+		return domain.Payment{}, err
+	}
+	return payment, nil
+}
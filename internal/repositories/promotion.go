@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/infrastructure/persistence"
+)
+
+type PromotionRepository interface {
+	GetAll() ([]domain.Promotion, error)
+	GetActive(at time.Time) ([]domain.Promotion, error)
+	GetByID(id int) (domain.Promotion, error)
+	Create(promotion domain.Promotion) (domain.Promotion, error)
+	Update(promotion domain.Promotion) (domain.Promotion, error)
+	Delete(id int) error
+}
+
+type GormPromotionRepository struct {
+	db persistence.DB
+}
+
+func NewGormPromotionRepository(db persistence.DB) *GormPromotionRepository {
+	return &GormPromotionRepository{db: db}
+}
+
+func (r *GormPromotionRepository) GetAll() ([]domain.Promotion, error) {
+	var promotions []domain.Promotion
+	if err := r.db.Find(&promotions); err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+func (r *GormPromotionRepository) GetActive(at time.Time) ([]domain.Promotion, error) {
+	var promotions []domain.Promotion
+	if err := r.db.Where("starts_at <= ? AND ends_at > ?", at, at).Find(&promotions); err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+func (r *GormPromotionRepository) GetByID(id int) (domain.Promotion, error) {
+	var promotion domain.Promotion
+	if err := r.db.First(&promotion, id); err != nil {
+		return promotion, err
+	}
+	return promotion, nil
+}
+
+func (r *GormPromotionRepository) Create(promotion domain.Promotion) (domain.Promotion, error) {
+	if err := r.db.Create(&promotion); err != nil {
+		return domain.Promotion{}, err
+	}
+	return promotion, nil
+}
+
+func (r *GormPromotionRepository) Update(promotion domain.Promotion) (domain.Promotion, error) {
+	if err := r.db.Save(&promotion); err != nil {
+		return domain.Promotion{}, err
+	}
+	return promotion, nil
+}
+
+func (r *GormPromotionRepository) Delete(id int) error {
+	return r.db.Delete(&domain.Promotion{}, id)
+}
@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/infrastructure/persistence"
+)
+
+// Review writes lock the album first, then the review, and keep the album rating aggregates
+// in step within the same transaction.
+type ReviewRepository interface {
+	GetByAlbum(albumID int, includeHidden bool) ([]domain.Review, error)
+	GetByID(albumID int, id int) (domain.Review, error)
+	Create(review domain.Review) (domain.Review, error)
+	Update(albumID int, id int, change func(review *domain.Review) error) (domain.Review, error)
+	Delete(albumID int, id int) error
+	// Flag counts the flag of the client, a second flag of the same client fails with ErrDuplicateFlag
+	Flag(albumID int, id int, client string) (domain.Review, error)
+}
+
+type GormReviewRepository struct {
+	db persistence.DB
+}
+
+func NewGormReviewRepository(db persistence.DB) *GormReviewRepository {
+	return &GormReviewRepository{db: db}
+}
+
+func (r *GormReviewRepository) GetByAlbum(albumID int, includeHidden bool) ([]domain.Review, error) {
+	query := r.db.Where("album_id = ?", albumID)
+	if !includeHidden {
+		query = query.Where("hidden = ?", false)
+	}
+	var reviews []domain.Review
+	if err := query.Order("created_at DESC, id DESC").Find(&reviews); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+func (r *GormReviewRepository) GetByID(albumID int, id int) (domain.Review, error) {
+	var review domain.Review
+	if err := r.db.Where("album_id = ?", albumID).First(&review, id); err != nil {
+		return review, err
+	}
+	return review, nil
+}
+
+func (r *GormReviewRepository) Create(review domain.Review) (domain.Review, error) {
+	err := r.db.Transaction(func(tx persistence.DB) error {
+		var album domain.Album
+		if err := tx.LockForUpdate().First(&album, review.AlbumID); err != nil {
+			return err
+		}
+		// The album lock serializes reviews of the album, so the check cannot race
+		var existing []domain.Review
+		if err := tx.Where("album_id = ? AND author = ?", review.AlbumID, review.Author).Limit(1).Find(&existing); err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			return domain.ErrDuplicateReview
+		}
+		if err := tx.Create(&review); err != nil {
+			return err
+		}
+		album.UpdateRating(nil, &review)
+		return tx.OmitAssociations().Save(&album)
+	})
+	if err != nil {
+		return domain.Review{}, err
+	}
+	return review, nil
+}
+
+func (r *GormReviewRepository) Update(albumID int, id int, change func(review *domain.Review) error) (domain.Review, error) {
+	return r.update(albumID, id, func(tx persistence.DB, review *domain.Review) error {
+		return change(review)
+	})
+}
+
+func (r *GormReviewRepository) Flag(albumID int, id int, client string) (domain.Review, error) {
+	return r.update(albumID, id, func(tx persistence.DB, review *domain.Review) error {
+		// The review lock serializes flags of the review, the unique index backs the check
+		var existing []domain.ReviewFlag
+		if err := tx.Where("review_id = ? AND client = ?", review.ID, client).Limit(1).Find(&existing); err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			return domain.ErrDuplicateFlag
+		}
+		if err := tx.Create(&domain.ReviewFlag{ReviewID: review.ID, Client: client}); errors.Is(err, domain.ErrDuplicate) {
+			return domain.ErrDuplicateFlag
+		} else if err != nil {
+			return err
+		}
+		review.FlagCount++
+		return nil
+	})
+}
+
+// update applies change to the locked review within the transaction that keeps the album
+// rating aggregates in step.
+func (r *GormReviewRepository) update(albumID int, id int, change func(tx persistence.DB, review *domain.Review) error) (domain.Review, error) {
+	var review domain.Review
+	err := r.db.Transaction(func(tx persistence.DB) error {
+		var album domain.Album
+		if err := tx.LockForUpdate().First(&album, albumID); err != nil {
+			return err
+		}
+		if err := tx.LockForUpdate().Where("album_id = ?", albumID).First(&review, id); err != nil {
+			return err
+		}
+		before := review
+		if err := change(tx, &review); err != nil {
+			return err
+		}
+		if err := tx.Save(&review); err != nil {
+			return err
+		}
+		album.UpdateRating(&before, &review)
+		return tx.OmitAssociations().Save(&album)
+	})
+	if err != nil {
+		return domain.Review{}, err
+	}
+	return review, nil
+}
+
+func (r *GormReviewRepository) Delete(albumID int, id int) error {
+	return r.db.Transaction(func(tx persistence.DB) error {
+		var album domain.Album
+		if err := tx.LockForUpdate().First(&album, albumID); err != nil {
+			return err
+		}
+		var review domain.Review
+		if err := tx.LockForUpdate().Where("album_id = ?", albumID).First(&review, id); err != nil {
+			return err
+		}
+		if err := tx.Where("review_id = ?", review.ID).Delete(&domain.ReviewFlag{}); err != nil {
+			return err
+		}
+		if err := tx.Delete(&review); err != nil {
+			return err
+		}
+		album.UpdateRating(&review, nil)
+		return tx.OmitAssociations().Save(&album)
+	})
+}
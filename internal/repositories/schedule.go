@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/infrastructure/persistence"
+)
+
+type ScheduleRepository interface {
+	GetAll(filter domain.ScheduleFilter) ([]domain.ScheduledChange, error)
+	GetByID(id int) (domain.ScheduledChange, error)
+	GetDue(now time.Time, limit int) ([]domain.ScheduledChange, error)
+	Create(change domain.ScheduledChange) (domain.ScheduledChange, error)
+	// Update applies change to the locked scheduled change and saves it, nothing is saved if change fails
+	Update(id int, change func(scheduled *domain.ScheduledChange) error) (domain.ScheduledChange, error)
+}
+
+type GormScheduleRepository struct {
+	db persistence.DB
+}
+
+func NewGormScheduleRepository(db persistence.DB) *GormScheduleRepository {
+	return &GormScheduleRepository{db: db}
+}
+
+func (r *GormScheduleRepository) GetAll(filter domain.ScheduleFilter) ([]domain.ScheduledChange, error) {
+	query := r.db
+	if filter.AlbumID != 0 {
+		query = query.Where("album_id = ?", filter.AlbumID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	var changes []domain.ScheduledChange
+	if err := query.Order("run_at, id").Find(&changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (r *GormScheduleRepository) GetByID(id int) (domain.ScheduledChange, error) {
+	var change domain.ScheduledChange
+	if err := r.db.First(&change, id); err != nil {
+		return change, err
+	}
+	return change, nil
+}
+
+func (r *GormScheduleRepository) GetDue(now time.Time, limit int) ([]domain.ScheduledChange, error) {
+	var changes []domain.ScheduledChange
+	err := r.db.Where("status = ? AND run_at <= ?", domain.SchedulePending, now).
+		Order("run_at, id").Limit(limit).Find(&changes)
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (r *GormScheduleRepository) Create(change domain.ScheduledChange) (domain.ScheduledChange, error) {
+	if err := r.db.Create(&change); err != nil {
+		return domain.ScheduledChange{}, err
+	}
+	return change, nil
+}
+
+func (r *GormScheduleRepository) Update(id int, change func(scheduled *domain.ScheduledChange) error) (domain.ScheduledChange, error) {
+	var scheduled domain.ScheduledChange
+	err := r.db.Transaction(func(tx persistence.DB) error {
+		if err := tx.LockForUpdate().First(&scheduled, id); err != nil {
+			return err
+		}
+		if err := change(&scheduled); err != nil {
+			return err
+		}
+		return tx.Save(&scheduled)
+	})
+	if err != nil {
+		return domain.ScheduledChange{}, err
+	}
+	return scheduled, nil
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/infrastructure/persistence"
+)
+
+type TagRepository interface {
+	GetAll() ([]domain.Tag, error)
+	GetByID(id int) (domain.Tag, error)
+	Create(tag domain.Tag) (domain.Tag, error)
+	Update(tag domain.Tag) (domain.Tag, error)
+	Delete(id int) error
+}
+
+type GormTagRepository struct {
+	db persistence.DB
+}
+
+func NewGormTagRepository(db persistence.DB) *GormTagRepository {
+	return &GormTagRepository{db: db}
+}
+
+func (r *GormTagRepository) GetAll() ([]domain.Tag, error) {
+	var tags []domain.Tag
+	if err := r.db.Find(&tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *GormTagRepository) GetByID(id int) (domain.Tag, error) {
+	var tag domain.Tag
+	if err := r.db.First(&tag, id); err != nil {
+		return tag, err
+	}
+	return tag, nil
+}
+
+func (r *GormTagRepository) Create(tag domain.Tag) (domain.Tag, error) {
+	if err := r.db.Create(&tag); err != nil {
+		return domain.Tag{}, err
+	}
+	return tag, nil
+}
+
+func (r *GormTagRepository) Update(tag domain.Tag) (domain.Tag, error) {
+	if err := r.db.Save(&tag); err != nil {
+		return domain.Tag{}, err
+	}
+	return tag, nil
+}
+
+func (r *GormTagRepository) Delete(id int) error {
+	return r.db.Delete(&domain.Tag{}, id)
+}
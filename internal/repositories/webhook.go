@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/infrastructure/persistence"
+)
+
+type WebhookRepository interface {
+	GetAll() ([]domain.Webhook, error)
+	GetByID(id int) (domain.Webhook, error)
+	Create(webhook domain.Webhook) (domain.Webhook, error)
+	Update(webhook domain.Webhook) (domain.Webhook, error)
+	// Delete removes the webhook with its deliveries
+	Delete(id int) error
+	// GetDeliveries lists the latest deliveries of a webhook, newest first
+	GetDeliveries(webhookID int, limit int) ([]domain.WebhookDelivery, error)
+	// GetDueDeliveries lists pending deliveries due at now, longest waiting first
+	GetDueDeliveries(now time.Time, limit int) ([]domain.WebhookDelivery, error)
+	CreateDeliveries(deliveries []domain.WebhookDelivery) ([]domain.WebhookDelivery, error)
+	// UpdateDelivery applies change to the locked delivery and saves it, nothing is saved if change fails
+	UpdateDelivery(id int, change func(delivery *domain.WebhookDelivery) error) (domain.WebhookDelivery, error)
+}
+
+type GormWebhookRepository struct {
+	db persistence.DB
+}
+
+func NewGormWebhookRepository(db persistence.DB) *GormWebhookRepository {
+	return &GormWebhookRepository{db: db}
+}
+
+func (r *GormWebhookRepository) GetAll() ([]domain.Webhook, error) {
+	var webhooks []domain.Webhook
+	if err := r.db.Order("id").Find(&webhooks); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (r *GormWebhookRepository) GetByID(id int) (domain.Webhook, error) {
+	var webhook domain.Webhook
+	if err := r.db.First(&webhook, id); err != nil {
+		return webhook, err
+	}
+	return webhook, nil
+}
+
+func (r *GormWebhookRepository) Create(webhook domain.Webhook) (domain.Webhook, error) {
+	if err := r.db.Create(&webhook); err != nil {
+		return domain.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+func (r *GormWebhookRepository) Update(webhook domain.Webhook) (domain.Webhook, error) {
+	if err := r.db.Save(&webhook); err != nil {
+		return domain.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+func (r *GormWebhookRepository) Delete(id int) error {
+	return r.db.Transaction(func(tx persistence.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&domain.WebhookDelivery{}); err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Webhook{}, id)
+	})
+}
+
+func (r *GormWebhookRepository) GetDeliveries(webhookID int, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	if err := r.db.Where("webhook_id = ?", webhookID).Order("id DESC").Limit(limit).Find(&deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *GormWebhookRepository) GetDueDeliveries(now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", domain.DeliveryPending, now).
+		Order("next_attempt_at").Limit(limit).Find(&deliveries)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *GormWebhookRepository) CreateDeliveries(deliveries []domain.WebhookDelivery) ([]domain.WebhookDelivery, error) {
+	if err := r.db.Create(&deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *GormWebhookRepository) UpdateDelivery(id int, change func(delivery *domain.WebhookDelivery) error) (domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	err := r.db.Transaction(func(tx persistence.DB) error {
+		if err := tx.LockForUpdate().First(&delivery, id); err != nil {
+			return err
+		}
+		if err := change(&delivery); err != nil {
+			return err
+		}
+		return tx.Save(&delivery)
+	})
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
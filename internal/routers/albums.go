@@ -11,9 +11,36 @@ func RegisterAlbumHandlers(router *gin.Engine, handler *handlers.AlbumHandler) *
 		// Album routes
 		albumRouter.GET("/albums", handler.GetAlbums)
 		albumRouter.GET("/albums/:id", handler.GetAlbumByID)
+		albumRouter.GET("/albums/by-barcode/:code", handler.GetAlbumsByBarcode)
+		albumRouter.GET("/albums/export", handler.ExportAlbums)
+		albumRouter.GET("/albums/events", handler.StreamAlbumEvents)
+		albumRouter.POST("/albums/import", handlers.RequireEditor, handler.ImportAlbums)
 		albumRouter.POST("/albums", handler.CreateAlbum)
 		albumRouter.PUT("/albums", handler.UpdateAlbum)
 		albumRouter.DELETE("/albums/:id", handler.DeleteAlbum)
+
+		// Tracklist routes
+		albumRouter.GET("/albums/:id/tracks", handler.GetAlbumTracks)
+		albumRouter.PUT("/albums/:id/tracks", handler.ReplaceAlbumTracks)
+
+		// Editorial workflow routes, anyone may submit a draft for review
+		albumRouter.POST("/albums/:id/submit", handler.SubmitAlbum)
+		albumRouter.POST("/albums/:id/approve", handlers.RequireEditor, handler.ApproveAlbum)
+		albumRouter.POST("/albums/:id/publish", handlers.RequireEditor, handler.PublishAlbum)
+		albumRouter.POST("/albums/:id/archive", handlers.RequireEditor, handler.ArchiveAlbum)
+
+		// Cover art routes
+		albumRouter.GET("/albums/:id/cover", handler.GetCover)
+		albumRouter.PUT("/albums/:id/cover", handlers.RequireEditor, handler.UploadCover)
+
+		// Price history routes
+		albumRouter.GET("/albums/:id/prices", handler.GetAlbumPriceHistory)
+
+		// Genre and tag assignment routes
+		albumRouter.POST("/albums/:id/genres/:genre", handler.AddAlbumGenre)
+		albumRouter.DELETE("/albums/:id/genres/:genre", handler.RemoveAlbumGenre)
+		albumRouter.POST("/albums/:id/tags/:tag", handler.AddAlbumTag)
+		albumRouter.DELETE("/albums/:id/tags/:tag", handler.RemoveAlbumTag)
 	}
 	return albumRouter
 }
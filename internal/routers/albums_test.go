@@ -0,0 +1,95 @@
+package routers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/handlers"
+	"github.com/ssitko/hex-domain/internal/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlbumAPISpec(t *testing.T) {
+	r := gin.New()
+	RegisterAlbumHandlers(r, handlers.NewAlbumHandler(nil))
+	spec := handlers.AlbumAPISpec()
+
+	t.Run("AlbumAPISpec :: documents every registered route", func(t *testing.T) {
+		registered := map[string]bool{}
+		for _, route := range r.Routes() {
+			registered[route.Method+" "+openapi.Path(route.Path)] = true
+			operation := spec.Operation(route.Method, route.Path)
+			if assert.NotNil(t, operation, "%s %s is missing from the OpenAPI document", route.Method, route.Path) {
+				assert.NotEmpty(t, operation.Summary)
+				assert.NotEmpty(t, operation.Responses)
+			}
+		}
+		for path, item := range spec.Paths {
+			for method := range *item {
+				assert.True(t, registered[strings.ToUpper(method)+" "+path], "%s %s is documented but not registered", method, path)
+			}
+		}
+	})
+
+	t.Run("AlbumAPISpec :: documents path parameters and resolvable references", func(t *testing.T) {
+		for path, item := range spec.Paths {
+			for method, operation := range *item {
+				for _, param := range operation.Parameters {
+					if param.In == openapi.InPath {
+						assert.Contains(t, path, fmt.Sprintf("{%s}", param.Name), "%s %s", method, path)
+					}
+				}
+				for status, response := range operation.Responses {
+					for _, media := range response.Content {
+						if media.Schema != nil && media.Schema.Ref != "" {
+							assert.NotNil(t, spec.Resolve(media.Schema), "%s %s %s", method, path, status)
+						}
+					}
+				}
+			}
+		}
+		album := spec.Components.Schemas["Album"]
+		assert.Contains(t, album.Properties, "release_date")
+		assert.NotContains(t, album.Properties, "Cover")
+		assert.Contains(t, spec.Components.Schemas["AlbumResponse"].Properties, "cover_srcset")
+	})
+
+	t.Run("AlbumAPISpec :: editor only operations reject anonymous requests", func(t *testing.T) {
+		for _, route := range r.Routes() {
+			operation := spec.Operation(route.Method, route.Path)
+			if operation == nil || len(operation.Security) == 0 {
+				continue
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(route.Method, strings.ReplaceAll(route.Path, ":id", "1"), nil))
+			assert.Equal(t, http.StatusUnauthorized, w.Code, "%s %s", route.Method, route.Path)
+			assert.Contains(t, operation.Responses, "401", "%s %s", route.Method, route.Path)
+		}
+		assert.NotEmpty(t, spec.Operation(http.MethodPost, "/v1/albums/import").Security)
+		assert.NotEmpty(t, spec.Operation(http.MethodPut, "/v1/albums/:id/cover").Security)
+	})
+
+	t.Run("GET :: /openapi.json and /docs endpoints", func(t *testing.T) {
+		docsHandler, err := handlers.NewDocsHandler(spec)
+		assert.Nil(t, err)
+		RegisterDocsHandlers(r, docsHandler)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"openapi": "3.1.0"`)
+
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "swagger-ui-bundle.js")
+
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/swagger-initializer.js", nil))
+		assert.Contains(t, w.Body.String(), `url: "/openapi.json"`)
+	})
+}
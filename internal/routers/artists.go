@@ -0,0 +1,20 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/handlers"
+)
+
+func RegisterArtistHandlers(router *gin.Engine, handler *handlers.ArtistHandler) *gin.RouterGroup {
+	artistRouter := router.Group("/v1")
+	{
+		// Artist routes
+		artistRouter.GET("/artists", handler.GetArtists)
+		artistRouter.GET("/artists/:id", handler.GetArtistByID)
+		artistRouter.GET("/artists/:id/albums", handler.GetArtistAlbums)
+		artistRouter.POST("/artists", handler.CreateArtist)
+		artistRouter.PUT("/artists/:id", handler.UpdateArtist)
+		artistRouter.DELETE("/artists/:id", handler.DeleteArtist)
+	}
+	return artistRouter
+}
@@ -0,0 +1,16 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/handlers"
+)
+
+func RegisterDocsHandlers(router *gin.Engine, handler *handlers.DocsHandler) *gin.RouterGroup {
+	docsRouter := router.Group("")
+	{
+		// API description routes, /docs redirects to /docs/
+		docsRouter.GET("/openapi.json", handler.GetSpec)
+		docsRouter.GET("/docs/*filepath", handler.GetUI)
+	}
+	return docsRouter
+}
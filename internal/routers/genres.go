@@ -0,0 +1,19 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/handlers"
+)
+
+func RegisterGenreHandlers(router *gin.Engine, handler *handlers.GenreHandler) *gin.RouterGroup {
+	genreRouter := router.Group("/v1")
+	{
+		// Genre routes
+		genreRouter.GET("/genres", handler.GetGenres)
+		genreRouter.GET("/genres/:id", handler.GetGenreByID)
+		genreRouter.POST("/genres", handler.CreateGenre)
+		genreRouter.PUT("/genres/:id", handler.UpdateGenre)
+		genreRouter.DELETE("/genres/:id", handler.DeleteGenre)
+	}
+	return genreRouter
+}
@@ -0,0 +1,21 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/handlers"
+)
+
+func RegisterInventoryHandlers(router *gin.Engine, handler *handlers.InventoryHandler) *gin.RouterGroup {
+	inventoryRouter := router.Group("/v1")
+	{
+		// Stock routes, anyone may read stock while only editors change it
+		inventoryRouter.GET("/albums/:id/stock", handler.GetStock)
+		inventoryRouter.PUT("/albums/:id/stock/:warehouse", handlers.RequireEditor, handler.SetStock)
+
+		// Reservation routes, editors only
+		inventoryRouter.POST("/albums/:id/stock/reservations", handlers.RequireEditor, handler.Reserve)
+		inventoryRouter.POST("/albums/:id/stock/reservations/:reservation/release", handlers.RequireEditor, handler.ReleaseReservation)
+		inventoryRouter.POST("/albums/:id/stock/reservations/:reservation/commit", handlers.RequireEditor, handler.CommitReservation)
+	}
+	return inventoryRouter
+}
@@ -0,0 +1,20 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/handlers"
+)
+
+func RegisterJobHandlers(router *gin.Engine, handler *handlers.JobHandler) *gin.RouterGroup {
+	// Jobs rewrite and export the catalog in bulk, drafts included, so they are limited to editors
+	jobRouter := router.Group("/v1", handlers.RequireEditor)
+	{
+		// Job routes
+		jobRouter.POST("/jobs/import", handler.StartImportJob)
+		jobRouter.POST("/jobs/export", handler.StartExportJob)
+		jobRouter.GET("/jobs/:id", handler.GetJob)
+		jobRouter.POST("/jobs/:id/cancel", handler.CancelJob)
+		jobRouter.GET("/jobs/:id/result", handler.GetJobResult)
+	}
+	return jobRouter
+}
@@ -0,0 +1,21 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/handlers"
+)
+
+func RegisterOrderHandlers(router *gin.Engine, handler *handlers.OrderHandler) *gin.RouterGroup {
+	orderRouter := router.Group("/v1")
+	{
+		// Order routes, orders carry the customer email so only editors read them
+		orderRouter.GET("/orders", handlers.RequireEditor, handler.GetOrders)
+		orderRouter.GET("/orders/:id", handlers.RequireEditor, handler.GetOrderByID)
+		orderRouter.POST("/orders", handler.PlaceOrder)
+
+		// Order lifecycle routes, payment is only ever recorded by the payment service
+		orderRouter.POST("/orders/:id/ship", handlers.RequireEditor, handler.ShipOrder)
+		orderRouter.POST("/orders/:id/cancel", handlers.RequireEditor, handler.CancelOrder)
+	}
+	return orderRouter
+}
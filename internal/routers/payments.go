@@ -0,0 +1,22 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/handlers"
+)
+
+func RegisterPaymentHandlers(router *gin.Engine, handler *handlers.PaymentHandler) *gin.RouterGroup {
+	paymentRouter := router.Group("/v1")
+	{
+		// Order payment routes, customers authorize their order while only editors see its payments
+		paymentRouter.GET("/orders/:id/payments", handlers.RequireEditor, handler.GetOrderPayments)
+		paymentRouter.POST("/orders/:id/payments", handler.AuthorizeOrder)
+
+		// Payment routes, the webhook is authenticated by its signature instead
+		paymentRouter.GET("/payments/:id", handlers.RequireEditor, handler.GetPaymentByID)
+		paymentRouter.POST("/payments/:id/capture", handlers.RequireEditor, handler.CapturePayment)
+		paymentRouter.POST("/payments/:id/refund", handlers.RequireEditor, handler.RefundPayment)
+		paymentRouter.POST("/payments/webhook", handler.Webhook)
+	}
+	return paymentRouter
+}
@@ -0,0 +1,19 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/handlers"
+)
+
+func RegisterPromotionHandlers(router *gin.Engine, handler *handlers.PromotionHandler) *gin.RouterGroup {
+	promotionRouter := router.Group("/v1")
+	{
+		// Promotion routes, only editors change promotions or see their coupon codes
+		promotionRouter.GET("/promotions", handler.GetPromotions)
+		promotionRouter.GET("/promotions/:id", handler.GetPromotionByID)
+		promotionRouter.POST("/promotions", handlers.RequireEditor, handler.CreatePromotion)
+		promotionRouter.PUT("/promotions/:id", handlers.RequireEditor, handler.UpdatePromotion)
+		promotionRouter.DELETE("/promotions/:id", handlers.RequireEditor, handler.DeletePromotion)
+	}
+	return promotionRouter
+}
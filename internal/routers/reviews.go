@@ -0,0 +1,24 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/handlers"
+)
+
+func RegisterReviewHandlers(router *gin.Engine, handler *handlers.ReviewHandler) *gin.RouterGroup {
+	reviewRouter := router.Group("/v1")
+	{
+		// Review routes
+		reviewRouter.GET("/albums/:id/reviews", handler.GetAlbumReviews)
+		reviewRouter.GET("/albums/:id/reviews/:review", handler.GetReview)
+		reviewRouter.POST("/albums/:id/reviews", handler.CreateReview)
+		reviewRouter.PUT("/albums/:id/reviews/:review", handler.UpdateReview)
+		reviewRouter.DELETE("/albums/:id/reviews/:review", handler.DeleteReview)
+
+		// Moderation routes, anyone may flag a review, editors decide whether it is hidden
+		reviewRouter.POST("/albums/:id/reviews/:review/flag", handler.FlagReview)
+		reviewRouter.POST("/albums/:id/reviews/:review/hide", handlers.RequireEditor, handler.HideReview)
+		reviewRouter.POST("/albums/:id/reviews/:review/unhide", handlers.RequireEditor, handler.UnhideReview)
+	}
+	return reviewRouter
+}
@@ -0,0 +1,20 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/handlers"
+)
+
+func RegisterScheduleHandlers(router *gin.Engine, handler *handlers.ScheduleHandler) *gin.RouterGroup {
+	// Scheduled changes publish albums, so they are limited to editors
+	scheduleRouter := router.Group("/v1", handlers.RequireEditor)
+	{
+		// Scheduled change routes
+		scheduleRouter.GET("/schedules", handler.GetSchedules)
+		scheduleRouter.GET("/schedules/:id", handler.GetScheduleByID)
+		scheduleRouter.POST("/schedules/:id/cancel", handler.CancelSchedule)
+		scheduleRouter.GET("/albums/:id/schedules", handler.GetAlbumSchedules)
+		scheduleRouter.POST("/albums/:id/schedules", handler.ScheduleAlbumChange)
+	}
+	return scheduleRouter
+}
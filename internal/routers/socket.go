@@ -0,0 +1,16 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/handlers"
+)
+
+func RegisterSocketHandlers(router *gin.Engine, handler *handlers.SocketHandler) *gin.RouterGroup {
+	// The socket follows drafts as well, editors authenticate on the upgrade request
+	socketRouter := router.Group("/v1", handlers.RequireEditor)
+	{
+		// WebSocket routes
+		socketRouter.GET("/ws", handler.ServeSocket)
+	}
+	return socketRouter
+}
@@ -0,0 +1,19 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/handlers"
+)
+
+func RegisterTagHandlers(router *gin.Engine, handler *handlers.TagHandler) *gin.RouterGroup {
+	tagRouter := router.Group("/v1")
+	{
+		// Tag routes
+		tagRouter.GET("/tags", handler.GetTags)
+		tagRouter.GET("/tags/:id", handler.GetTagByID)
+		tagRouter.POST("/tags", handler.CreateTag)
+		tagRouter.PUT("/tags/:id", handler.UpdateTag)
+		tagRouter.DELETE("/tags/:id", handler.DeleteTag)
+	}
+	return tagRouter
+}
@@ -0,0 +1,25 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ssitko/hex-domain/internal/handlers"
+)
+
+func RegisterWebhookHandlers(router *gin.Engine, handler *handlers.WebhookHandler) *gin.RouterGroup {
+	// Webhooks receive drafts as well and make the server call out, so they are limited to editors
+	webhookRouter := router.Group("/v1", handlers.RequireEditor)
+	{
+		// Webhook routes
+		webhookRouter.GET("/webhooks", handler.GetWebhooks)
+		webhookRouter.GET("/webhooks/:id", handler.GetWebhookByID)
+		webhookRouter.POST("/webhooks", handler.CreateWebhook)
+		webhookRouter.PUT("/webhooks/:id", handler.UpdateWebhook)
+		webhookRouter.DELETE("/webhooks/:id", handler.DeleteWebhook)
+		webhookRouter.POST("/webhooks/:id/test", handler.TestWebhook)
+
+		// Delivery log routes
+		webhookRouter.GET("/webhooks/:id/deliveries", handler.GetWebhookDeliveries)
+		webhookRouter.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", handler.RedeliverWebhook)
+	}
+	return webhookRouter
+}
@@ -1,36 +1,335 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/ssitko/hex-domain/internal/domain"
 	"github.com/ssitko/hex-domain/internal/repositories"
 )
 
+// Albums loaded per query by ExportAlbums.
+const exportBatchSize = 500
+
 // Service Layer
 // Orchestrates the business logic and interacts with the repository.
 type AlbumService struct {
-	repo repositories.AlbumRepository
+	repo   repositories.AlbumRepository
+	events []domain.AlbumEventPublisher
+}
+
+// AlbumServiceOption configures optional collaborators of the album service.
+type AlbumServiceOption func(*AlbumService)
+
+// WithEvents publishes every album change to publisher, in addition to those given before.
+func WithEvents(publisher domain.AlbumEventPublisher) AlbumServiceOption {
+	return func(s *AlbumService) {
+		s.events = append(s.events, publisher)
+	}
+}
+
+func NewAlbumService(repo repositories.AlbumRepository, opts ...AlbumServiceOption) *AlbumService {
+	s := &AlbumService{repo: repo}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *AlbumService) GetAllAlbums(filter domain.AlbumFilter) ([]domain.Album, error) {
+	return s.repo.GetAll(normalizeAlbumFilter(filter))
 }
 
-func NewAlbumService(repo repositories.AlbumRepository) *AlbumService {
-	return &AlbumService{repo: repo}
+// ExportAlbums streams the albums matching filter with an ID above afterID to batch in ID order,
+// exportBatchSize at a time. An export is resumed by passing the last ID it delivered.
+func (s *AlbumService) ExportAlbums(filter domain.AlbumFilter, afterID uint, batch func(albums []domain.Album) error) error {
+	return s.repo.EachBatch(normalizeAlbumFilter(filter), afterID, exportBatchSize, batch)
 }
 
-func (s *AlbumService) GetAllAlbums() ([]domain.Album, error) {
-	return s.repo.GetAll()
+// normalizeAlbumFilter matches genre and tag names the way they are stored. The names are
+// normalized into copies, the slices of the caller are left as they are.
+func normalizeAlbumFilter(filter domain.AlbumFilter) domain.AlbumFilter {
+	filter.Tags = slices.Clone(filter.Tags)
+	for i, tag := range filter.Tags {
+		filter.Tags[i] = domain.NormalizeTagName(tag)
+	}
+	filter.Genres = slices.Clone(filter.Genres)
+	for i, genre := range filter.Genres {
+		filter.Genres[i] = domain.NormalizeGenreName(genre)
+	}
+	return filter
 }
 
 func (s *AlbumService) GetAlbumByID(id int) (domain.Album, error) {
 	return s.repo.GetByID(id)
 }
 
+// GetAlbumPriceHistory returns the album price series over the query range.
+func (s *AlbumService) GetAlbumPriceHistory(id int, query domain.PriceHistoryQuery) (domain.PriceHistory, error) {
+	if err := query.Validate(); err != nil {
+		return domain.PriceHistory{}, err
+	}
+	if _, err := s.repo.GetByID(id); err != nil {
+		return domain.PriceHistory{}, err
+	}
+	// Lowest30Days looks back over the Omnibus window, which may start before the range
+	from := query.From
+	if windowStart := query.To.Add(-domain.OmnibusWindow); windowStart.Before(from) {
+		from = windowStart
+	}
+	changes, err := s.repo.GetPriceChanges(id, from, query.To)
+	if err != nil {
+		return domain.PriceHistory{}, err
+	}
+	return domain.BuildPriceHistory(uint(id), changes, query), nil
+}
+
+func (s *AlbumService) GetAlbumsByBarcode(code string) ([]domain.Album, error) {
+	return s.repo.GetByBarcode(domain.NormalizeBarcode(code))
+}
+
+// CreateAlbum adds the album as a draft, it becomes public once published.
 func (s *AlbumService) CreateAlbum(album domain.Album) (domain.Album, error) {
-	return s.repo.Create(album)
+	if err := s.validate(&album); err != nil {
+		return domain.Album{}, err
+	}
+	album.KeepManagedFields(domain.Album{Status: domain.AlbumDraft})
+	return s.published(domain.AlbumCreated)(s.repo.Create(album))
 }
 
+// UpdateAlbum saves the album, its workflow status is kept.
 func (s *AlbumService) UpdateAlbum(album domain.Album) (domain.Album, error) {
-	return s.repo.Update(album)
+	if err := s.validate(&album); err != nil {
+		return domain.Album{}, err
+	}
+	eventType := domain.AlbumUpdated
+	if album.ID == 0 {
+		album.KeepManagedFields(domain.Album{Status: domain.AlbumDraft})
+		eventType = domain.AlbumCreated
+	}
+	return s.published(eventType)(s.repo.Update(album))
+}
+
+// ImportAlbums matches the rows to stored albums by label and barcode and reports whether each
+// creates or updates an album or is rejected. Unless dryRun, the albums are saved in one
+// transaction, provided no row is rejected, otherwise ErrImportRejected is returned with the report.
+func (s *AlbumService) ImportAlbums(rows []domain.ImportRow, dryRun bool) (domain.ImportReport, error) {
+	report := domain.ImportReport{DryRun: dryRun, Rows: make([]domain.ImportRowResult, 0, len(rows))}
+	var albums []domain.Album
+	var results []int
+	keys := map[string]int{}
+	for _, row := range rows {
+		album, err := s.planImport(row, keys)
+		if err != nil && !errors.Is(err, domain.ErrInvalidAlbum) {
+			return report, err
+		}
+		if err != nil {
+			report.Add(domain.ImportRowResult{Row: row.Row, Action: domain.ImportRejected, Error: err.Error()})
+			continue
+		}
+		action := domain.ImportCreated
+		if album.ID != 0 {
+			action = domain.ImportUpdated
+		}
+		report.Add(domain.ImportRowResult{Row: row.Row, Action: action, AlbumID: album.ID})
+		albums = append(albums, album)
+		results = append(results, len(report.Rows)-1)
+	}
+	if dryRun {
+		return report, nil
+	}
+	if report.Rejected > 0 {
+		return report, domain.ErrImportRejected
+	}
+	if len(albums) == 0 {
+		return report, nil
+	}
+
+	saved, err := s.repo.SaveAll(albums)
+	if err != nil {
+		return report, err
+	}
+	for i, album := range saved {
+		eventType := domain.AlbumUpdated
+		if report.Rows[results[i]].Action == domain.ImportCreated {
+			eventType = domain.AlbumCreated
+		}
+		report.Rows[results[i]].AlbumID = album.ID
+		s.publish(eventType, album)
+	}
+	return report, nil
+}
+
+// planImport returns the album a row saves, carrying the ID of the stored album it updates, or
+// why the row is rejected wrapping ErrInvalidAlbum. keys maps the natural keys of the rows
+// planned so far to their row.
+func (s *AlbumService) planImport(row domain.ImportRow, keys map[string]int) (domain.Album, error) {
+	if row.Err != nil {
+		return domain.Album{}, row.Err
+	}
+	key := row.Album.ImportKey()
+	if previous, ok := keys[key]; ok {
+		return domain.Album{}, fmt.Errorf("%w: same label and barcode as row %d", domain.ErrInvalidAlbum, previous)
+	}
+
+	album := row.Album
+	stored, err := s.findImported(row.Album)
+	if err != nil {
+		return domain.Album{}, err
+	}
+	if stored != nil {
+		album = *stored
+		album.MergeImported(row)
+	} else {
+		album.KeepManagedFields(domain.Album{Status: domain.AlbumDraft})
+	}
+	if err := album.ValidateImport(); err != nil {
+		return domain.Album{}, err
+	}
+	keys[key] = row.Row
+	album.PriceChangeReason = domain.PriceReasonImport
+	return album, nil
+}
+
+// findImported returns the stored album with the label and barcode of album, if any.
+func (s *AlbumService) findImported(album domain.Album) (*domain.Album, error) {
+	barcode := domain.NormalizeBarcode(album.Barcode)
+	if barcode == "" {
+		return nil, nil
+	}
+	existing, err := s.repo.GetByBarcode(barcode)
+	if err != nil {
+		return nil, err
+	}
+	for _, stored := range existing {
+		if stored.ImportKey() == album.ImportKey() {
+			return &stored, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *AlbumService) SubmitAlbum(id int) (domain.Album, error) {
+	return s.published(domain.AlbumUpdated)(s.repo.Modify(id, (*domain.Album).Submit))
+}
+
+func (s *AlbumService) ApproveAlbum(id int) (domain.Album, error) {
+	return s.published(domain.AlbumUpdated)(s.repo.Modify(id, func(album *domain.Album) error {
+		return album.Approve(time.Now().UTC())
+	}))
+}
+
+func (s *AlbumService) PublishAlbum(id int) (domain.Album, error) {
+	return s.published(domain.AlbumUpdated)(s.repo.Modify(id, func(album *domain.Album) error {
+		return album.Publish(time.Now().UTC())
+	}))
+}
+
+func (s *AlbumService) ArchiveAlbum(id int) (domain.Album, error) {
+	return s.published(domain.AlbumUpdated)(s.repo.Modify(id, (*domain.Album).Archive))
+}
+
+// validate checks the album and that no other album of the same label uses its barcode.
+func (s *AlbumService) validate(album *domain.Album) error {
+	if err := album.Validate(); err != nil {
+		return err
+	}
+	if album.Barcode == "" {
+		return nil
+	}
+	existing, err := s.repo.GetByBarcode(album.Barcode)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.ID != album.ID && strings.EqualFold(other.Label, album.Label) {
+			return domain.ErrDuplicateBarcode
+		}
+	}
+	return nil
+}
+
+func (s *AlbumService) GetAlbumTracks(id int) ([]domain.Track, error) {
+	return s.repo.GetTracks(id)
+}
+
+func (s *AlbumService) ReplaceAlbumTracks(id int, tracks []domain.Track) ([]domain.Track, error) {
+	if err := domain.ValidateTracklist(tracks); err != nil {
+		return nil, err
+	}
+	// A missing album is reported as not found rather than as a failed replacement
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+	replaced, err := s.repo.ReplaceTracks(id, tracks)
+	if err != nil || len(s.events) == 0 {
+		return replaced, err
+	}
+	if album, err := s.repo.GetByID(id); err == nil {
+		s.publish(domain.AlbumUpdated, album)
+	}
+	return replaced, nil
+}
+
+func (s *AlbumService) AddAlbumGenre(id int, genre string) (domain.Album, error) {
+	if err := s.repo.AddGenre(id, domain.NormalizeGenreName(genre)); err != nil {
+		return domain.Album{}, err
+	}
+	return s.published(domain.AlbumUpdated)(s.repo.GetByID(id))
+}
+
+func (s *AlbumService) RemoveAlbumGenre(id int, genre string) (domain.Album, error) {
+	if err := s.repo.RemoveGenre(id, domain.NormalizeGenreName(genre)); err != nil {
+		return domain.Album{}, err
+	}
+	return s.published(domain.AlbumUpdated)(s.repo.GetByID(id))
+}
+
+func (s *AlbumService) AddAlbumTag(id int, tag string) (domain.Album, error) {
+	if err := s.repo.AddTag(id, domain.NormalizeTagName(tag)); err != nil {
+		return domain.Album{}, err
+	}
+	return s.published(domain.AlbumUpdated)(s.repo.GetByID(id))
+}
+
+func (s *AlbumService) RemoveAlbumTag(id int, tag string) (domain.Album, error) {
+	if err := s.repo.RemoveTag(id, domain.NormalizeTagName(tag)); err != nil {
+		return domain.Album{}, err
+	}
+	return s.published(domain.AlbumUpdated)(s.repo.GetByID(id))
 }
 
 func (s *AlbumService) DeleteAlbum(id int) error {
-	return s.repo.Delete(id)
+	if len(s.events) == 0 {
+		return s.repo.Delete(id)
+	}
+	album, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.publish(domain.AlbumDeleted, album)
+	return nil
+}
+
+// published returns a function passing on the result of a write, publishing the album as an
+// event of eventType when the write succeeded.
+func (s *AlbumService) published(eventType domain.AlbumEventType) func(domain.Album, error) (domain.Album, error) {
+	return func(album domain.Album, err error) (domain.Album, error) {
+		if err == nil {
+			s.publish(eventType, album)
+		}
+		return album, err
+	}
+}
+
+func (s *AlbumService) publish(eventType domain.AlbumEventType, album domain.Album) {
+	for _, publisher := range s.events {
+		publisher.Publish(eventType, album)
+	}
 }
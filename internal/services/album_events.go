@@ -0,0 +1,130 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// AlbumEventBroker hands the album changes published by the album service to subscribers in
+// this process. It keeps the latest events for subscribers resuming after a reconnect. Each
+// subscriber has a bounded queue, one that falls further behind is dropped rather than slowing
+// down publishers or holding events in memory, and resumes from the buffer when it reconnects.
+//
+// Event IDs are prefixed with the start time of the broker, an ID of an earlier run is
+// reported as a gap. Nothing is shared between replicas: subscribers only see the changes made
+// through their own process, so the event stream and sockets are complete with one replica only.
+type AlbumEventBroker struct {
+	mu          sync.Mutex
+	epoch       string
+	sequence    uint64
+	replay      []domain.AlbumEvent
+	replaySize  int
+	queueSize   int
+	subscribers map[*albumSubscriber]struct{}
+	closed      bool
+}
+
+type albumSubscriber struct {
+	filter domain.AlbumEventFilter
+	events chan domain.AlbumEvent
+}
+
+func NewAlbumEventBroker(replaySize, queueSize int) *AlbumEventBroker {
+	return &AlbumEventBroker{
+		epoch:       strconv.FormatInt(time.Now().UnixMilli(), 36),
+		replaySize:  max(replaySize, 1),
+		queueSize:   queueSize,
+		subscribers: map[*albumSubscriber]struct{}{},
+	}
+}
+
+func (b *AlbumEventBroker) Publish(eventType domain.AlbumEventType, album domain.Album) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sequence++
+	event := domain.AlbumEvent{
+		ID:    fmt.Sprintf("%s-%d", b.epoch, b.sequence),
+		Type:  eventType,
+		Album: album,
+		At:    time.Now().UTC(),
+	}
+	if len(b.replay) == b.replaySize {
+		b.replay = b.replay[1:]
+	}
+	b.replay = append(b.replay, event)
+
+	for subscriber := range b.subscribers {
+		if !subscriber.filter.Matches(event) {
+			continue
+		}
+		select {
+		case subscriber.events <- event:
+		default:
+			b.drop(subscriber)
+		}
+	}
+}
+
+func (b *AlbumEventBroker) Subscribe(filter domain.AlbumEventFilter, lastEventID string) domain.AlbumSubscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	subscriber := &albumSubscriber{filter: filter, events: make(chan domain.AlbumEvent, b.queueSize)}
+	b.subscribers[subscriber] = struct{}{}
+	if b.closed {
+		b.drop(subscriber)
+	}
+	subscription := domain.AlbumSubscription{
+		Events: subscriber.events,
+		Close: func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.drop(subscriber)
+		},
+	}
+	if lastEventID == "" {
+		return subscription
+	}
+
+	epoch, sequence, _ := strings.Cut(lastEventID, "-")
+	last, err := strconv.ParseUint(sequence, 10, 64)
+	if epoch != b.epoch || err != nil || last > b.sequence {
+		subscription.Gap = true
+		return subscription
+	}
+	// Sequences in the buffer are consecutive, anything older than its first event was dropped
+	oldest, start := b.sequence-uint64(len(b.replay))+1, 0
+	if last >= oldest {
+		start = int(last - oldest + 1)
+	}
+	subscription.Gap = last+1 < oldest
+	for _, event := range b.replay[start:] {
+		if filter.Matches(event) {
+			subscription.Replay = append(subscription.Replay, event)
+		}
+	}
+	return subscription
+}
+
+// Close ends every subscription, for the server to shut down without waiting for subscribers
+// to go away. Subscribing afterwards returns closed subscriptions.
+func (b *AlbumEventBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for subscriber := range b.subscribers {
+		b.drop(subscriber)
+	}
+}
+
+// drop closes the queue of a subscriber, once.
+func (b *AlbumEventBroker) drop(subscriber *albumSubscriber) {
+	if _, ok := b.subscribers[subscriber]; ok {
+		delete(b.subscribers, subscriber)
+		close(subscriber.events)
+	}
+}
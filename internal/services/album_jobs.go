@@ -0,0 +1,129 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+)
+
+// Albums per result part of an export job. Each part is stored when complete, an interrupted
+// export resumes after the last stored part.
+const exportPartSize = 2000
+
+// ImportJob runs import jobs like ImportAlbums, the uploaded file is read from the blob store.
+// The import is saved in one transaction, so an interrupted job is simply run again.
+type ImportJob struct {
+	albums domain.AlbumService
+	blobs  domain.BlobStore
+	files  domain.AlbumFiles
+}
+
+func NewImportJob(albums domain.AlbumService, blobs domain.BlobStore, files domain.AlbumFiles) *ImportJob {
+	return &ImportJob{albums: albums, blobs: blobs, files: files}
+}
+
+func (j *ImportJob) Execute(ctx context.Context, job domain.Job, progress domain.JobProgress) error {
+	var params domain.ImportParams
+	if err := json.Unmarshal([]byte(job.Params), &params); err != nil {
+		return err
+	}
+	input, err := j.blobs.Open(job.InputKey)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	rows, err := j.files.ReadImport(params, input)
+	if err != nil {
+		return err
+	}
+	total := len(rows)
+	if _, err := progress(func(job *domain.Job) { job.Total = &total }); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	report, err := j.albums.ImportAlbums(rows, params.DryRun)
+	if err != nil && !errors.Is(err, domain.ErrImportRejected) {
+		return err
+	}
+	// The report of a rejected import tells which rows to fix
+	if _, saveErr := progress(func(job *domain.Job) { job.Processed, job.Report = total, &report }); saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+// ExportJob runs export jobs like ExportAlbums, writing the result to the blob store in parts.
+type ExportJob struct {
+	albums domain.AlbumService
+	blobs  domain.BlobStore
+	files  domain.AlbumFiles
+}
+
+func NewExportJob(albums domain.AlbumService, blobs domain.BlobStore, files domain.AlbumFiles) *ExportJob {
+	return &ExportJob{albums: albums, blobs: blobs, files: files}
+}
+
+func (j *ExportJob) Execute(ctx context.Context, job domain.Job, progress domain.JobProgress) error {
+	var params domain.ExportParams
+	if err := json.Unmarshal([]byte(job.Params), &params); err != nil {
+		return err
+	}
+
+	var part bytes.Buffer
+	rows, processed, lastID := 0, job.Processed, job.Cursor
+	// Only the first part starts with the header of the format
+	writer, contentType := j.files.NewExportWriter(params.Format, &part, job.Parts == 0)
+	// store saves the rows up to lastID as the next part, a part stored again after an
+	// interruption overwrites the earlier attempt
+	store := func() error {
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		next := job.Parts + 1
+		if err := j.blobs.Put(job.ResultKey(next), part.Bytes(), contentType); err != nil {
+			return err
+		}
+		saved, err := progress(func(job *domain.Job) {
+			job.Parts, job.Cursor, job.Processed, job.ResultType = next, lastID, processed, contentType
+		})
+		if err != nil {
+			return err
+		}
+		job, rows = saved, 0
+		part.Reset()
+		return nil
+	}
+
+	err := j.albums.ExportAlbums(params.Filter, job.Cursor, func(albums []domain.Album) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := writer.Write(albums); err != nil {
+			return err
+		}
+		rows += len(albums)
+		processed += len(albums)
+		lastID = albums[len(albums)-1].ID
+		if rows >= exportPartSize {
+			return store()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	if part.Len() > 0 || job.Parts == 0 {
+		// The last part, an export without albums has one empty part or the CSV header
+		return store()
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// memoryBlobs is a blob store keeping blobs in a map.
+type memoryBlobs map[string][]byte
+
+func (b memoryBlobs) Put(key string, content []byte, contentType string) error {
+	b[key] = append([]byte(nil), content...)
+	return nil
+}
+
+func (b memoryBlobs) Open(key string) (io.ReadCloser, error) {
+	content, ok := b[key]
+	if !ok {
+		return nil, domain.ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (b memoryBlobs) Delete(key string) error {
+	delete(b, key)
+	return nil
+}
+
+// lineFiles is an AlbumFiles writing an album ID per line after an "id" header. Imports are read
+// as a row per line, each rejected.
+type lineFiles struct{}
+
+func (lineFiles) ReadImport(params domain.ImportParams, input io.Reader) ([]domain.ImportRow, error) {
+	content, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	var rows []domain.ImportRow
+	for i, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		rows = append(rows, domain.ImportRow{Row: i + 1, Err: fmt.Errorf("%w: %s is not an album", domain.ErrInvalidAlbum, line)})
+	}
+	return rows, nil
+}
+
+func (lineFiles) NewExportWriter(format string, w io.Writer, header bool) (domain.AlbumWriter, string) {
+	if header {
+		fmt.Fprintln(w, "id")
+	}
+	return lineWriter{w}, "text/plain"
+}
+
+type lineWriter struct {
+	w io.Writer
+}
+
+func (l lineWriter) Write(albums []domain.Album) error {
+	for _, album := range albums {
+		fmt.Fprintln(l.w, album.ID)
+	}
+	return nil
+}
+
+func (lineWriter) Flush() error {
+	return nil
+}
+
+func TestAlbumJobs(t *testing.T) {
+	// progress applies updates to the job the way the job runner saves them
+	progressOf := func(job *domain.Job) domain.JobProgress {
+		return func(update func(job *domain.Job)) (domain.Job, error) {
+			if update != nil {
+				update(job)
+			}
+			return *job, nil
+		}
+	}
+	// batches has the repository hand out albums in batches of IDs [from, to]
+	batches := func(ranges ...[2]uint) func(args mock.Arguments) {
+		return func(args mock.Arguments) {
+			batch := args.Get(3).(func(albums []domain.Album) error)
+			for _, ids := range ranges {
+				var albums []domain.Album
+				for id := ids[0]; id <= ids[1]; id++ {
+					albums = append(albums, domain.Album{ID: id})
+				}
+				if batch(albums) != nil {
+					return
+				}
+			}
+		}
+	}
+
+	t.Run("ExportJob :: writes the export in parts and resumes after the last one", func(t *testing.T) {
+		repo, blobs := new(MockAlbumRepository), memoryBlobs{}
+		filter := domain.AlbumFilter{Status: domain.AlbumPublished}
+		params, _ := json.Marshal(domain.ExportParams{Format: "csv", Filter: filter})
+		job := domain.Job{ID: 3, Kind: domain.JobExport, Params: string(params)}
+		export := NewExportJob(NewAlbumService(repo), blobs, lineFiles{})
+
+		// The first run stops after the first part
+		repo.On("EachBatch", filter, uint(0), exportBatchSize, mock.Anything).
+			Run(batches([2]uint{1, 1000}, [2]uint{1001, 2000}, [2]uint{2001, 2500})).Return(context.Canceled).Once()
+		err := export.Execute(context.Background(), job, progressOf(&job))
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, job.Parts)
+		assert.Equal(t, uint(2000), job.Cursor)
+		assert.Equal(t, 2000, job.Processed)
+
+		repo.On("EachBatch", filter, uint(2000), exportBatchSize, mock.Anything).
+			Run(batches([2]uint{2001, 2500})).Return(nil).Once()
+		err = export.Execute(context.Background(), job, progressOf(&job))
+		assert.Nil(t, err)
+		assert.Equal(t, 2, job.Parts)
+		assert.Equal(t, 2500, job.Processed)
+		assert.Equal(t, "text/plain", job.ResultType)
+
+		lines := strings.Split(strings.TrimSpace(string(blobs[job.ResultKey(1)])+string(blobs[job.ResultKey(2)])), "\n")
+		assert.Len(t, lines, 2501)
+		assert.Equal(t, "id", lines[0])
+		assert.Equal(t, "2001", lines[2001])
+		assert.Equal(t, "2500", lines[2500])
+	})
+
+	t.Run("ExportJob :: stores an empty export", func(t *testing.T) {
+		repo, blobs := new(MockAlbumRepository), memoryBlobs{}
+		params, _ := json.Marshal(domain.ExportParams{Format: "csv"})
+		job := domain.Job{ID: 4, Kind: domain.JobExport, Params: string(params)}
+		repo.On("EachBatch", domain.AlbumFilter{}, uint(0), exportBatchSize, mock.Anything).Return(nil).Once()
+
+		assert.Nil(t, NewExportJob(NewAlbumService(repo), blobs, lineFiles{}).Execute(context.Background(), job, progressOf(&job)))
+		assert.Equal(t, 1, job.Parts)
+		assert.Equal(t, "id\n", string(blobs[job.ResultKey(1)]))
+	})
+
+	t.Run("ImportJob :: imports the stored upload and keeps the report of a rejected import", func(t *testing.T) {
+		repo := new(MockAlbumRepository)
+		blobs := memoryBlobs{"jobs/inputs/upload": []byte("first\nsecond\n")}
+		params, _ := json.Marshal(domain.ImportParams{ContentType: "text/csv"})
+		job := domain.Job{ID: 5, Kind: domain.JobImport, Params: string(params), InputKey: "jobs/inputs/upload"}
+
+		err := NewImportJob(NewAlbumService(repo), blobs, lineFiles{}).Execute(context.Background(), job, progressOf(&job))
+		assert.ErrorIs(t, err, domain.ErrImportRejected)
+		assert.Equal(t, 2, *job.Total)
+		assert.Equal(t, 2, job.Processed)
+		assert.Equal(t, 2, job.Report.Rejected)
+		assert.Contains(t, job.Report.Rows[1].Error, "second is not an album")
+		repo.AssertNotCalled(t, "SaveAll", mock.Anything)
+	})
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAlbumRepository is a mock implementation of the AlbumRepository interface (contained in the repositories package)
+type MockAlbumRepository struct {
+	mock.Mock
+}
+
+func (m *MockAlbumRepository) GetAll(filter domain.AlbumFilter) ([]domain.Album, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]domain.Album), args.Error(1)
+}
+
+func (m *MockAlbumRepository) EachBatch(filter domain.AlbumFilter, afterID uint, size int, fn func(albums []domain.Album) error) error {
+	args := m.Called(filter, afterID, size, fn)
+	return args.Error(0)
+}
+
+func (m *MockAlbumRepository) GetByID(id int) (domain.Album, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Album), args.Error(1)
+}
+
+func (m *MockAlbumRepository) GetByArtistID(artistID int) ([]domain.Album, error) {
+	args := m.Called(artistID)
+	return args.Get(0).([]domain.Album), args.Error(1)
+}
+
+func (m *MockAlbumRepository) GetByBarcode(code string) ([]domain.Album, error) {
+	args := m.Called(code)
+	return args.Get(0).([]domain.Album), args.Error(1)
+}
+
+func (m *MockAlbumRepository) GetTracks(albumID int) ([]domain.Track, error) {
+	args := m.Called(albumID)
+	return args.Get(0).([]domain.Track), args.Error(1)
+}
+
+func (m *MockAlbumRepository) GetPriceChanges(albumID int, from, to time.Time) ([]domain.PriceChange, error) {
+	args := m.Called(albumID, from, to)
+	return args.Get(0).([]domain.PriceChange), args.Error(1)
+}
+
+func (m *MockAlbumRepository) ReplaceTracks(albumID int, tracks []domain.Track) ([]domain.Track, error) {
+	args := m.Called(albumID, tracks)
+	return args.Get(0).([]domain.Track), args.Error(1)
+}
+
+func (m *MockAlbumRepository) AddGenre(albumID int, genre string) error {
+	return m.Called(albumID, genre).Error(0)
+}
+
+func (m *MockAlbumRepository) RemoveGenre(albumID int, genre string) error {
+	return m.Called(albumID, genre).Error(0)
+}
+
+func (m *MockAlbumRepository) AddTag(albumID int, tag string) error {
+	return m.Called(albumID, tag).Error(0)
+}
+
+func (m *MockAlbumRepository) RemoveTag(albumID int, tag string) error {
+	return m.Called(albumID, tag).Error(0)
+}
+
+func (m *MockAlbumRepository) Create(album domain.Album) (domain.Album, error) {
+	args := m.Called(album)
+	return args.Get(0).(domain.Album), args.Error(1)
+}
+
+func (m *MockAlbumRepository) Update(album domain.Album) (domain.Album, error) {
+	args := m.Called(album)
+	return args.Get(0).(domain.Album), args.Error(1)
+}
+
+func (m *MockAlbumRepository) SaveAll(albums []domain.Album) ([]domain.Album, error) {
+	args := m.Called(albums)
+	return args.Get(0).([]domain.Album), args.Error(1)
+}
+
+func (m *MockAlbumRepository) Modify(id int, change func(album *domain.Album) error) (domain.Album, error) {
+	args := m.Called(id, change)
+	return args.Get(0).(domain.Album), args.Error(1)
+}
+
+func (m *MockAlbumRepository) GetStaleThumbnails(now time.Time, limit int) ([]domain.Album, error) {
+	args := m.Called(now, limit)
+	return args.Get(0).([]domain.Album), args.Error(1)
+}
+
+func (m *MockAlbumRepository) Delete(id int) error {
+	return m.Called(id).Error(0)
+}
+
+func TestAlbumService(t *testing.T) {
+	t.Run("GetAlbumPriceHistory :: finds the lowest price of the 30 days before a short range", func(t *testing.T) {
+		repo := new(MockAlbumRepository)
+		to := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+		query := domain.PriceHistoryQuery{From: to.AddDate(0, 0, -7), To: to}
+		changes := []domain.PriceChange{
+			{Price: 24.99, ChangedAt: to.AddDate(0, 0, -60)},
+			{Price: 14.99, ChangedAt: to.AddDate(0, 0, -20)},
+			{Price: 19.99, ChangedAt: to.AddDate(0, 0, -10)},
+		}
+		repo.On("GetByID", 1).Return(domain.Album{ID: 1}, nil)
+		repo.On("GetPriceChanges", 1, to.Add(-domain.OmnibusWindow), to).Return(changes, nil)
+		service := NewAlbumService(repo)
+
+		history, err := service.GetAlbumPriceHistory(1, query)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 14.99, *history.Lowest30Days)
+		assert.Equal(t, 19.99, *history.Min)
+		assert.Empty(t, history.Changes)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("ReplaceAlbumTracks :: reports a missing album", func(t *testing.T) {
+		repo := new(MockAlbumRepository)
+		repo.On("GetByID", 7).Return(domain.Album{}, domain.ErrNotFound)
+		service := NewAlbumService(repo)
+
+		_, err := service.ReplaceAlbumTracks(7, []domain.Track{{Position: 1, Title: "Intro"}})
+
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		repo.AssertNotCalled(t, "ReplaceTracks", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ReplaceAlbumTracks :: replaces the tracklist of an album", func(t *testing.T) {
+		repo := new(MockAlbumRepository)
+		tracks := []domain.Track{{Position: 1, Title: "Intro"}}
+		repo.On("GetByID", 1).Return(domain.Album{ID: 1}, nil)
+		repo.On("ReplaceTracks", 1, tracks).Return(tracks, nil)
+		service := NewAlbumService(repo)
+
+		replaced, err := service.ReplaceAlbumTracks(1, tracks)
+
+		assert.Nil(t, err)
+		assert.Equal(t, tracks, replaced)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("ImportAlbums :: refuses an import whose every row is rejected", func(t *testing.T) {
+		repo := new(MockAlbumRepository)
+		service := NewAlbumService(repo)
+		rows := []domain.ImportRow{
+			{Row: 2, Err: fmt.Errorf("%w: title is required", domain.ErrInvalidAlbum)},
+			{Row: 3, Err: fmt.Errorf("%w: price must be a number", domain.ErrInvalidAlbum)},
+		}
+
+		report, err := service.ImportAlbums(rows, false)
+
+		assert.ErrorIs(t, err, domain.ErrImportRejected)
+		assert.Equal(t, 2, report.Rejected)
+		repo.AssertNotCalled(t, "SaveAll", mock.Anything)
+
+		report, err = service.ImportAlbums(rows, true)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, report.Rejected)
+	})
+
+	t.Run("GetAllAlbums :: normalizes filter names without changing the caller's filter", func(t *testing.T) {
+		repo := new(MockAlbumRepository)
+		filter := domain.AlbumFilter{Tags: []string{" Late  Night "}, Genres: []string{"Hard  Rock"}}
+		repo.On("GetAll", domain.AlbumFilter{Tags: []string{"late night"}, Genres: []string{"Hard Rock"}}).Return([]domain.Album{}, nil)
+		service := NewAlbumService(repo)
+
+		_, err := service.GetAllAlbums(filter)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{" Late  Night "}, filter.Tags)
+		assert.Equal(t, []string{"Hard  Rock"}, filter.Genres)
+		repo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/repositories"
+)
+
+// Artist service, keeps artist names unique across spelling variants.
+type ArtistService struct {
+	repo   repositories.ArtistRepository
+	albums repositories.AlbumRepository
+}
+
+func NewArtistService(repo repositories.ArtistRepository, albums repositories.AlbumRepository) *ArtistService {
+	return &ArtistService{repo: repo, albums: albums}
+}
+
+func (s *ArtistService) GetAllArtists() ([]domain.Artist, error) {
+	return s.repo.GetAll()
+}
+
+func (s *ArtistService) GetArtistByID(id int) (domain.Artist, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *ArtistService) GetAlbumsByArtist(id int) ([]domain.Album, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+	return s.albums.GetByArtistID(id)
+}
+
+func (s *ArtistService) CreateArtist(artist domain.Artist) (domain.Artist, error) {
+	artist.NormalizedName = domain.NormalizeArtistName(artist.Name)
+	if err := s.ensureUnique(artist); err != nil {
+		return domain.Artist{}, err
+	}
+	return s.repo.Create(artist)
+}
+
+func (s *ArtistService) UpdateArtist(artist domain.Artist) (domain.Artist, error) {
+	artist.NormalizedName = domain.NormalizeArtistName(artist.Name)
+	if err := s.ensureUnique(artist); err != nil {
+		return domain.Artist{}, err
+	}
+	return s.repo.Update(artist)
+}
+
+func (s *ArtistService) DeleteArtist(id int) error {
+	albums, err := s.albums.GetByArtistID(id)
+	if err != nil {
+		return err
+	}
+	if len(albums) > 0 {
+		return domain.ErrArtistHasAlbums
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *ArtistService) ensureUnique(artist domain.Artist) error {
+	existing, err := s.repo.FindByNormalizedName(artist.NormalizedName)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.ID != artist.ID {
+			return domain.ErrArtistExists
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/repositories"
+)
+
+// Uploads waiting for thumbnails, further uploads are picked up by the worker's periodic sweep.
+const thumbnailQueueSize = 64
+
+// Cover service, images live in the blob store and the album keeps their metadata.
+type CoverService struct {
+	albums  repositories.AlbumRepository
+	blobs   domain.BlobStore
+	resizer domain.ImageResizer
+	queue   chan int
+}
+
+func NewCoverService(albums repositories.AlbumRepository, blobs domain.BlobStore, resizer domain.ImageResizer) *CoverService {
+	return &CoverService{albums: albums, blobs: blobs, resizer: resizer, queue: make(chan int, thumbnailQueueSize)}
+}
+
+// UploadCover stores the image and points the album at it. The previous image and its
+// thumbnails are removed afterwards, blobs left over when that fails are never served again.
+func (s *CoverService) UploadCover(albumID int, content []byte) (domain.Album, error) {
+	if _, err := s.albums.GetByID(albumID); err != nil {
+		return domain.Album{}, err
+	}
+	cover, err := domain.NewCoverImage(uint(albumID), content, time.Now().UTC())
+	if err != nil {
+		return domain.Album{}, err
+	}
+	if err := s.blobs.Put(cover.Key, content, cover.ContentType); err != nil {
+		return domain.Album{}, err
+	}
+	var previous domain.CoverImage
+	album, err := s.albums.Modify(albumID, func(album *domain.Album) error {
+		previous = album.Cover
+		if previous.Checksum == cover.Checksum {
+			// Same image again, its thumbnails are still valid
+			cover.ThumbnailChecksum = previous.ThumbnailChecksum
+		}
+		album.Cover = cover
+		return nil
+	})
+	if err != nil {
+		return domain.Album{}, err
+	}
+	if previous.Exists() && previous.Key != cover.Key {
+		s.blobs.Delete(previous.Key)
+		for _, key := range previous.ThumbnailKeys() {
+			s.blobs.Delete(key)
+		}
+	}
+	if !album.Cover.ThumbnailsReady() {
+		select {
+		case s.queue <- albumID:
+		default:
+		}
+	}
+	return album, nil
+}
+
+func (s *CoverService) OpenCover(album domain.Album, size int, contentType string) (io.ReadCloser, error) {
+	if !album.Cover.Exists() {
+		return nil, domain.ErrNoCover
+	}
+	if size == 0 {
+		return s.blobs.Open(album.Cover.Key)
+	}
+	if err := domain.ValidateThumbnail(size, contentType); err != nil {
+		return nil, err
+	}
+	return s.blobs.Open(album.Cover.ThumbnailKey(size, contentType))
+}
+
+// GenerateThumbnails stores every thumbnail of the current cover and records the image they
+// were made from. Thumbnail keys derive from the image checksum, so running it twice for the
+// same image, or concurrently with an upload, only ever rewrites identical content. Failures
+// are recorded on the cover, which is not attempted again before its backoff passed.
+func (s *CoverService) GenerateThumbnails(albumID int) error {
+	album, err := s.albums.GetByID(albumID)
+	if err != nil {
+		return err
+	}
+	cover := album.Cover
+	if !cover.Exists() {
+		return domain.ErrNoCover
+	}
+	if !cover.ThumbnailsDue(time.Now().UTC()) {
+		return nil
+	}
+	if err := s.storeThumbnails(cover); err != nil {
+		return s.recordThumbnailFailure(albumID, cover, err)
+	}
+
+	_, err = s.albums.Modify(albumID, func(album *domain.Album) error {
+		// A newer upload replaced the image meanwhile, it gets its own thumbnails
+		if album.Cover.Checksum == cover.Checksum {
+			album.Cover.ThumbnailChecksum = cover.Checksum
+			album.Cover.ThumbnailAttempts, album.Cover.ThumbnailRetryAt = 0, nil
+		}
+		return nil
+	})
+	return err
+}
+
+// recordThumbnailFailure counts the failure on the cover unless a newer upload replaced it,
+// returning cause.
+func (s *CoverService) recordThumbnailFailure(albumID int, cover domain.CoverImage, cause error) error {
+	updated, err := s.albums.Modify(albumID, func(album *domain.Album) error {
+		if album.Cover.Checksum == cover.Checksum {
+			album.Cover.RecordThumbnailFailure(time.Now().UTC())
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%w, recording the failure: %s", cause, err)
+	}
+	if updated.Cover.Checksum == cover.Checksum && updated.Cover.ThumbnailAttempts >= domain.MaxThumbnailAttempts {
+		return fmt.Errorf("%w, giving up after %d attempts", cause, updated.Cover.ThumbnailAttempts)
+	}
+	return cause
+}
+
+func (s *CoverService) storeThumbnails(cover domain.CoverImage) error {
+	source, err := s.blobs.Open(cover.Key)
+	if err != nil {
+		return err
+	}
+	content, err := io.ReadAll(source)
+	source.Close()
+	if err != nil {
+		return err
+	}
+	thumbnails, err := s.resizer.Thumbnails(content, domain.CoverSizes, domain.ThumbnailTypes)
+	if err != nil {
+		return err
+	}
+	for _, thumbnail := range thumbnails {
+		if err := s.blobs.Put(cover.ThumbnailKey(thumbnail.Size, thumbnail.ContentType), thumbnail.Content, thumbnail.ContentType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBlobStore is a mock implementation of the BlobStore interface (contained in the Cover domain)
+type MockBlobStore struct {
+	mock.Mock
+}
+
+func (m *MockBlobStore) Put(key string, content []byte, contentType string) error {
+	return m.Called(key, content, contentType).Error(0)
+}
+
+func (m *MockBlobStore) Open(key string) (io.ReadCloser, error) {
+	args := m.Called(key)
+	return io.NopCloser(strings.NewReader(args.String(0))), args.Error(1)
+}
+
+func (m *MockBlobStore) Delete(key string) error {
+	return m.Called(key).Error(0)
+}
+
+// MockImageResizer is a mock implementation of the ImageResizer interface (contained in the Cover domain)
+type MockImageResizer struct {
+	mock.Mock
+}
+
+func (m *MockImageResizer) Thumbnails(source []byte, sizes []int, contentTypes []string) ([]domain.Thumbnail, error) {
+	args := m.Called(source, sizes, contentTypes)
+	return args.Get(0).([]domain.Thumbnail), args.Error(1)
+}
+
+func TestCoverService(t *testing.T) {
+	cover := domain.CoverImage{Key: "covers/1/abc.png", ContentType: "image/png", Checksum: "abc"}
+
+	t.Run("GenerateThumbnails :: records a failure and gives up at the limit", func(t *testing.T) {
+		repo, blobs, resizer := new(MockAlbumRepository), new(MockBlobStore), new(MockImageResizer)
+		failing := cover
+		failing.ThumbnailAttempts = domain.MaxThumbnailAttempts - 1
+		album := domain.Album{ID: 1, Cover: failing}
+		repo.On("GetByID", 1).Return(album, nil)
+		blobs.On("Open", cover.Key).Return("not an image", nil)
+		resizer.On("Thumbnails", []byte("not an image"), domain.CoverSizes, domain.ThumbnailTypes).
+			Return([]domain.Thumbnail(nil), domain.ErrInvalidCover)
+		givenUp := album
+		givenUp.Cover.ThumbnailAttempts = domain.MaxThumbnailAttempts
+		repo.On("Modify", 1, mock.Anything).Run(func(args mock.Arguments) {
+			changed := album
+			assert.Nil(t, args.Get(1).(func(album *domain.Album) error)(&changed))
+			assert.Equal(t, givenUp, changed)
+		}).Return(givenUp, nil)
+		service := NewCoverService(repo, blobs, resizer)
+
+		err := service.GenerateThumbnails(1)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidCover)
+		assert.Contains(t, err.Error(), "giving up")
+		repo.AssertExpectations(t)
+
+		// Given up covers are skipped, the sweep no longer lists them either
+		repo.On("GetByID", 2).Return(givenUp, nil)
+		assert.Nil(t, service.GenerateThumbnails(2))
+		blobs.AssertNumberOfCalls(t, "Open", 1)
+	})
+
+	t.Run("GenerateThumbnails :: stores thumbnails and clears failures", func(t *testing.T) {
+		repo, blobs, resizer := new(MockAlbumRepository), new(MockBlobStore), new(MockImageResizer)
+		retried := cover
+		retried.ThumbnailAttempts = 2
+		album := domain.Album{ID: 1, Cover: retried}
+		thumbnail := domain.Thumbnail{Size: 64, ContentType: "image/png", Content: []byte("thumbnail")}
+		repo.On("GetByID", 1).Return(album, nil)
+		blobs.On("Open", cover.Key).Return("image", nil)
+		resizer.On("Thumbnails", []byte("image"), domain.CoverSizes, domain.ThumbnailTypes).Return([]domain.Thumbnail{thumbnail}, nil)
+		blobs.On("Put", cover.ThumbnailKey(64, "image/png"), thumbnail.Content, "image/png").Return(nil)
+		repo.On("Modify", 1, mock.Anything).Run(func(args mock.Arguments) {
+			changed := album
+			assert.Nil(t, args.Get(1).(func(album *domain.Album) error)(&changed))
+			assert.True(t, changed.Cover.ThumbnailsReady())
+			assert.Zero(t, changed.Cover.ThumbnailAttempts)
+		}).Return(album, nil)
+		service := NewCoverService(repo, blobs, resizer)
+
+		assert.Nil(t, service.GenerateThumbnails(1))
+		repo.AssertExpectations(t)
+		blobs.AssertExpectations(t)
+	})
+
+	t.Run("GenerateThumbnails :: passes on failures to record the failure", func(t *testing.T) {
+		repo, blobs, resizer := new(MockAlbumRepository), new(MockBlobStore), new(MockImageResizer)
+		repo.On("GetByID", 1).Return(domain.Album{ID: 1, Cover: cover}, nil)
+		blobs.On("Open", cover.Key).Return("", domain.ErrBlobNotFound)
+		repo.On("Modify", 1, mock.Anything).Return(domain.Album{}, errors.New("connection refused"))
+		service := NewCoverService(repo, blobs, resizer)
+
+		err := service.GenerateThumbnails(1)
+
+		assert.ErrorIs(t, err, domain.ErrBlobNotFound)
+		assert.Contains(t, err.Error(), "connection refused")
+	})
+}
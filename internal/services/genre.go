@@ -0,0 +1,37 @@
+package services
+
+import (
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/repositories"
+)
+
+// Genre service, names are stored with normalized whitespace.
+type GenreService struct {
+	repo repositories.GenreRepository
+}
+
+func NewGenreService(repo repositories.GenreRepository) *GenreService {
+	return &GenreService{repo: repo}
+}
+
+func (s *GenreService) GetAllGenres() ([]domain.Genre, error) {
+	return s.repo.GetAll()
+}
+
+func (s *GenreService) GetGenreByID(id int) (domain.Genre, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *GenreService) CreateGenre(genre domain.Genre) (domain.Genre, error) {
+	genre.Name = domain.NormalizeGenreName(genre.Name)
+	return s.repo.Create(genre)
+}
+
+func (s *GenreService) UpdateGenre(genre domain.Genre) (domain.Genre, error) {
+	genre.Name = domain.NormalizeGenreName(genre.Name)
+	return s.repo.Update(genre)
+}
+
+func (s *GenreService) DeleteGenre(id int) error {
+	return s.repo.Delete(id)
+}
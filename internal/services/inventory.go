@@ -0,0 +1,71 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/repositories"
+)
+
+// Inventory service, tracks album stock per warehouse and raises low stock alerts.
+type InventoryService struct {
+	repo    repositories.InventoryRepository
+	alerter domain.StockAlerter
+}
+
+func NewInventoryService(repo repositories.InventoryRepository, alerter domain.StockAlerter) *InventoryService {
+	return &InventoryService{repo: repo, alerter: alerter}
+}
+
+func (s *InventoryService) GetStock(albumID int) (domain.StockSummary, error) {
+	levels, err := s.repo.GetStock(albumID)
+	if err != nil {
+		return domain.StockSummary{}, err
+	}
+	return domain.SummarizeStock(uint(albumID), levels), nil
+}
+
+func (s *InventoryService) SetStock(level domain.StockLevel) (domain.StockLevel, error) {
+	level.Warehouse = strings.TrimSpace(level.Warehouse)
+	if level.OnHand < 0 || level.LowStockThreshold < 0 {
+		return domain.StockLevel{}, domain.ErrInvalidQuantity
+	}
+	previous, updated, err := s.repo.SetStock(level)
+	if err != nil {
+		return domain.StockLevel{}, err
+	}
+	s.checkLowStock(previous, updated)
+	return updated, nil
+}
+
+func (s *InventoryService) Reserve(albumID int, warehouse string, quantity int) (domain.Reservation, error) {
+	if quantity <= 0 {
+		return domain.Reservation{}, domain.ErrInvalidQuantity
+	}
+	reservation, level, err := s.repo.Reserve(albumID, strings.TrimSpace(warehouse), quantity)
+	if err != nil {
+		return domain.Reservation{}, err
+	}
+	previous := level
+	previous.Reserved -= reservation.Quantity
+	s.checkLowStock(previous, level)
+	return reservation, nil
+}
+
+func (s *InventoryService) ReleaseReservation(albumID int, reservationID int) (domain.Reservation, error) {
+	return s.repo.Release(albumID, reservationID)
+}
+
+// CommitReservation ships reserved stock, which leaves availability as it is, so it never
+// raises an alert.
+func (s *InventoryService) CommitReservation(albumID int, reservationID int) (domain.Reservation, error) {
+	reservation, _, err := s.repo.Commit(albumID, reservationID)
+	return reservation, err
+}
+
+// checkLowStock alerts when a change took the level to its threshold.
+func (s *InventoryService) checkLowStock(previous, level domain.StockLevel) {
+	if s.alerter != nil && level.FellToLowStock(previous) {
+		s.alerter.LowStock(level)
+	}
+}
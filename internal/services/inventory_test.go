@@ -0,0 +1,98 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockInventoryRepository is a mock implementation of the InventoryRepository interface (contained in the repositories package)
+type MockInventoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockInventoryRepository) GetStock(albumID int) ([]domain.StockLevel, error) {
+	args := m.Called(albumID)
+	return args.Get(0).([]domain.StockLevel), args.Error(1)
+}
+
+func (m *MockInventoryRepository) SetStock(level domain.StockLevel) (domain.StockLevel, domain.StockLevel, error) {
+	args := m.Called(level)
+	return args.Get(0).(domain.StockLevel), args.Get(1).(domain.StockLevel), args.Error(2)
+}
+
+func (m *MockInventoryRepository) Reserve(albumID int, warehouse string, quantity int) (domain.Reservation, domain.StockLevel, error) {
+	args := m.Called(albumID, warehouse, quantity)
+	return args.Get(0).(domain.Reservation), args.Get(1).(domain.StockLevel), args.Error(2)
+}
+
+func (m *MockInventoryRepository) Release(albumID int, reservationID int) (domain.Reservation, error) {
+	args := m.Called(albumID, reservationID)
+	return args.Get(0).(domain.Reservation), args.Error(1)
+}
+
+func (m *MockInventoryRepository) Commit(albumID int, reservationID int) (domain.Reservation, domain.StockLevel, error) {
+	args := m.Called(albumID, reservationID)
+	return args.Get(0).(domain.Reservation), args.Get(1).(domain.StockLevel), args.Error(2)
+}
+
+// Records the levels alerted about.
+type recordingAlerter struct {
+	alerts []domain.StockLevel
+}
+
+func (a *recordingAlerter) LowStock(level domain.StockLevel) {
+	a.alerts = append(a.alerts, level)
+}
+
+func TestInventoryService(t *testing.T) {
+	t.Run("Reserve :: alerts once when stock falls to the threshold", func(t *testing.T) {
+		repo := new(MockInventoryRepository)
+		alerter := &recordingAlerter{}
+		service := NewInventoryService(repo, alerter)
+		crossing := domain.StockLevel{AlbumID: 1, Warehouse: "waw", OnHand: 5, Reserved: 3, LowStockThreshold: 2}
+		below := crossing
+		below.Reserved = 4
+		repo.On("Reserve", 1, "waw", 3).Return(domain.Reservation{Quantity: 3}, crossing, nil).Once()
+		repo.On("Reserve", 1, "waw", 1).Return(domain.Reservation{Quantity: 1}, below, nil).Once()
+
+		_, err := service.Reserve(1, "waw", 3)
+		assert.Nil(t, err)
+		_, err = service.Reserve(1, "waw", 1)
+		assert.Nil(t, err)
+
+		assert.Equal(t, []domain.StockLevel{crossing}, alerter.alerts)
+	})
+
+	t.Run("SetStock :: alerts only when the count crosses the threshold", func(t *testing.T) {
+		repo := new(MockInventoryRepository)
+		alerter := &recordingAlerter{}
+		service := NewInventoryService(repo, alerter)
+		low := domain.StockLevel{AlbumID: 1, Warehouse: "waw", OnHand: 1, LowStockThreshold: 2}
+		lower := low
+		lower.OnHand = 0
+		repo.On("SetStock", low).Return(domain.StockLevel{AlbumID: 1, Warehouse: "waw", OnHand: 9, LowStockThreshold: 2}, low, nil).Once()
+		repo.On("SetStock", lower).Return(low, lower, nil).Once()
+
+		_, err := service.SetStock(low)
+		assert.Nil(t, err)
+		_, err = service.SetStock(lower)
+		assert.Nil(t, err)
+
+		assert.Equal(t, []domain.StockLevel{low}, alerter.alerts)
+	})
+
+	t.Run("CommitReservation :: leaves availability and alerts alone", func(t *testing.T) {
+		repo := new(MockInventoryRepository)
+		alerter := &recordingAlerter{}
+		service := NewInventoryService(repo, alerter)
+		repo.On("Commit", 1, 7).Return(domain.Reservation{ID: 7}, domain.StockLevel{OnHand: 1, LowStockThreshold: 2}, nil)
+
+		_, err := service.CommitReservation(1, 7)
+
+		assert.Nil(t, err)
+		assert.Empty(t, alerter.alerts)
+	})
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/repositories"
+)
+
+// Job service, jobs are stored in the database and run by a JobRunner.
+type JobService struct {
+	repo      repositories.JobRepository
+	blobs     domain.BlobStore
+	executors map[domain.JobKind]domain.JobExecutor
+	// Wakes the runner when a job is queued, instead of waiting for its next poll
+	queued chan struct{}
+}
+
+func NewJobService(repo repositories.JobRepository, blobs domain.BlobStore, executors map[domain.JobKind]domain.JobExecutor) *JobService {
+	return &JobService{repo: repo, blobs: blobs, executors: executors, queued: make(chan struct{}, 1)}
+}
+
+func (s *JobService) StartJob(kind domain.JobKind, params interface{}, input []byte) (domain.Job, error) {
+	if _, ok := s.executors[kind]; !ok {
+		return domain.Job{}, fmt.Errorf("%w: unknown kind %q", domain.ErrInvalidJob, kind)
+	}
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return domain.Job{}, err
+	}
+	job := domain.Job{Kind: kind, Status: domain.JobQueued, Params: string(encoded)}
+	if input != nil {
+		if job.InputKey, err = jobInputKey(); err != nil {
+			return domain.Job{}, err
+		}
+		if err := s.blobs.Put(job.InputKey, input, "application/octet-stream"); err != nil {
+			return domain.Job{}, err
+		}
+	}
+	if job, err = s.repo.Create(job); err != nil {
+		return domain.Job{}, err
+	}
+	select {
+	case s.queued <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// jobInputKey names the blob of an upload before its job exists, so the job is never queued
+// without its input.
+func jobInputKey() (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return "jobs/inputs/" + hex.EncodeToString(random), nil
+}
+
+func (s *JobService) GetJob(id int) (domain.Job, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *JobService) CancelJob(id int) (domain.Job, error) {
+	job, err := s.repo.Update(id, func(job *domain.Job) error {
+		return job.Cancel(time.Now().UTC())
+	})
+	if err == nil && job.Finished() {
+		s.removeInput(job)
+	}
+	return job, err
+}
+
+func (s *JobService) OpenJobResult(job domain.Job) (io.ReadCloser, error) {
+	if !job.HasResult() {
+		return nil, domain.ErrNoJobResult
+	}
+	return &partsReader{blobs: s.blobs, job: job}, nil
+}
+
+// partsReader reads the result parts one after the other, opening each when it is reached.
+type partsReader struct {
+	blobs   domain.BlobStore
+	job     domain.Job
+	part    int
+	current io.ReadCloser
+}
+
+func (r *partsReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if r.part == r.job.Parts {
+				return 0, io.EOF
+			}
+			r.part++
+			current, err := r.blobs.Open(r.job.ResultKey(r.part))
+			if err != nil {
+				return 0, err
+			}
+			r.current = current
+		}
+		n, err := r.current.Read(p)
+		if errors.Is(err, io.EOF) {
+			err = r.current.Close()
+			r.current = nil
+			if n > 0 || err != nil {
+				return n, err
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *partsReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	return r.current.Close()
+}
+
+// claim takes the oldest job that waits for a worker, ok is false when there is none. A job
+// claimed concurrently by another replica is skipped.
+func (s *JobService) claim(holder string, now time.Time, ttl time.Duration) (job domain.Job, ok bool, err error) {
+	candidates, err := s.repo.GetClaimable(now, jobClaimCandidates)
+	if err != nil {
+		return domain.Job{}, false, err
+	}
+	for _, candidate := range candidates {
+		job, err := s.repo.Update(int(candidate.ID), func(job *domain.Job) error {
+			return job.Claim(holder, now, ttl)
+		})
+		if errors.Is(err, domain.ErrJobNotClaimable) {
+			continue
+		}
+		if err != nil {
+			return domain.Job{}, false, err
+		}
+		if job.Status != domain.JobRunning {
+			// Failed for having been interrupted too often
+			s.removeInput(job)
+			continue
+		}
+		return job, true, nil
+	}
+	return domain.Job{}, false, nil
+}
+
+// removeInput deletes the upload of a finished job, a leftover blob is harmless.
+func (s *JobService) removeInput(job domain.Job) {
+	if job.InputKey != "" {
+		s.blobs.Delete(job.InputKey)
+	}
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/pkg/logger"
+)
+
+const (
+	// Claimable jobs looked at per claim, more than one in case another replica is faster.
+	jobClaimCandidates = 10
+	// How long a claimed job stays with its worker without a renewal. Workers renew three
+	// times per period, a job of a crashed worker is resumed elsewhere once it expired.
+	jobLeaseTTL = time.Minute
+)
+
+// JobRunner runs queued jobs on a bounded pool of workers. Every replica runs one, jobs are
+// claimed in the database so each runs on one worker at a time. On shutdown running jobs are
+// put back in the queue and resumed by the next runner.
+type JobRunner struct {
+	jobs     *JobService
+	logger   logger.Logger
+	holder   string
+	workers  int
+	interval time.Duration
+}
+
+func NewJobRunner(jobs *JobService, logger logger.Logger, workers int, interval time.Duration) *JobRunner {
+	hostname, _ := os.Hostname()
+	return &JobRunner{
+		jobs:     jobs,
+		logger:   logger,
+		holder:   fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano()),
+		workers:  max(workers, 1),
+		interval: interval,
+	}
+}
+
+// Run claims jobs while workers are free, whenever a job is queued or a worker finishes and
+// every interval. Once ctx is done it waits for the running jobs to be put back.
+func (r *JobRunner) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	finished := make(chan struct{}, r.workers)
+	running := 0
+	for {
+		for running < r.workers && ctx.Err() == nil {
+			job, ok, err := r.jobs.claim(r.holder, time.Now().UTC(), jobLeaseTTL)
+			if err != nil {
+				r.logger.Warn(fmt.Sprintf("jobs: claim: %s", err))
+			}
+			if !ok {
+				break
+			}
+			running++
+			go func() {
+				r.run(ctx, job)
+				finished <- struct{}{}
+			}()
+		}
+		select {
+		case <-ctx.Done():
+			for ; running > 0; running-- {
+				<-finished
+			}
+			return
+		case <-finished:
+			running--
+		case <-r.jobs.queued:
+		case <-ticker.C:
+		}
+	}
+}
+
+// run executes a claimed job, renewing its lease in the background until the executor returns.
+func (r *JobRunner) run(ctx context.Context, job domain.Job) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	id := int(job.ID)
+	progress := func(update func(job *domain.Job)) (domain.Job, error) {
+		job, err := r.jobs.repo.Update(id, func(job *domain.Job) error {
+			if err := job.Renew(r.holder, time.Now().UTC(), jobLeaseTTL); err != nil {
+				return err
+			}
+			if update != nil {
+				update(job)
+			}
+			return nil
+		})
+		if err != nil || job.CancelRequested {
+			cancel()
+		}
+		return job, err
+	}
+
+	heartbeat := make(chan struct{})
+	go func() {
+		defer close(heartbeat)
+		ticker := time.NewTicker(jobLeaseTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-jobCtx.Done():
+				return
+			case <-ticker.C:
+				if _, err := progress(nil); err != nil {
+					r.logger.Warn(fmt.Sprintf("jobs: job %d: renew lease: %s", id, err))
+				}
+			}
+		}
+	}()
+	err := r.execute(jobCtx, job, progress)
+	cancel()
+	<-heartbeat
+
+	if err != nil && ctx.Err() != nil {
+		_, err = r.jobs.repo.Update(id, func(job *domain.Job) error { return job.Requeue(r.holder) })
+		if err != nil {
+			r.logger.Warn(fmt.Sprintf("jobs: job %d: requeue: %s", id, err))
+		}
+		return
+	}
+	finished, updateErr := r.jobs.repo.Update(id, func(job *domain.Job) error {
+		return job.Finish(r.holder, err, time.Now().UTC())
+	})
+	if updateErr != nil {
+		r.logger.Warn(fmt.Sprintf("jobs: job %d: finish: %s", id, updateErr))
+		return
+	}
+	if finished.Status == domain.JobFailed {
+		r.logger.Error(fmt.Sprintf("jobs: %s job %d failed: %s", job.Kind, id, err))
+	}
+	r.jobs.removeInput(finished)
+}
+
+// execute runs the executor of the job's kind, a panic fails the job instead of the process.
+func (r *JobRunner) execute(ctx context.Context, job domain.Job, progress domain.JobProgress) (err error) {
+	executor, ok := r.jobs.executors[job.Kind]
+	if !ok {
+		return fmt.Errorf("%w: unknown kind %q", domain.ErrInvalidJob, job.Kind)
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+	return executor.Execute(ctx, job, progress)
+}
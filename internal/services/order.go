@@ -0,0 +1,74 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/repositories"
+)
+
+// Order service, places orders against the current album catalog and drives their lifecycle.
+// Items are charged the effective price the catalog shows, promotions included.
+type OrderService struct {
+	repo    repositories.OrderRepository
+	albums  repositories.AlbumRepository
+	pricing domain.PricingService
+}
+
+func NewOrderService(repo repositories.OrderRepository, albums repositories.AlbumRepository, pricing domain.PricingService) *OrderService {
+	return &OrderService{repo: repo, albums: albums, pricing: pricing}
+}
+
+func (s *OrderService) GetAllOrders() ([]domain.Order, error) {
+	return s.repo.GetAll()
+}
+
+func (s *OrderService) GetOrderByID(id int) (domain.Order, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *OrderService) PlaceOrder(customerEmail string, items []domain.OrderItem) (domain.Order, error) {
+	albums := map[uint]domain.Album{}
+	var found []domain.Album
+	for _, item := range items {
+		if _, ok := albums[item.AlbumID]; ok {
+			continue
+		}
+		album, err := s.albums.GetByID(int(item.AlbumID))
+		if errors.Is(err, domain.ErrNotFound) {
+			// Unknown albums are reported by NewOrder
+			continue
+		}
+		if err != nil {
+			return domain.Order{}, err
+		}
+		albums[item.AlbumID] = album
+		found = append(found, album)
+	}
+	quotes, err := s.pricing.QuoteAlbums(found, domain.PriceRequest{Quantity: 1})
+	if err != nil {
+		return domain.Order{}, err
+	}
+	prices := make(map[uint]float64, len(quotes))
+	for _, quote := range quotes {
+		prices[quote.AlbumID] = quote.EffectivePrice
+	}
+	order, err := domain.NewOrder(customerEmail, items, albums, prices)
+	if err != nil {
+		return domain.Order{}, err
+	}
+	return s.repo.Place(order)
+}
+
+func (s *OrderService) ShipOrder(id int) (domain.Order, error) {
+	return s.repo.Update(id, func(order *domain.Order) error {
+		return order.MarkShipped(time.Now().UTC())
+	})
+}
+
+func (s *OrderService) CancelOrder(id int) (domain.Order, error) {
+	return s.repo.Update(id, func(order *domain.Order) error {
+		return order.Cancel(time.Now().UTC())
+	})
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOrderRepository is a mock implementation of the OrderRepository interface (contained in the repositories package).
+// Update applies the change to the order the mock returns, as the repository does to the stored one.
+type MockOrderRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrderRepository) GetAll() ([]domain.Order, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetByID(id int) (domain.Order, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) Place(order domain.Order) (domain.Order, error) {
+	args := m.Called(order)
+	return args.Get(0).(domain.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) Update(id int, change func(order *domain.Order) error) (domain.Order, error) {
+	args := m.Called(id)
+	order := args.Get(0).(domain.Order)
+	if err := args.Error(1); err != nil {
+		return domain.Order{}, err
+	}
+	if err := change(&order); err != nil {
+		return domain.Order{}, err
+	}
+	return order, nil
+}
+
+// MockPricingService is a mock implementation of the PricingService interface (contained in the Promotion domain)
+type MockPricingService struct {
+	mock.Mock
+}
+
+func (m *MockPricingService) QuoteAlbums(albums []domain.Album, request domain.PriceRequest) ([]domain.PriceQuote, error) {
+	args := m.Called(albums, request)
+	return args.Get(0).([]domain.PriceQuote), args.Error(1)
+}
+
+func TestOrderService(t *testing.T) {
+	published := domain.Album{ID: 1, Title: "Abbey Road", Price: 19.99, Format: domain.FormatVinyl, Status: domain.AlbumPublished}
+
+	t.Run("PlaceOrder :: prices items from the catalog", func(t *testing.T) {
+		orders, albums, pricing := new(MockOrderRepository), new(MockAlbumRepository), new(MockPricingService)
+		albums.On("GetByID", 1).Return(published, nil).Once()
+		pricing.On("QuoteAlbums", []domain.Album{published}, domain.PriceRequest{Quantity: 1}).
+			Return([]domain.PriceQuote{{AlbumID: 1, Quantity: 1, ListPrice: 19.99, EffectivePrice: 14.99}}, nil).Once()
+		var placed domain.Order
+		orders.On("Place", mock.Anything).Run(func(args mock.Arguments) {
+			placed = args.Get(0).(domain.Order)
+		}).Return(domain.Order{ID: 5}, nil).Once()
+		service := NewOrderService(orders, albums, pricing)
+
+		order, err := service.PlaceOrder("buyer@example.com", []domain.OrderItem{{AlbumID: 1, Quantity: 1}, {AlbumID: 1, Quantity: 2}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, uint(5), order.ID)
+		assert.Equal(t, domain.OrderCreated, placed.Status)
+		assert.Equal(t, 14.99, placed.Items[0].UnitPrice)
+		assert.Equal(t, 44.97, placed.Total)
+		assert.True(t, placed.Items[0].RequiresStock)
+		albums.AssertExpectations(t)
+		pricing.AssertExpectations(t)
+		orders.AssertExpectations(t)
+	})
+
+	t.Run("PlaceOrder :: rejects unknown albums", func(t *testing.T) {
+		orders, albums := new(MockOrderRepository), new(MockAlbumRepository)
+		albums.On("GetByID", 2).Return(domain.Album{}, domain.ErrNotFound)
+		pricing := new(MockPricingService)
+		pricing.On("QuoteAlbums", mock.Anything, mock.Anything).Return([]domain.PriceQuote{}, nil)
+		service := NewOrderService(orders, albums, pricing)
+
+		_, err := service.PlaceOrder("buyer@example.com", []domain.OrderItem{{AlbumID: 2, Quantity: 1}})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidOrder)
+		orders.AssertNotCalled(t, "Place", mock.Anything)
+	})
+
+	t.Run("PlaceOrder :: passes on failures to load albums", func(t *testing.T) {
+		orders, albums := new(MockOrderRepository), new(MockAlbumRepository)
+		failure := errors.New("connection refused")
+		albums.On("GetByID", 1).Return(domain.Album{}, failure)
+		service := NewOrderService(orders, albums, new(MockPricingService))
+
+		_, err := service.PlaceOrder("buyer@example.com", []domain.OrderItem{{AlbumID: 1, Quantity: 1}})
+
+		assert.ErrorIs(t, err, failure)
+		assert.NotErrorIs(t, err, domain.ErrInvalidOrder)
+		orders.AssertNotCalled(t, "Place", mock.Anything)
+	})
+
+	t.Run("ShipOrder and CancelOrder :: follow the order lifecycle", func(t *testing.T) {
+		orders := new(MockOrderRepository)
+		orders.On("Update", 1).Return(domain.Order{ID: 1, Status: domain.OrderCreated}, nil)
+		orders.On("Update", 2).Return(domain.Order{ID: 2, Status: domain.OrderPaid}, nil)
+		orders.On("Update", 3).Return(domain.Order{ID: 3, Status: domain.OrderShipped}, nil)
+		orders.On("Update", 4).Return(domain.Order{}, domain.ErrNotFound)
+		service := NewOrderService(orders, new(MockAlbumRepository), new(MockPricingService))
+
+		shipped, err := service.ShipOrder(2)
+		assert.Nil(t, err)
+		assert.Equal(t, domain.OrderShipped, shipped.Status)
+
+		_, err = service.CancelOrder(3)
+		assert.ErrorIs(t, err, domain.ErrInvalidOrderTransition)
+		_, err = service.ShipOrder(1)
+		assert.ErrorIs(t, err, domain.ErrInvalidOrderTransition)
+		_, err = service.CancelOrder(4)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/repositories"
+)
+
+// Payment service, charges orders through the configured payment gateway.
+// Orders become paid once their payment is captured, either directly or through a gateway webhook.
+type PaymentService struct {
+	repo    repositories.PaymentRepository
+	orders  repositories.OrderRepository
+	gateway domain.PaymentGateway
+}
+
+func NewPaymentService(repo repositories.PaymentRepository, orders repositories.OrderRepository, gateway domain.PaymentGateway) *PaymentService {
+	return &PaymentService{repo: repo, orders: orders, gateway: gateway}
+}
+
+func (s *PaymentService) GetPaymentByID(id int) (domain.Payment, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *PaymentService) GetPaymentsByOrder(orderID int) ([]domain.Payment, error) {
+	return s.repo.GetByOrderID(orderID)
+}
+
+// AuthorizeOrder places a hold for the order total. Declined attempts are stored as failed payments,
+// an order holding a live authorization is not authorized again.
+func (s *PaymentService) AuthorizeOrder(orderID int, source string) (domain.Payment, error) {
+	order, err := s.orders.GetByID(orderID)
+	if err != nil {
+		return domain.Payment{}, err
+	}
+	if order.Status != domain.OrderCreated {
+		return domain.Payment{}, fmt.Errorf("%w: %s order cannot be paid", domain.ErrInvalidPaymentState, order.Status)
+	}
+	payments, err := s.repo.GetByOrderID(orderID)
+	if err != nil {
+		return domain.Payment{}, err
+	}
+	for _, payment := range payments {
+		if payment.Live() {
+			return domain.Payment{}, fmt.Errorf("%w: order already has the %s payment %d", domain.ErrInvalidPaymentState, payment.Status, payment.ID)
+		}
+	}
+
+	result, authErr := s.gateway.Authorize(domain.PaymentAuthorization{
+		Reference: fmt.Sprintf("order-%d", order.ID),
+		Amount:    order.Total,
+		Currency:  order.Currency,
+		Source:    source,
+	})
+	if authErr != nil && !errors.Is(authErr, domain.ErrPaymentDeclined) {
+		return domain.Payment{}, authErr
+	}
+	payment, err := s.repo.Create(domain.Payment{
+		OrderID:       order.ID,
+		Gateway:       s.gateway.Name(),
+		GatewayRef:    result.GatewayRef,
+		Amount:        order.Total,
+		Currency:      order.Currency,
+		Status:        result.Status,
+		FailureReason: result.Reason,
+	})
+	if err != nil {
+		return domain.Payment{}, err
+	}
+	return payment, authErr
+}
+
+// CapturePayment collects an authorized payment and marks its order as paid. The payment is claimed
+// on the locked row before the gateway is called, so concurrent captures never both reach it.
+func (s *PaymentService) CapturePayment(id int) (domain.Payment, error) {
+	payment, err := s.repo.Update(id, func(payment *domain.Payment) error {
+		return payment.ClaimCapture()
+	})
+	if err != nil {
+		return payment, err
+	}
+	result, captureErr := s.gateway.Capture(payment.GatewayRef, payment.Amount, payment.Currency)
+	if captureErr != nil && !errors.Is(captureErr, domain.ErrPaymentDeclined) {
+		return payment, s.release(id, captureErr)
+	}
+	payment, err = s.repo.Update(id, func(payment *domain.Payment) error {
+		if payment.Status == result.Status {
+			// A webhook reported the outcome first
+			return nil
+		}
+		return payment.Transition(result.Status, result.Reason)
+	})
+	if err != nil {
+		return payment, err
+	}
+	if captureErr != nil {
+		return payment, captureErr
+	}
+	return payment, s.markOrderPaid(payment)
+}
+
+// RefundPayment returns amount of a captured payment, a zero amount refunds whatever is left.
+// Refunding the whole payment cancels its order unless it shipped. Like captures, the refund is
+// claimed on the locked payment before the gateway is called.
+func (s *PaymentService) RefundPayment(id int, amount float64) (domain.Payment, error) {
+	payment, err := s.repo.Update(id, func(payment *domain.Payment) error {
+		return payment.ClaimRefund(amount)
+	})
+	if err != nil {
+		return payment, err
+	}
+	if _, err := s.gateway.Refund(payment.GatewayRef, payment.Refunding, payment.Currency); err != nil {
+		return payment, s.release(id, err)
+	}
+	payment, err = s.repo.Update(id, func(payment *domain.Payment) error {
+		payment.CompleteRefund()
+		return nil
+	})
+	if err != nil || payment.Status != domain.PaymentRefunded {
+		return payment, err
+	}
+	return payment, s.cancelRefundedOrder(payment)
+}
+
+// HandleWebhook verifies a gateway notification and applies the reported status. Replayed,
+// late and out of order notifications leave the payment unchanged.
+func (s *PaymentService) HandleWebhook(payload []byte, signature string) (domain.Payment, error) {
+	event, err := s.gateway.VerifyWebhook(payload, signature)
+	if err != nil {
+		return domain.Payment{}, err
+	}
+	payment, err := s.repo.GetByGatewayRef(s.gateway.Name(), event.GatewayRef)
+	if err != nil {
+		return domain.Payment{}, err
+	}
+	if !payment.CanBecome(event.Status) {
+		return payment, nil
+	}
+	// Checked again on the locked payment, a concurrent change may have moved it on
+	payment, err = s.repo.Update(int(payment.ID), func(payment *domain.Payment) error {
+		return payment.Transition(event.Status, "")
+	})
+	if err != nil {
+		return payment, err
+	}
+	switch payment.Status {
+	case domain.PaymentCaptured:
+		return payment, s.markOrderPaid(payment)
+	case domain.PaymentRefunded:
+		return payment, s.cancelRefundedOrder(payment)
+	}
+	return payment, nil
+}
+
+// release gives up the claim on a payment after the gateway call failed with cause.
+func (s *PaymentService) release(id int, cause error) error {
+	_, err := s.repo.Update(id, func(payment *domain.Payment) error {
+		payment.Release()
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%w, releasing payment %d failed: %v", cause, id, err)
+	}
+	return cause
+}
+
+func (s *PaymentService) markOrderPaid(payment domain.Payment) error {
+	_, err := s.orders.Update(int(payment.OrderID), func(order *domain.Order) error {
+		if order.Status != domain.OrderCreated {
+			return nil
+		}
+		return order.MarkPaid(time.Now().UTC())
+	})
+	return err
+}
+
+// cancelRefundedOrder cancels the order of a fully refunded payment, shipped orders stay shipped.
+func (s *PaymentService) cancelRefundedOrder(payment domain.Payment) error {
+	_, err := s.orders.Update(int(payment.OrderID), func(order *domain.Order) error {
+		if order.Status != domain.OrderCreated && order.Status != domain.OrderPaid {
+			return nil
+		}
+		return order.Cancel(time.Now().UTC())
+	})
+	return err
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPaymentRepository is a mock implementation of the PaymentRepository interface (contained in the repositories package).
+// Update applies the change to the payment the mock returns, as the repository does to the stored one.
+type MockPaymentRepository struct {
+	mock.Mock
+}
+
+func (m *MockPaymentRepository) GetByID(id int) (domain.Payment, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) GetByOrderID(orderID int) ([]domain.Payment, error) {
+	args := m.Called(orderID)
+	return args.Get(0).([]domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) GetByGatewayRef(gateway, ref string) (domain.Payment, error) {
+	args := m.Called(gateway, ref)
+	return args.Get(0).(domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) Create(payment domain.Payment) (domain.Payment, error) {
+	args := m.Called(payment)
+	return args.Get(0).(domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) Update(id int, change func(payment *domain.Payment) error) (domain.Payment, error) {
+	args := m.Called(id)
+	payment := args.Get(0).(domain.Payment)
+	if err := change(&payment); err != nil {
+		return domain.Payment{}, err
+	}
+	return payment, args.Error(1)
+}
+
+// MockPaymentGateway is a mock implementation of the PaymentGateway interface (contained in the Payment domain)
+type MockPaymentGateway struct {
+	mock.Mock
+}
+
+func (m *MockPaymentGateway) Name() string {
+	return "mock"
+}
+
+func (m *MockPaymentGateway) Authorize(authorization domain.PaymentAuthorization) (domain.PaymentResult, error) {
+	args := m.Called(authorization)
+	return args.Get(0).(domain.PaymentResult), args.Error(1)
+}
+
+func (m *MockPaymentGateway) Capture(gatewayRef string, amount float64, currency string) (domain.PaymentResult, error) {
+	args := m.Called(gatewayRef, amount, currency)
+	return args.Get(0).(domain.PaymentResult), args.Error(1)
+}
+
+func (m *MockPaymentGateway) Refund(gatewayRef string, amount float64, currency string) (domain.PaymentResult, error) {
+	args := m.Called(gatewayRef, amount, currency)
+	return args.Get(0).(domain.PaymentResult), args.Error(1)
+}
+
+func (m *MockPaymentGateway) VerifyWebhook(payload []byte, signature string) (domain.PaymentEvent, error) {
+	args := m.Called(payload, signature)
+	return args.Get(0).(domain.PaymentEvent), args.Error(1)
+}
+
+func TestPaymentService(t *testing.T) {
+	authorized := domain.Payment{ID: 1, OrderID: 3, GatewayRef: "pi_1", Amount: 20, Currency: "USD", Status: domain.PaymentAuthorized}
+	capturing := authorized
+	capturing.Status = domain.PaymentCapturing
+	captured := authorized
+	captured.Status = domain.PaymentCaptured
+	setup := func() (*PaymentService, *MockPaymentRepository, *MockOrderRepository, *MockPaymentGateway) {
+		repo, orders, gateway := new(MockPaymentRepository), new(MockOrderRepository), new(MockPaymentGateway)
+		return NewPaymentService(repo, orders, gateway), repo, orders, gateway
+	}
+
+	t.Run("AuthorizeOrder :: refuses orders holding a live authorization", func(t *testing.T) {
+		service, repo, orders, gateway := setup()
+		orders.On("GetByID", 3).Return(domain.Order{ID: 3, Status: domain.OrderCreated, Total: 20, Currency: "USD"}, nil)
+		repo.On("GetByOrderID", 3).Return([]domain.Payment{{ID: 2, OrderID: 3, Status: domain.PaymentFailed}, authorized}, nil)
+
+		_, err := service.AuthorizeOrder(3, "4242424242424242")
+
+		assert.ErrorIs(t, err, domain.ErrInvalidPaymentState)
+		gateway.AssertNotCalled(t, "Authorize", mock.Anything)
+	})
+
+	t.Run("CapturePayment :: captures and marks the order paid", func(t *testing.T) {
+		service, repo, orders, gateway := setup()
+		repo.On("Update", 1).Return(authorized, nil).Once()
+		repo.On("Update", 1).Return(capturing, nil).Once()
+		gateway.On("Capture", "pi_1", 20.0, "USD").Return(domain.PaymentResult{GatewayRef: "pi_1", Status: domain.PaymentCaptured}, nil)
+		orders.On("Update", 3).Return(domain.Order{ID: 3, Status: domain.OrderCreated}, nil)
+
+		payment, err := service.CapturePayment(1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, domain.PaymentCaptured, payment.Status)
+		orders.AssertExpectations(t)
+	})
+
+	t.Run("CapturePayment :: refuses payments that are not authorized", func(t *testing.T) {
+		service, repo, _, gateway := setup()
+		repo.On("Update", 1).Return(capturing, nil)
+
+		_, err := service.CapturePayment(1)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidPaymentState)
+		gateway.AssertNotCalled(t, "Capture", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("CapturePayment :: releases the claim when the gateway fails", func(t *testing.T) {
+		service, repo, orders, gateway := setup()
+		var released domain.Payment
+		repo.On("Update", 1).Return(authorized, nil).Once()
+		repo.On("Update", 1).Return(capturing, nil).Run(func(args mock.Arguments) {
+			released = capturing
+			released.Release()
+		}).Once()
+		gateway.On("Capture", "pi_1", 20.0, "USD").Return(domain.PaymentResult{}, fmt.Errorf("%w: timeout", domain.ErrPaymentGateway))
+
+		_, err := service.CapturePayment(1)
+
+		assert.ErrorIs(t, err, domain.ErrPaymentGateway)
+		assert.Equal(t, domain.PaymentAuthorized, released.Status)
+		repo.AssertExpectations(t)
+		orders.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("RefundPayment :: refunds in part, then in full cancelling the order", func(t *testing.T) {
+		service, repo, orders, gateway := setup()
+		refunding := captured
+		refunding.Status, refunding.Refunding = domain.PaymentRefunding, 5
+		partly := captured
+		partly.Refunded = 5
+		refundingRest := partly
+		refundingRest.Status, refundingRest.Refunding = domain.PaymentRefunding, 15
+		repo.On("Update", 1).Return(captured, nil).Once()
+		repo.On("Update", 1).Return(refunding, nil).Once()
+		repo.On("Update", 1).Return(partly, nil).Once()
+		repo.On("Update", 1).Return(refundingRest, nil).Once()
+		gateway.On("Refund", "pi_1", 5.0, "USD").Return(domain.PaymentResult{}, nil).Once()
+		gateway.On("Refund", "pi_1", 15.0, "USD").Return(domain.PaymentResult{}, nil).Once()
+		orders.On("Update", 3).Return(domain.Order{ID: 3, Status: domain.OrderPaid}, nil).Once()
+
+		payment, err := service.RefundPayment(1, 5)
+		assert.Nil(t, err)
+		assert.Equal(t, domain.PaymentCaptured, payment.Status)
+		assert.Equal(t, 5.0, payment.Refunded)
+
+		payment, err = service.RefundPayment(1, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, domain.PaymentRefunded, payment.Status)
+		assert.Equal(t, 20.0, payment.Refunded)
+		gateway.AssertExpectations(t)
+		orders.AssertExpectations(t)
+	})
+
+	t.Run("RefundPayment :: refuses more than is left", func(t *testing.T) {
+		service, repo, _, gateway := setup()
+		repo.On("Update", 1).Return(captured, nil)
+
+		_, err := service.RefundPayment(1, 25)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidPaymentState)
+		gateway.AssertNotCalled(t, "Refund", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("HandleWebhook :: rejects invalid signatures", func(t *testing.T) {
+		service, repo, _, gateway := setup()
+		gateway.On("VerifyWebhook", []byte(`{}`), "t=1,v1=bad").Return(domain.PaymentEvent{}, domain.ErrInvalidWebhookSignature)
+
+		_, err := service.HandleWebhook([]byte(`{}`), "t=1,v1=bad")
+
+		assert.ErrorIs(t, err, domain.ErrInvalidWebhookSignature)
+		repo.AssertNotCalled(t, "GetByGatewayRef", mock.Anything, mock.Anything)
+	})
+
+	t.Run("HandleWebhook :: applies a capture once and ignores replays", func(t *testing.T) {
+		service, repo, orders, gateway := setup()
+		event := domain.PaymentEvent{ID: "evt_1", GatewayRef: "pi_1", Status: domain.PaymentCaptured}
+		gateway.On("VerifyWebhook", mock.Anything, mock.Anything).Return(event, nil)
+		repo.On("GetByGatewayRef", "mock", "pi_1").Return(authorized, nil).Once()
+		repo.On("Update", 1).Return(authorized, nil).Once()
+		repo.On("GetByGatewayRef", "mock", "pi_1").Return(captured, nil).Once()
+		orders.On("Update", 3).Return(domain.Order{ID: 3, Status: domain.OrderCreated}, nil).Once()
+
+		payment, err := service.HandleWebhook([]byte(`{}`), "signature")
+		assert.Nil(t, err)
+		assert.Equal(t, domain.PaymentCaptured, payment.Status)
+
+		payment, err = service.HandleWebhook([]byte(`{}`), "signature")
+		assert.Nil(t, err)
+		assert.Equal(t, domain.PaymentCaptured, payment.Status)
+		repo.AssertExpectations(t)
+		orders.AssertExpectations(t)
+	})
+
+	t.Run("HandleWebhook :: ignores late events of an earlier status", func(t *testing.T) {
+		service, repo, _, gateway := setup()
+		gateway.On("VerifyWebhook", mock.Anything, mock.Anything).Return(domain.PaymentEvent{GatewayRef: "pi_1", Status: domain.PaymentAuthorized}, nil)
+		repo.On("GetByGatewayRef", "mock", "pi_1").Return(captured, nil)
+
+		payment, err := service.HandleWebhook([]byte(`{}`), "signature")
+
+		assert.Nil(t, err)
+		assert.Equal(t, domain.PaymentCaptured, payment.Status)
+		repo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("HandleWebhook :: records refunds and cancels the order", func(t *testing.T) {
+		service, repo, orders, gateway := setup()
+		gateway.On("VerifyWebhook", mock.Anything, mock.Anything).Return(domain.PaymentEvent{GatewayRef: "pi_1", Status: domain.PaymentRefunded}, nil)
+		repo.On("GetByGatewayRef", "mock", "pi_1").Return(captured, nil)
+		repo.On("Update", 1).Return(captured, nil)
+		orders.On("Update", 3).Return(domain.Order{ID: 3, Status: domain.OrderPaid}, nil)
+
+		payment, err := service.HandleWebhook([]byte(`{}`), "signature")
+
+		assert.Nil(t, err)
+		assert.Equal(t, domain.PaymentRefunded, payment.Status)
+		assert.Equal(t, 20.0, payment.Refunded)
+		orders.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/repositories"
+)
+
+// Pricing service, quotes effective album prices from the promotions active at request time.
+type PricingService struct {
+	promotions repositories.PromotionRepository
+}
+
+func NewPricingService(promotions repositories.PromotionRepository) *PricingService {
+	return &PricingService{promotions: promotions}
+}
+
+// QuoteAlbums prices every album against one snapshot of the active promotions.
+func (s *PricingService) QuoteAlbums(albums []domain.Album, request domain.PriceRequest) ([]domain.PriceQuote, error) {
+	if request.At.IsZero() {
+		request.At = time.Now().UTC()
+	}
+	active, err := s.promotions.GetActive(request.At)
+	if err != nil {
+		return nil, err
+	}
+	quotes := make([]domain.PriceQuote, 0, len(albums))
+	for _, album := range albums {
+		quotes = append(quotes, domain.PriceAlbum(album, active, request))
+	}
+	return quotes, nil
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/repositories"
+)
+
+// Promotion service, manages the discount rules used by the pricing service.
+type PromotionService struct {
+	repo repositories.PromotionRepository
+}
+
+func NewPromotionService(repo repositories.PromotionRepository) *PromotionService {
+	return &PromotionService{repo: repo}
+}
+
+func (s *PromotionService) GetAllPromotions() ([]domain.Promotion, error) {
+	return s.repo.GetAll()
+}
+
+func (s *PromotionService) GetPromotionByID(id int) (domain.Promotion, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *PromotionService) CreatePromotion(promotion domain.Promotion) (domain.Promotion, error) {
+	if err := promotion.Validate(); err != nil {
+		return domain.Promotion{}, err
+	}
+	return s.repo.Create(promotion)
+}
+
+func (s *PromotionService) UpdatePromotion(promotion domain.Promotion) (domain.Promotion, error) {
+	if err := promotion.Validate(); err != nil {
+		return domain.Promotion{}, err
+	}
+	return s.repo.Update(promotion)
+}
+
+func (s *PromotionService) DeletePromotion(id int) error {
+	return s.repo.Delete(id)
+}
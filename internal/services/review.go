@@ -0,0 +1,83 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/repositories"
+)
+
+// Review service, the repository maintains the album rating aggregates.
+type ReviewService struct {
+	repo   repositories.ReviewRepository
+	albums repositories.AlbumRepository
+}
+
+func NewReviewService(repo repositories.ReviewRepository, albums repositories.AlbumRepository) *ReviewService {
+	return &ReviewService{repo: repo, albums: albums}
+}
+
+// GetAlbumReviews lists the reviews of a public album. Moderators, who see hidden reviews
+// (includeHidden), also see those of albums that are not public.
+func (s *ReviewService) GetAlbumReviews(albumID int, includeHidden bool) ([]domain.Review, error) {
+	album, err := s.albums.GetByID(albumID)
+	if err != nil {
+		return nil, err
+	}
+	if !includeHidden && !album.IsPublic() {
+		return nil, fmt.Errorf("%w: album %d is not published", domain.ErrNotFound, albumID)
+	}
+	return s.repo.GetByAlbum(albumID, includeHidden)
+}
+
+func (s *ReviewService) GetReview(albumID int, id int) (domain.Review, error) {
+	return s.repo.GetByID(albumID, id)
+}
+
+// CreateReview adds a visible review, only published albums can be reviewed. The review
+// returned carries the token its author edits and deletes it with.
+func (s *ReviewService) CreateReview(albumID int, review domain.Review) (domain.Review, error) {
+	review.Author = domain.NormalizeReviewAuthor(review.Author)
+	if err := review.Validate(); err != nil {
+		return domain.Review{}, err
+	}
+	album, err := s.albums.GetByID(albumID)
+	if err != nil {
+		return domain.Review{}, err
+	}
+	if !album.IsPublic() {
+		return domain.Review{}, fmt.Errorf("%w: album %d is not published", domain.ErrInvalidReview, albumID)
+	}
+	review.ID, review.AlbumID = 0, uint(albumID)
+	review.FlagCount, review.Hidden = 0, false
+	token, err := randomToken(24)
+	if err != nil {
+		return domain.Review{}, err
+	}
+	review.SetToken(token)
+	return s.repo.Create(review)
+}
+
+// UpdateReview changes rating and text, author and moderation state are kept.
+func (s *ReviewService) UpdateReview(albumID int, review domain.Review) (domain.Review, error) {
+	return s.repo.Update(albumID, int(review.ID), func(existing *domain.Review) error {
+		existing.Rating, existing.Text = review.Rating, review.Text
+		return existing.Validate()
+	})
+}
+
+func (s *ReviewService) DeleteReview(albumID int, id int) error {
+	return s.repo.Delete(albumID, id)
+}
+
+func (s *ReviewService) FlagReview(albumID int, id int, client string) (domain.Review, error) {
+	return s.repo.Flag(albumID, id, client)
+}
+
+// ModerateReview hides or restores a review, hidden reviews do not count towards the album rating.
+func (s *ReviewService) ModerateReview(albumID int, id int, hidden bool) (domain.Review, error) {
+	return s.repo.Update(albumID, id, func(review *domain.Review) error {
+		review.Hidden = hidden
+		return nil
+	})
+}
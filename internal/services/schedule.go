@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/repositories"
+)
+
+// How many due changes one ApplyDueChanges call processes at most.
+const scheduleBatchSize = 100
+
+// Scheduled change service, changes are applied through the album service so they are validated
+// and recorded in the price history like manual updates.
+type ScheduleService struct {
+	repo   repositories.ScheduleRepository
+	albums domain.AlbumService
+}
+
+func NewScheduleService(repo repositories.ScheduleRepository, albums domain.AlbumService) *ScheduleService {
+	return &ScheduleService{repo: repo, albums: albums}
+}
+
+func (s *ScheduleService) ScheduleChange(albumID int, change domain.ScheduledChange) (domain.ScheduledChange, error) {
+	if err := change.Validate(time.Now().UTC()); err != nil {
+		return domain.ScheduledChange{}, err
+	}
+	if _, err := s.albums.GetAlbumByID(albumID); err != nil {
+		return domain.ScheduledChange{}, err
+	}
+	change.ID, change.AlbumID = 0, uint(albumID)
+	change.Status, change.Error = domain.SchedulePending, ""
+	change.AppliedAt, change.CancelledAt = nil, nil
+	return s.repo.Create(change)
+}
+
+func (s *ScheduleService) GetSchedules(filter domain.ScheduleFilter) ([]domain.ScheduledChange, error) {
+	return s.repo.GetAll(filter)
+}
+
+func (s *ScheduleService) GetScheduleByID(id int) (domain.ScheduledChange, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *ScheduleService) CancelSchedule(id int) (domain.ScheduledChange, error) {
+	return s.repo.Update(id, func(change *domain.ScheduledChange) error {
+		return change.Cancel(time.Now().UTC())
+	})
+}
+
+func (s *ScheduleService) apply(change domain.ScheduledChange) error {
+	id := int(change.AlbumID)
+	var err error
+	switch change.Action {
+	case domain.SchedulePublish:
+		_, err = s.albums.PublishAlbum(id)
+	case domain.ScheduleUnpublish:
+		_, err = s.albums.ArchiveAlbum(id)
+	default:
+		var album domain.Album
+		if album, err = s.albums.GetAlbumByID(id); err == nil {
+			change.ApplyTo(&album)
+			_, err = s.albums.UpdateAlbum(album)
+		}
+	}
+	return err
+}
+
+// ApplyDueChanges applies each due change while holding its row lock, so a change cancelled or
+// applied concurrently is skipped. A change the album service rejects is marked failed.
+func (s *ScheduleService) ApplyDueChanges(now time.Time) (int, error) {
+	due, err := s.repo.GetDue(now, scheduleBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	processed := 0
+	for _, pending := range due {
+		_, err := s.repo.Update(int(pending.ID), func(change *domain.ScheduledChange) error {
+			if change.Status != domain.SchedulePending {
+				return domain.ErrScheduleNotPending
+			}
+			return change.Finish(s.apply(*change), now)
+		})
+		if errors.Is(err, domain.ErrScheduleNotPending) {
+			continue
+		}
+		if err != nil {
+			return processed, err
+		}
+		processed++
+	}
+	return processed, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/repositories"
+	"github.com/ssitko/hex-domain/pkg/logger"
+)
+
+// Lease taken by the replica that applies scheduled changes.
+const schedulerLease = "scheduler"
+
+// Scheduler applies due scheduled changes in the background. Every replica runs one, the lease
+// makes sure only one of them is active at a time.
+type Scheduler struct {
+	schedules domain.ScheduleService
+	leases    repositories.LeaseRepository
+	logger    logger.Logger
+	holder    string
+	interval  time.Duration
+}
+
+func NewScheduler(schedules domain.ScheduleService, leases repositories.LeaseRepository, logger logger.Logger, interval time.Duration) *Scheduler {
+	hostname, _ := os.Hostname()
+	return &Scheduler{
+		schedules: schedules,
+		leases:    leases,
+		logger:    logger,
+		holder:    fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano()),
+		interval:  interval,
+	}
+}
+
+// Run polls every interval until ctx is done, then gives up the lease.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.tick(time.Now().UTC())
+		select {
+		case <-ctx.Done():
+			if err := s.leases.Release(schedulerLease, s.holder); err != nil {
+				s.logger.Warn(fmt.Sprintf("scheduler: release lease: %s", err))
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) tick(now time.Time) {
+	// The lease outlives a few missed ticks so a slow run does not hand over leadership
+	leader, err := s.leases.Acquire(schedulerLease, s.holder, now, 3*s.interval)
+	if err != nil {
+		s.logger.Warn(fmt.Sprintf("scheduler: acquire lease: %s", err))
+		return
+	}
+	if !leader {
+		return
+	}
+	applied, err := s.schedules.ApplyDueChanges(now)
+	if applied > 0 {
+		s.logger.Info(fmt.Sprintf("scheduler: processed %d scheduled changes", applied))
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("scheduler: apply due changes: %s", err))
+	}
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/repositories"
+)
+
+// Tag service, names are stored lower cased.
+type TagService struct {
+	repo repositories.TagRepository
+}
+
+func NewTagService(repo repositories.TagRepository) *TagService {
+	return &TagService{repo: repo}
+}
+
+func (s *TagService) GetAllTags() ([]domain.Tag, error) {
+	return s.repo.GetAll()
+}
+
+func (s *TagService) GetTagByID(id int) (domain.Tag, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *TagService) CreateTag(tag domain.Tag) (domain.Tag, error) {
+	tag.Name = domain.NormalizeTagName(tag.Name)
+	return s.repo.Create(tag)
+}
+
+func (s *TagService) UpdateTag(tag domain.Tag) (domain.Tag, error) {
+	tag.Name = domain.NormalizeTagName(tag.Name)
+	return s.repo.Update(tag)
+}
+
+func (s *TagService) DeleteTag(id int) error {
+	return s.repo.Delete(id)
+}
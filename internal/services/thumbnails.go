@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/repositories"
+	"github.com/ssitko/hex-domain/pkg/logger"
+)
+
+// Albums with stale thumbnails picked up per sweep.
+const thumbnailSweepSize = 100
+
+// ThumbnailWorker generates cover thumbnails in the background. Uploads are handled as they
+// arrive, a periodic sweep catches covers missed because of a full queue, an error or a restart.
+// Covers that fail are retried with backoff and given up after MaxThumbnailAttempts.
+type ThumbnailWorker struct {
+	covers   *CoverService
+	albums   repositories.AlbumRepository
+	logger   logger.Logger
+	interval time.Duration
+}
+
+func NewThumbnailWorker(covers *CoverService, albums repositories.AlbumRepository, logger logger.Logger, interval time.Duration) *ThumbnailWorker {
+	return &ThumbnailWorker{covers: covers, albums: albums, logger: logger, interval: interval}
+}
+
+// Run processes uploads and sweeps every interval until ctx is done.
+func (w *ThumbnailWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	w.sweep()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case albumID := <-w.covers.queue:
+			w.generate(albumID)
+		case <-ticker.C:
+			w.sweep()
+		}
+	}
+}
+
+func (w *ThumbnailWorker) sweep() {
+	albums, err := w.albums.GetStaleThumbnails(time.Now().UTC(), thumbnailSweepSize)
+	if err != nil {
+		w.logger.Warn(fmt.Sprintf("thumbnails: list stale covers: %s", err))
+		return
+	}
+	for _, album := range albums {
+		w.generate(int(album.ID))
+	}
+}
+
+func (w *ThumbnailWorker) generate(albumID int) {
+	if err := w.covers.GenerateThumbnails(albumID); err != nil {
+		w.logger.Error(fmt.Sprintf("thumbnails: album %d: %s", albumID, err))
+	}
+}
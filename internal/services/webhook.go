@@ -0,0 +1,251 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ssitko/hex-domain/internal/domain"
+	"github.com/ssitko/hex-domain/internal/repositories"
+	"github.com/ssitko/hex-domain/pkg/logger"
+)
+
+const (
+	// Deliveries listed per webhook, newest first.
+	webhookDeliveryLog = 100
+	// How long a claimed delivery is left to its sender before it is sent again.
+	webhookClaimTimeout = time.Minute
+)
+
+// Webhook service, it turns album changes into deliveries stored in the database, sent and
+// retried by a WebhookWorker.
+type WebhookService struct {
+	repo   repositories.WebhookRepository
+	sender domain.WebhookSender
+	logger logger.Logger
+	// Wakes the worker when deliveries are queued, instead of waiting for its next poll
+	pending chan struct{}
+}
+
+func NewWebhookService(repo repositories.WebhookRepository, sender domain.WebhookSender, logger logger.Logger) *WebhookService {
+	return &WebhookService{repo: repo, sender: sender, logger: logger, pending: make(chan struct{}, 1)}
+}
+
+// Body of a delivery. Album events carry the album after the change, or as it was before it
+// was deleted, pings the ID of the webhook.
+type webhookPayload struct {
+	ID        string        `json:"id"`
+	Type      string        `json:"type"`
+	At        time.Time     `json:"at"`
+	WebhookID uint          `json:"webhook_id,omitempty"`
+	AlbumID   uint          `json:"album_id,omitempty"`
+	ArtistID  uint          `json:"artist_id,omitempty"`
+	Album     *domain.Album `json:"album,omitempty"`
+}
+
+func (s *WebhookService) GetAllWebhooks() ([]domain.Webhook, error) {
+	return s.repo.GetAll()
+}
+
+func (s *WebhookService) GetWebhookByID(id int) (domain.Webhook, error) {
+	return s.repo.GetByID(id)
+}
+
+// CreateWebhook registers the webhook, generating its secret unless one is given.
+func (s *WebhookService) CreateWebhook(webhook domain.Webhook) (domain.Webhook, error) {
+	if webhook.Secret == "" {
+		secret, err := randomToken(24)
+		if err != nil {
+			return domain.Webhook{}, err
+		}
+		webhook.Secret = "whsec_" + secret
+	}
+	if err := webhook.Validate(); err != nil {
+		return domain.Webhook{}, err
+	}
+	return s.repo.Create(webhook)
+}
+
+// UpdateWebhook replaces the URL and events of the webhook, its secret is kept unless a new
+// one is given.
+func (s *WebhookService) UpdateWebhook(webhook domain.Webhook) (domain.Webhook, error) {
+	stored, err := s.repo.GetByID(int(webhook.ID))
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	if webhook.Secret == "" {
+		webhook.Secret = stored.Secret
+	}
+	webhook.CreatedAt = stored.CreatedAt
+	if err := webhook.Validate(); err != nil {
+		return domain.Webhook{}, err
+	}
+	return s.repo.Update(webhook)
+}
+
+func (s *WebhookService) DeleteWebhook(id int) error {
+	return s.repo.Delete(id)
+}
+
+func (s *WebhookService) GetWebhookDeliveries(id int) ([]domain.WebhookDelivery, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetDeliveries(id, webhookDeliveryLog)
+}
+
+// RedeliverWebhook queues a dead delivery of the webhook again.
+func (s *WebhookService) RedeliverWebhook(id int, deliveryID int) (domain.WebhookDelivery, error) {
+	delivery, err := s.repo.UpdateDelivery(deliveryID, func(delivery *domain.WebhookDelivery) error {
+		if delivery.WebhookID != uint(id) {
+			return fmt.Errorf("webhook delivery %w", domain.ErrNotFound)
+		}
+		return delivery.Redeliver(time.Now().UTC())
+	})
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	s.wake()
+	return delivery, nil
+}
+
+// TestWebhook sends a ping right away, it is recorded in the delivery log but not retried.
+func (s *WebhookService) TestWebhook(id int) (domain.WebhookDelivery, error) {
+	webhook, err := s.repo.GetByID(id)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	now := time.Now().UTC()
+	deliveries, err := s.newDeliveries([]domain.Webhook{webhook}, webhookPayload{Type: domain.WebhookPing, WebhookID: webhook.ID}, now)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	// Claimed before it is stored, so the worker leaves it to this call
+	ping := deliveries[0]
+	if err := ping.Claim(now, webhookClaimTimeout); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	created, err := s.repo.CreateDeliveries([]domain.WebhookDelivery{ping})
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	return s.send(webhook, created[0])
+}
+
+// Publish queues a delivery of the album change to every webhook subscribed to eventType. The
+// change is already saved, a failure to queue it is logged.
+func (s *WebhookService) Publish(eventType domain.AlbumEventType, album domain.Album) {
+	webhooks, err := s.repo.GetAll()
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("webhooks: album %d %s: %s", album.ID, eventType, err))
+		return
+	}
+	var subscribed []domain.Webhook
+	for _, webhook := range webhooks {
+		if webhook.Subscribes(eventType) {
+			subscribed = append(subscribed, webhook)
+		}
+	}
+	if len(subscribed) == 0 {
+		return
+	}
+	payload := webhookPayload{Type: string(eventType), AlbumID: album.ID, ArtistID: album.ArtistID, Album: &album}
+	deliveries, err := s.newDeliveries(subscribed, payload, time.Now().UTC())
+	if err == nil {
+		_, err = s.repo.CreateDeliveries(deliveries)
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("webhooks: album %d %s: %s", album.ID, eventType, err))
+		return
+	}
+	s.wake()
+}
+
+// newDeliveries returns a pending delivery of the payload to each webhook, all with the same
+// event ID so receivers can tell redeliveries apart.
+func (s *WebhookService) newDeliveries(webhooks []domain.Webhook, payload webhookPayload, now time.Time) ([]domain.WebhookDelivery, error) {
+	var err error
+	if payload.ID, err = randomToken(16); err != nil {
+		return nil, err
+	}
+	payload.At = now
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make([]domain.WebhookDelivery, len(webhooks))
+	for i, webhook := range webhooks {
+		deliveries[i] = domain.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       payload.ID,
+			EventType:     payload.Type,
+			Payload:       string(encoded),
+			Status:        domain.DeliveryPending,
+			NextAttemptAt: &now,
+		}
+	}
+	return deliveries, nil
+}
+
+// deliverDue sends up to limit due deliveries, returning how many were due. Deliveries
+// claimed by another worker in the meantime are skipped.
+func (s *WebhookService) deliverDue(limit int) (int, error) {
+	now := time.Now().UTC()
+	due, err := s.repo.GetDueDeliveries(now, limit)
+	if err != nil {
+		return 0, err
+	}
+	webhooks := map[uint]domain.Webhook{}
+	for _, delivery := range due {
+		claimed, err := s.repo.UpdateDelivery(int(delivery.ID), func(delivery *domain.WebhookDelivery) error {
+			return delivery.Claim(now, webhookClaimTimeout)
+		})
+		if errors.Is(err, domain.ErrDeliveryNotDue) || errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return len(due), err
+		}
+		webhook, ok := webhooks[claimed.WebhookID]
+		if !ok {
+			if webhook, err = s.repo.GetByID(int(claimed.WebhookID)); errors.Is(err, domain.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return len(due), err
+			}
+			webhooks[claimed.WebhookID] = webhook
+		}
+		if _, err := s.send(webhook, claimed); err != nil {
+			return len(due), err
+		}
+	}
+	return len(due), nil
+}
+
+// send makes the attempt claimed for the delivery and records its outcome.
+func (s *WebhookService) send(webhook domain.Webhook, delivery domain.WebhookDelivery) (domain.WebhookDelivery, error) {
+	status, sendErr := s.sender.Send(webhook, delivery)
+	return s.repo.UpdateDelivery(int(delivery.ID), func(delivery *domain.WebhookDelivery) error {
+		delivery.Record(status, sendErr, time.Now().UTC())
+		return nil
+	})
+}
+
+func (s *WebhookService) wake() {
+	select {
+	case s.pending <- struct{}{}:
+	default:
+	}
+}
+
+func randomToken(size int) (string, error) {
+	random := make([]byte, size)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(random), nil
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ssitko/hex-domain/pkg/logger"
+)
+
+// Due deliveries picked up per query.
+const webhookBatchSize = 50
+
+// WebhookWorker sends due webhook deliveries in the background: new ones as they are queued,
+// retries when their backoff passed. Every replica runs one, a delivery is claimed in the
+// database before it is sent.
+type WebhookWorker struct {
+	webhooks *WebhookService
+	logger   logger.Logger
+	interval time.Duration
+}
+
+func NewWebhookWorker(webhooks *WebhookService, logger logger.Logger, interval time.Duration) *WebhookWorker {
+	return &WebhookWorker{webhooks: webhooks, logger: logger, interval: interval}
+}
+
+// Run sends deliveries as they are queued and looks for due ones every interval until ctx is done.
+func (w *WebhookWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	w.deliver(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.webhooks.pending:
+			w.deliver(ctx)
+		case <-ticker.C:
+			w.deliver(ctx)
+		}
+	}
+}
+
+// deliver sends batches of due deliveries until none are left.
+func (w *WebhookWorker) deliver(ctx context.Context) {
+	for ctx.Err() == nil {
+		due, err := w.webhooks.deliverDue(webhookBatchSize)
+		if err != nil {
+			w.logger.Error(fmt.Sprintf("webhooks: deliver: %s", err))
+			return
+		}
+		if due < webhookBatchSize {
+			return
+		}
+	}
+}